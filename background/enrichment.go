@@ -0,0 +1,163 @@
+package background
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EnrichmentActor записывается в историю версий как автор изменений, внесённых фоновым обогащением.
+const EnrichmentActor = "enrichment"
+
+// EnrichmentOptions задаёт параметры обработки задач обогащения песен.
+type EnrichmentOptions struct {
+	Workers       int           // Количество задач, выполняемых одновременно
+	PollInterval  time.Duration // Как часто простаивающий обработчик проверяет очередь
+	RetryDelay    time.Duration // Задержка перед второй попыткой; перед каждой следующей удваивается
+	MaxRetryDelay time.Duration // Наибольшая задержка между попытками
+	Lease         time.Duration // Срок захвата задачи; по его истечении задачу заберёт другой обработчик
+}
+
+// versionConflictRetries — сколько раз попытка заново читает песню, если её изменили во время обогащения.
+const versionConflictRetries = 3
+
+// EnrichSongs запускает opts.Workers обработчиков, которые забирают задачи обогащения из очереди jobs
+// и дополняют песни данными из внешнего API. Неудачная попытка повторяется с экспоненциально растущей
+// задержкой, пока не будет исчерпано MaxAttempts попыток задачи; постоянная ошибка (см. utils.IsPermanentError)
+// завершает задачу сразу. Функция блокируется до отмены ctx и завершения начатых попыток, поэтому её
+// запускают в отдельной горутине.
+func EnrichSongs(ctx context.Context, logger *logrus.Logger, songs repository.SongRepository, jobs repository.JobRepository, opts EnrichmentOptions) {
+	// Изменения песен записываются в историю от имени фонового обогащения
+	ctx = repository.WithActor(ctx, EnrichmentActor)
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				claimed, err := jobs.Claim(ctx, 1, opts.Lease)
+				if err != nil && ctx.Err() == nil {
+					logger.Errorf("Failed to claim enrichment jobs: %v", err)
+				}
+				for _, job := range claimed {
+					runEnrichmentJob(ctx, logger, songs, jobs, job, opts)
+				}
+
+				// Очередь проверяется снова сразу после выполненной задачи, а при пустой очереди — через PollInterval
+				if len(claimed) > 0 {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(opts.PollInterval):
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// runEnrichmentJob выполняет одну попытку задачи и сохраняет её результат: успех, повтор или окончательную ошибку.
+func runEnrichmentJob(ctx context.Context, logger *logrus.Logger, songs repository.SongRepository, jobs repository.JobRepository, job models.Job, opts EnrichmentOptions) {
+	err := enrichSong(ctx, logger, songs, job.SongID)
+	now := time.Now().UTC()
+	switch {
+	case err == nil:
+		job.Status, job.LastError, job.FinishedAt = models.JobSucceeded, "", &now
+		logger.Infof("Enriched song ID: %d (job %d, attempt %d)", job.SongID, job.ID, job.Attempts)
+	case ctx.Err() != nil:
+		// Приложение останавливается: прерванная попытка возвращает задачу в очередь без задержки
+		job.Status, job.LastError, job.RunAt = models.JobQueued, err.Error(), now
+		logger.Infof("Enrichment job %d for song ID: %d interrupted by shutdown and returned to the queue", job.ID, job.SongID)
+	case errors.Is(err, repository.ErrNotFound):
+		// Песню удалили в корзину до обогащения: повторять попытку бессмысленно
+		job.Status, job.LastError, job.FinishedAt = models.JobFailed, "Song not found", &now
+		logger.Warnf("Enrichment job %d failed: song ID: %d not found", job.ID, job.SongID)
+	case job.Attempts >= job.MaxAttempts || utils.IsPermanentError(err):
+		// Если у внешнего API нет данных о песне или его ответ не изменится при повторе, попытки не повторяются
+		job.Status, job.LastError, job.FinishedAt = models.JobFailed, err.Error(), &now
+		logger.Errorf("Enrichment job %d for song ID: %d failed after %d attempts: %v", job.ID, job.SongID, job.Attempts, err)
+		status := models.EnrichmentFailed
+		if _, err := songs.Update(ctx, job.SongID, models.SongPatch{EnrichmentStatus: &status}, 0); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			logger.Errorf("Failed to mark song ID: %d as not enriched: %v", job.SongID, err)
+		}
+	default:
+		delay := retryDelay(job.Attempts, opts)
+		job.Status, job.LastError, job.RunAt = models.JobQueued, err.Error(), now.Add(delay)
+		logger.Warnf("Enrichment job %d for song ID: %d failed (attempt %d of %d), retrying in %s: %v",
+			job.ID, job.SongID, job.Attempts, job.MaxAttempts, delay, err)
+	}
+
+	// Результат сохраняется и после отмены ctx, чтобы прерванная задача не ждала истечения срока захвата
+	if err := jobs.Save(context.WithoutCancel(ctx), &job); err != nil {
+		if errors.Is(err, repository.ErrJobLost) {
+			logger.Warnf("Enrichment job %d was claimed by another worker, result of attempt %d discarded", job.ID, job.Attempts)
+			return
+		}
+		logger.Errorf("Failed to save enrichment job %d: %v", job.ID, err)
+	}
+}
+
+// retryDelay возвращает задержку перед попыткой, следующей за попыткой attempt: RetryDelay, умноженная
+// на 2^(attempt-1), но не больше MaxRetryDelay.
+func retryDelay(attempt int, opts EnrichmentOptions) time.Duration {
+	delay := opts.RetryDelay
+	for i := 1; i < attempt && delay < opts.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, opts.MaxRetryDelay)
+}
+
+// enrichSong дополняет песню данными из внешнего API и отмечает её обогащённой. Заполняются только
+// пустые поля: значения, которые пользователь успел задать сам, не перезаписываются. Если песню изменили
+// между чтением и записью, она читается заново.
+func enrichSong(ctx context.Context, logger *logrus.Logger, songs repository.SongRepository, songID uint) error {
+	song, err := songs.Get(ctx, songID)
+	if err != nil {
+		return err
+	}
+	details, err := utils.FetchSongDetails(ctx, song.Group, song.Song)
+	if err != nil {
+		return fmt.Errorf("failed to fetch song details: %w", err)
+	}
+
+	// Дата выпуска из внешнего API приходит в формате DD.MM.YYYY; некорректная дата не сохраняется
+	releaseDate, err := models.ParseDate(details.ReleaseDate)
+	if err != nil && details.ReleaseDate != "" {
+		logger.Warnf("Invalid release date %q received for song %s by %s", details.ReleaseDate, song.Song, song.Group)
+	}
+
+	for attempt := 0; ; attempt++ {
+		status := models.EnrichmentEnriched
+		patch := models.SongPatch{EnrichmentStatus: &status}
+		var filled []string // Поля, заполняемые данными из внешнего API
+		if song.ReleaseDate.IsZero() && !releaseDate.IsZero() {
+			patch.ReleaseDate, filled = &releaseDate, append(filled, "releaseDate")
+		}
+		if song.Text == "" && details.Text != "" {
+			patch.Text, filled = &details.Text, append(filled, "text")
+		}
+		if song.Link == "" && details.Link != "" {
+			patch.Link, filled = &details.Link, append(filled, "link")
+		}
+		// Вместе с полями сохраняется, какой провайдер предоставил каждое из них
+		patch.EnrichmentSources = details.Sources.Only(filled...)
+
+		_, err := songs.Update(ctx, song.ID, patch, song.Version)
+		if !errors.Is(err, repository.ErrVersionMismatch) || attempt == versionConflictRetries {
+			return err
+		}
+		if song, err = songs.Get(ctx, songID); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package background
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// testEnrichmentOptions — параметры обработки задач в тестах.
+var testEnrichmentOptions = EnrichmentOptions{RetryDelay: time.Second, MaxRetryDelay: 4 * time.Second, Lease: time.Minute}
+
+// useSongDetailsAPI направляет запросы FetchSongDetails к тестовому внешнему API с ответом status и телом body.
+func useSongDetailsAPI(t *testing.T, status int, body string) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("EXTERNAL_API_URL", server.URL)
+	t.Setenv("EXTERNAL_API_MAX_RETRIES", "0")
+	t.Setenv("EXTERNAL_API_BREAKER_THRESHOLD", "0")
+	if err := utils.InitSongDetailsProviders(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunEnrichmentJob(t *testing.T) {
+	const details = `{"releaseDate":"01.12.2003","text":"verse","link":"https://example.com/hysteria"}`
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		attempts    int
+		trashed     bool
+		wantJob     string
+		wantSong    string
+		wantRetryIn time.Duration
+	}{
+		{name: "enriched", status: http.StatusOK, body: details, attempts: 1, wantJob: models.JobSucceeded, wantSong: models.EnrichmentEnriched},
+		{name: "retry", status: http.StatusBadGateway, attempts: 2, wantJob: models.JobQueued, wantSong: models.EnrichmentPending, wantRetryIn: 2 * time.Second},
+		{name: "attempts exhausted", status: http.StatusBadGateway, attempts: 3, wantJob: models.JobFailed, wantSong: models.EnrichmentFailed},
+		{name: "song details not found", status: http.StatusNotFound, attempts: 1, wantJob: models.JobFailed, wantSong: models.EnrichmentFailed},
+		{name: "client error", status: http.StatusBadRequest, attempts: 1, wantJob: models.JobFailed, wantSong: models.EnrichmentFailed},
+		{name: "invalid json", status: http.StatusOK, body: `verse`, attempts: 1, wantJob: models.JobFailed, wantSong: models.EnrichmentFailed},
+		{name: "song trashed", status: http.StatusOK, body: details, attempts: 1, trashed: true, wantJob: models.JobFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useSongDetailsAPI(t, tt.status, tt.body)
+			logger := logrus.New()
+			logger.SetOutput(io.Discard)
+			ctx := context.Background()
+
+			songs := repository.NewMemorySongRepository()
+			jobs := repository.NewMemoryJobRepository(songs)
+			song := models.Song{Group: "Muse", Song: "Hysteria", EnrichmentStatus: models.EnrichmentPending}
+			queued := models.Job{Type: models.JobEnrichSong, Status: models.JobQueued, MaxAttempts: 3, RunAt: time.Now().Add(-time.Second)}
+			if err := songs.CreateWithJob(ctx, &song, &queued); err != nil {
+				t.Fatal(err)
+			}
+			if tt.trashed {
+				if err := songs.Delete(ctx, song.ID, 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Попытка с номером tt.attempts: предыдущие попытки захвачены с истёкшим сроком
+			var claimed []models.Job
+			for i := 0; i < tt.attempts; i++ {
+				lease := -time.Second
+				if i == tt.attempts-1 {
+					lease = time.Minute
+				}
+				var err error
+				if claimed, err = jobs.Claim(ctx, 1, lease); err != nil || len(claimed) != 1 {
+					t.Fatalf("claim %d: %v, error %v", i+1, claimed, err)
+				}
+			}
+			runEnrichmentJob(ctx, logger, songs, jobs, claimed[0], testEnrichmentOptions)
+
+			job, err := jobs.Get(ctx, queued.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.Status != tt.wantJob {
+				t.Errorf("job status %q, want %q, last error %q", job.Status, tt.wantJob, job.LastError)
+			}
+			if tt.wantRetryIn > 0 {
+				if delay := time.Until(job.RunAt); delay <= 0 || delay > tt.wantRetryIn {
+					t.Errorf("next attempt in %s, want %s", delay, tt.wantRetryIn)
+				}
+			}
+			if tt.trashed {
+				return
+			}
+			enriched, err := songs.Get(ctx, song.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if enriched.EnrichmentStatus != tt.wantSong {
+				t.Errorf("song enrichment status %q, want %q", enriched.EnrichmentStatus, tt.wantSong)
+			}
+			if tt.wantSong == models.EnrichmentEnriched && (enriched.Text != "verse" || enriched.ReleaseDate.IsZero()) {
+				t.Errorf("enriched song %+v, want text and release date from the external API", enriched)
+			}
+			wantSources := models.SongSources(nil)
+			if tt.wantSong == models.EnrichmentEnriched {
+				wantSources = models.SongSources{"releaseDate": utils.DefaultProviderName, "text": utils.DefaultProviderName, "link": utils.DefaultProviderName}
+			}
+			if !reflect.DeepEqual(enriched.EnrichmentSources, wantSources) {
+				t.Errorf("enrichment sources %v, want %v", enriched.EnrichmentSources, wantSources)
+			}
+		})
+	}
+}
+
+func TestRunEnrichmentJobKeepsUserValues(t *testing.T) {
+	useSongDetailsAPI(t, http.StatusOK, `{"releaseDate":"01.12.2003","text":"verse","link":"https://example.com/hysteria"}`)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	ctx := context.Background()
+
+	songs := repository.NewMemorySongRepository()
+	jobs := repository.NewMemoryJobRepository(songs)
+	song := models.Song{Group: "Muse", Song: "Hysteria", Text: "own verse", EnrichmentStatus: models.EnrichmentPending}
+	queued := models.Job{Type: models.JobEnrichSong, Status: models.JobQueued, MaxAttempts: 3, RunAt: time.Now().Add(-time.Second)}
+	if err := songs.CreateWithJob(ctx, &song, &queued); err != nil {
+		t.Fatal(err)
+	}
+	claimed, err := jobs.Claim(ctx, 1, time.Minute)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("claimed %v, error %v", claimed, err)
+	}
+	runEnrichmentJob(ctx, logger, songs, jobs, claimed[0], testEnrichmentOptions)
+
+	enriched, err := songs.Get(ctx, song.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enriched.Text != "own verse" || enriched.Link != "https://example.com/hysteria" {
+		t.Errorf("enriched song %+v, want the user's text kept and the link filled in", enriched)
+	}
+}
+
+func TestRunEnrichmentJobInterrupted(t *testing.T) {
+	useSongDetailsAPI(t, http.StatusOK, `{"text":"verse"}`)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	songs := repository.NewMemorySongRepository()
+	jobs := repository.NewMemoryJobRepository(songs)
+	song := models.Song{Group: "Muse", Song: "Hysteria", EnrichmentStatus: models.EnrichmentPending}
+	queued := models.Job{Type: models.JobEnrichSong, Status: models.JobQueued, MaxAttempts: 3, RunAt: time.Now().Add(-time.Second)}
+	if err := songs.CreateWithJob(context.Background(), &song, &queued); err != nil {
+		t.Fatal(err)
+	}
+	claimed, err := jobs.Claim(context.Background(), 1, time.Minute)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("claimed %v, error %v", claimed, err)
+	}
+
+	// Приложение останавливается во время попытки
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	runEnrichmentJob(ctx, logger, songs, jobs, claimed[0], testEnrichmentOptions)
+
+	job, err := jobs.Get(context.Background(), queued.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != models.JobQueued || job.RunAt.After(time.Now()) {
+		t.Errorf("interrupted job %+v, want a job queued without delay", job)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 10, want: 4 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempt, testEnrichmentOptions); got != tt.want {
+			t.Errorf("retryDelay(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+/*
+Package background содержит задачи, которые приложение выполняет в фоне параллельно с обработкой запросов.
+*/
+
+package background
+
+import (
+	"MusicLibrary/repository"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PurgeTrash каждые interval окончательно удаляет песни, которые находятся в корзине дольше retention.
+// Первая очистка выполняется сразу при запуске. Функция блокируется до отмены ctx,
+// поэтому её запускают в отдельной горутине.
+func PurgeTrash(ctx context.Context, logger *logrus.Logger, songs repository.SongRepository, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := songs.PurgeDeletedBefore(ctx, time.Now().Add(-retention))
+		if err != nil {
+			logger.Errorf("Failed to purge expired songs from trash: %v", err)
+		} else if purged > 0 {
+			logger.Infof("Purged %d songs deleted more than %s ago", purged, retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package background
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestPurgeTrash(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	songs := repository.NewMemorySongRepository()
+	ctx := context.Background()
+	for _, title := range []string{"Hysteria", "Starlight"} {
+		if err := songs.Create(ctx, &models.Song{Group: "Muse", Song: title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := songs.Delete(ctx, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Отменённый контекст: выполняется только первая очистка при запуске
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	PurgeTrash(cancelled, logger, songs, time.Hour, time.Hour)
+	if _, total, _ := songs.ListDeleted(ctx, 0, 10); total != 1 {
+		t.Fatalf("%d songs in trash after purging with an hour of retention, want 1", total)
+	}
+	PurgeTrash(cancelled, logger, songs, -time.Hour, time.Hour)
+	if _, total, _ := songs.ListDeleted(ctx, 0, 10); total != 0 {
+		t.Errorf("%d songs in trash after purging expired songs, want 0", total)
+	}
+	if _, err := songs.Get(ctx, 2); err != nil {
+		t.Errorf("song outside trash was purged: %v", err)
+	}
+}
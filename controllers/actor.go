@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"MusicLibrary/repository"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// actorHeader — заголовок, в котором клиент передаёт автора изменения для истории версий.
+const actorHeader = "X-Actor"
+
+// Actor сохраняет в контексте запроса автора изменения: значение заголовка X-Actor,
+// а если он не передан — IP-адрес клиента. Хранилище записывает автора в историю версий песни.
+func Actor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := strings.TrimSpace(c.GetHeader(actorHeader))
+		if actor == "" {
+			actor = c.ClientIP()
+		}
+		c.Request = c.Request.WithContext(repository.WithActor(c.Request.Context(), actor))
+		c.Next()
+	}
+}
@@ -0,0 +1,426 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GetAllAlbums возвращает список альбомов с фильтрацией и пагинацией.
+// @Summary Получение всех альбомов
+// @Description Возвращает список альбомов с возможностью фильтрации по названию и группе, а также поддержкой пагинации.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param title query string false "Название альбома"
+// @Param group query string false "Название группы"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество альбомов на странице" default(5)
+// @Success 200 {object} models.ResponseAllAlbums "Список альбомов"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums [get]
+func GetAllAlbums(logger *logrus.Logger, albums repository.AlbumRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		title := c.Query("title")
+		group := c.Query("group")
+		page := c.DefaultQuery("page", "1")
+		limit := c.DefaultQuery("limit", "5")
+
+		// Конвертация параметров пагинации в числа
+		pageInt, err := strconv.Atoi(page)
+		if err != nil || pageInt < 1 {
+			logger.Warnf("Invalid page parameter: %s", page)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid page parameter"})
+			return
+		}
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt < 1 {
+			logger.Warnf("Invalid limit parameter: %s", limit)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid limit parameter"})
+			return
+		}
+
+		// Фильтрация
+		offset := (pageInt - 1) * limitInt
+		list, total, err := albums.List(c.Request.Context(), repository.AlbumFilter{Title: title, Group: group}, offset, limitInt)
+		if err != nil {
+			logger.Errorf("Failed to retrieve albums: %v", err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve albums"})
+			return
+		}
+
+		logger.Infof("Retrieved %d albums", len(list))
+		c.JSON(http.StatusOK, models.ResponseAllAlbums{
+			Total:  total,
+			Page:   pageInt,
+			Limit:  limitInt,
+			Albums: list,
+		})
+	}
+}
+
+// GetAlbum возвращает альбом по ID.
+// @Summary Получение альбома
+// @Description Возвращает альбом по его ID.
+// @Tags albums
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Success 200 {object} models.Album "Альбом"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID альбома"
+// @Failure 404 {object} models.ErrorResponse "Альбом не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums/{id} [get]
+func GetAlbum(logger *logrus.Logger, albums repository.AlbumRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid album ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid album ID"})
+			return
+		}
+
+		album, err := albums.Get(c.Request.Context(), id)
+		if err != nil {
+			respondAlbumError(c, logger, err, id)
+			return
+		}
+
+		c.JSON(http.StatusOK, album)
+	}
+}
+
+// CreateAlbum добавляет новый альбом.
+// @Summary Создание альбома
+// @Description Добавляет новый альбом. Группа указывается по названию и создаётся, если её ещё нет. Формат даты releaseDate: DD.MM.YYYY
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param input body models.AlbumInput true "Данные альбома"
+// @Success 200 {object} models.Album "Созданный альбом"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums [post]
+func CreateAlbum(logger *logrus.Logger, albums repository.AlbumRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.AlbumInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			logger.Warnf("Failed to bind JSON: %v", err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if utils.NormalizeName(input.Title) == "" || utils.NormalizeName(input.Group) == "" {
+			logger.Warn("Album title or group is empty")
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Album title and group are required"})
+			return
+		}
+		var releaseDate models.Date
+		if input.ReleaseDate != "" {
+			date, err := parseReleaseDate(input.ReleaseDate)
+			if err != nil {
+				logger.Warnf("Invalid release date for album: %s, error: %v", input.ReleaseDate, err)
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+				return
+			}
+			releaseDate = date
+		}
+
+		album := models.Album{
+			Title:       utils.NormalizeName(input.Title),
+			ReleaseDate: releaseDate,
+			CoverLink:   input.CoverLink,
+			Label:       input.Label,
+		}
+		if err := albums.Create(c.Request.Context(), &album, input.Group); err != nil {
+			logger.Errorf("Failed to save the album: %v", err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to save the album"})
+			return
+		}
+
+		logger.Infof("Created album: %s by %s", album.Title, album.Group)
+		c.JSON(http.StatusOK, album)
+	}
+}
+
+// UpdateAlbum обновляет данные альбома по ID.
+// @Summary Обновление альбома
+// @Description Обновляет информацию об альбоме. Передаются только изменяемые поля. Формат даты releaseDate: DD.MM.YYYY
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Param input body models.AlbumInput true "Обновлённые данные альбома"
+// @Success 200 {object} models.Album "Обновлённый альбом"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 404 {object} models.ErrorResponse "Альбом не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums/{id} [patch]
+func UpdateAlbum(logger *logrus.Logger, albums repository.AlbumRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid album ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid album ID"})
+			return
+		}
+
+		album, err := albums.Get(c.Request.Context(), id)
+		if err != nil {
+			respondAlbumError(c, logger, err, id)
+			return
+		}
+
+		var input models.AlbumInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			logger.Warnf("Failed to bind JSON for updating album ID: %d, error: %v", id, err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if input.ReleaseDate != "" {
+			date, err := parseReleaseDate(input.ReleaseDate)
+			if err != nil {
+				logger.Warnf("Invalid release date for album ID: %d, error: %v", id, err)
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+				return
+			}
+			album.ReleaseDate = date
+		}
+		if input.Title != "" {
+			album.Title = utils.NormalizeName(input.Title)
+		}
+		if input.CoverLink != "" {
+			album.CoverLink = input.CoverLink
+		}
+		if input.Label != "" {
+			album.Label = input.Label
+		}
+
+		err = albums.Update(c.Request.Context(), album, input.Group)
+		if errors.Is(err, repository.ErrAlbumNotFound) {
+			respondAlbumError(c, logger, err, id)
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to update album ID: %d, error: %v", id, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update the album"})
+			return
+		}
+
+		logger.Infof("Updated album: %s with ID: %d", album.Title, id)
+		c.JSON(http.StatusOK, album)
+	}
+}
+
+// DeleteAlbum удаляет альбом по ID.
+// @Summary Удаление альбома
+// @Description Удаляет альбом и его треклист. Сами песни при этом не удаляются.
+// @Tags albums
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Success 200 {object} models.SuccessResponse "Альбом успешно удалён"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID альбома"
+// @Failure 404 {object} models.ErrorResponse "Альбом не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums/{id} [delete]
+func DeleteAlbum(logger *logrus.Logger, albums repository.AlbumRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid album ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid album ID"})
+			return
+		}
+
+		err = albums.Delete(c.Request.Context(), id)
+		if errors.Is(err, repository.ErrAlbumNotFound) {
+			respondAlbumError(c, logger, err, id)
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to delete album ID: %d, error: %v", id, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to delete the album"})
+			return
+		}
+
+		logger.Infof("Deleted album with ID: %d", id)
+		c.JSON(http.StatusOK, models.SuccessResponse{Message: "Album deleted successfully"})
+	}
+}
+
+// GetAlbumTracks возвращает упорядоченный треклист альбома.
+// @Summary Получение треклиста альбома
+// @Description Возвращает песни альбома, упорядоченные по номеру диска и номеру трека.
+// @Tags albums
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Success 200 {object} models.ResponseAlbumTracks "Альбом и его треклист"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID альбома"
+// @Failure 404 {object} models.ErrorResponse "Альбом не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums/{id}/tracks [get]
+func GetAlbumTracks(logger *logrus.Logger, albums repository.AlbumRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid album ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid album ID"})
+			return
+		}
+
+		album, err := albums.Get(c.Request.Context(), id)
+		if err != nil {
+			respondAlbumError(c, logger, err, id)
+			return
+		}
+		tracks, err := albums.Tracks(c.Request.Context(), id)
+		if errors.Is(err, repository.ErrAlbumNotFound) {
+			respondAlbumError(c, logger, err, id)
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to retrieve tracks of album ID: %d, error: %v", id, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve album tracks"})
+			return
+		}
+
+		logger.Infof("Returning %d tracks for album ID: %d", len(tracks), id)
+		c.JSON(http.StatusOK, models.ResponseAlbumTracks{Album: *album, Tracks: tracks})
+	}
+}
+
+// SetAlbumTrack добавляет песню в альбом или меняет её позицию в треклисте.
+// @Summary Добавление песни в альбом
+// @Description Добавляет песню в альбом на указанную позицию или перемещает её, если песня уже есть в альбоме. Номер диска по умолчанию равен 1.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Param songId path int true "ID песни"
+// @Param input body models.AlbumTrackInput true "Позиция песни в альбоме"
+// @Success 200 {object} models.AlbumTrack "Позиция песни в альбоме"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 404 {object} models.ErrorResponse "Альбом или песня не найдены"
+// @Failure 409 {object} models.ErrorResponse "Позиция уже занята другой песней"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums/{id}/tracks/{songId} [put]
+func SetAlbumTrack(logger *logrus.Logger, albums repository.AlbumRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		albumID, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid album ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid album ID"})
+			return
+		}
+		songID, err := parseIDParam(c, "songId")
+		if err != nil {
+			logger.Warnf("Invalid song ID: %s", c.Param("songId"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid song ID"})
+			return
+		}
+
+		var input models.AlbumTrackInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			logger.Warnf("Failed to bind JSON for track of album ID: %d, error: %v", albumID, err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if input.DiscNumber == 0 {
+			input.DiscNumber = 1
+		}
+		if input.DiscNumber < 0 {
+			logger.Warnf("Invalid disc number: %d", input.DiscNumber)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid disc number"})
+			return
+		}
+
+		track := models.AlbumTrack{
+			AlbumID:     albumID,
+			SongID:      songID,
+			DiscNumber:  input.DiscNumber,
+			TrackNumber: input.TrackNumber,
+		}
+		err = albums.SetTrack(c.Request.Context(), &track)
+		switch {
+		case errors.Is(err, repository.ErrAlbumNotFound):
+			respondAlbumError(c, logger, err, albumID)
+			return
+		case errors.Is(err, repository.ErrNotFound):
+			logger.Warnf("Song not found with ID: %d", songID)
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Song not found"})
+			return
+		case errors.Is(err, repository.ErrTrackPositionTaken):
+			logger.Warnf("Position %d-%d of album ID: %d is taken by another song", input.DiscNumber, input.TrackNumber, albumID)
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Track position is already taken"})
+			return
+		case err != nil:
+			logger.Errorf("Failed to save track of album ID: %d, error: %v", albumID, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to save the track"})
+			return
+		}
+
+		logger.Infof("Set song ID: %d as track %d-%d of album ID: %d", songID, track.DiscNumber, track.TrackNumber, albumID)
+		c.JSON(http.StatusOK, track)
+	}
+}
+
+// DeleteAlbumTrack убирает песню из альбома.
+// @Summary Удаление песни из альбома
+// @Description Убирает песню из треклиста альбома. Сама песня при этом не удаляется.
+// @Tags albums
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Param songId path int true "ID песни"
+// @Success 200 {object} models.SuccessResponse "Песня убрана из альбома"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID"
+// @Failure 404 {object} models.ErrorResponse "Песня отсутствует в альбоме"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /albums/{id}/tracks/{songId} [delete]
+func DeleteAlbumTrack(logger *logrus.Logger, albums repository.AlbumRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		albumID, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid album ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid album ID"})
+			return
+		}
+		songID, err := parseIDParam(c, "songId")
+		if err != nil {
+			logger.Warnf("Invalid song ID: %s", c.Param("songId"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid song ID"})
+			return
+		}
+
+		err = albums.DeleteTrack(c.Request.Context(), albumID, songID)
+		if errors.Is(err, repository.ErrTrackNotFound) {
+			logger.Warnf("Song ID: %d is not a track of album ID: %d", songID, albumID)
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Track not found"})
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to delete song ID: %d from album ID: %d, error: %v", songID, albumID, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to delete the track"})
+			return
+		}
+
+		logger.Infof("Deleted song ID: %d from album ID: %d", songID, albumID)
+		c.JSON(http.StatusOK, models.SuccessResponse{Message: "Track deleted successfully"})
+	}
+}
+
+// respondAlbumError отвечает 404, если альбома нет, и 500 при прочих ошибках чтения альбома.
+func respondAlbumError(c *gin.Context, logger *logrus.Logger, err error, id uint) {
+	if errors.Is(err, repository.ErrAlbumNotFound) {
+		logger.Warnf("Album not found with ID: %d", id)
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Album not found"})
+		return
+	}
+	logger.Errorf("Failed to retrieve album ID: %d, error: %v", id, err)
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve the album"})
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/sirupsen/logrus"
+)
+
+// maxBatchSize ограничивает количество песен в одном запросе пакетного создания.
+const maxBatchSize = 500
+
+// CreateSongsBatch добавляет несколько песен за один запрос, обогащая не более workers песен одновременно.
+// @Summary Пакетное создание песен
+// @Description Добавляет песни из массива и обогащает их данные из внешнего API параллельно, не более BATCH_WORKERS запросов одновременно. Ошибка одной песни не отменяет остальные: для каждой песни возвращается статус created, duplicate (уже есть в библиотеке или повторяется в запросе), enrichment_failed (внешний API не вернул данные, песня не создана), invalid (некорректные данные) или error.
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param input body []models.SongInput true "Песни, не более 500"
+// @Param X-Actor header string false "Автор изменения для истории версий"
+// @Success 200 {object} models.ResponseBatch "Результаты по каждой песне в порядке запроса"
+// @Failure 400 {object} models.ErrorResponse "Тело запроса не является массивом или песен слишком много"
+// @Router /songs/batch [post]
+func CreateSongsBatch(logger *logrus.Logger, songs repository.SongRepository, workers int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Песни разбираются по отдельности, чтобы ошибка в одной не отклоняла весь запрос
+		var items []json.RawMessage
+		if err := c.ShouldBindJSON(&items); err != nil {
+			logger.Warnf("Failed to bind JSON batch: %v", err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Request body must be a JSON array of songs"})
+			return
+		}
+		if len(items) == 0 || len(items) > maxBatchSize {
+			logger.Warnf("Invalid batch size: %d", len(items))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Batch must contain from 1 to %d songs", maxBatchSize)})
+			return
+		}
+
+		results := make([]models.BatchItemResult, len(items))
+		inputs := make([]models.SongInput, len(items))
+		pending := make([]int, 0, len(items))
+		seen := make(map[string]int, len(items))
+		for i, item := range items {
+			results[i].Index = i
+			if err := json.Unmarshal(item, &inputs[i]); err != nil {
+				results[i].Status, results[i].Error = models.BatchInvalid, "Song must be an object with string fields group and song"
+				continue
+			}
+			if err := binding.Validator.ValidateStruct(&inputs[i]); err != nil {
+				results[i].Status, results[i].Error = models.BatchInvalid, "Fields group and song are required"
+				continue
+			}
+
+			// Повтор песни в том же запросе не отправляется во внешний API второй раз
+			key := utils.NameKey(inputs[i].Group) + "\x00" + strings.ToLower(utils.NormalizeName(inputs[i].Song))
+			if first, ok := seen[key]; ok {
+				results[i].Status, results[i].Error = models.BatchDuplicate, fmt.Sprintf("Same song as item %d", first)
+				continue
+			}
+			seen[key] = i
+			pending = append(pending, i)
+		}
+
+		// Ограниченный пул обработчиков: каждый берёт следующую песню из очереди
+		queue := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < min(workers, len(pending)); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range queue {
+					results[i] = createBatchSong(c.Request.Context(), logger, songs, i, inputs[i])
+				}
+			}()
+		}
+		for _, i := range pending {
+			queue <- i
+		}
+		close(queue)
+		wg.Wait()
+
+		response := models.ResponseBatch{Total: len(items), Results: results}
+		for _, result := range results {
+			if result.Status == models.BatchCreated {
+				response.Created++
+			}
+		}
+
+		logger.Infof("Batch created %d of %d songs", response.Created, response.Total)
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// createBatchSong создаёт одну песню пакета так же, как CreateSong, и возвращает её результат.
+func createBatchSong(ctx context.Context, logger *logrus.Logger, songs repository.SongRepository, index int, input models.SongInput) models.BatchItemResult {
+	result := models.BatchItemResult{Index: index}
+	if err := ctx.Err(); err != nil {
+		result.Status, result.Error = models.BatchError, "Request was cancelled"
+		return result
+	}
+
+	title := utils.NormalizeName(input.Song)
+	exists, err := songs.ExistsByGroupAndTitle(ctx, input.Group, title)
+	if err != nil {
+		logger.Errorf("Failed to check song %s by %s for duplicates: %v", input.Song, input.Group, err)
+		result.Status, result.Error = models.BatchError, "Failed to check for duplicate songs"
+		return result
+	}
+	if exists {
+		result.Status, result.Error = models.BatchDuplicate, "Song already exists in the library"
+		return result
+	}
+
+	newSong, err := enrichSong(ctx, logger, input, title)
+	if err != nil {
+		logger.Warnf("Failed to fetch song details for %s by %s: %v", input.Song, input.Group, err)
+		result.Status, result.Error = models.BatchEnrichmentFailed, "Failed to fetch song details"
+		return result
+	}
+
+	if err := songs.Create(ctx, &newSong); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			result.Status, result.Error = models.BatchDuplicate, "Song already exists in the library"
+			return result
+		}
+		logger.Errorf("Failed to save the song %s by %s: %v", input.Song, input.Group, err)
+		result.Status, result.Error = models.BatchError, "Failed to save the song"
+		return result
+	}
+
+	result.Status, result.Song = models.BatchCreated, &newSong
+	return result
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCreateSongsBatchInvalidBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/songs/batch", CreateSongsBatch(newTestLogger(), repository.NewMemorySongRepository(), 4))
+
+	for _, body := range []string{`{"group":"Muse","song":"Hysteria"}`, `[]`, `[` + strings.Repeat(`{},`, maxBatchSize) + `{}]`} {
+		if w := serve(router, http.MethodPost, "/songs/batch", body, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("body of %d bytes: status %d, want %d", len(body), w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestCreateSongsBatch(t *testing.T) {
+	// Внешний API не знает песню Unknown
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("song") == "Unknown" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, `{"releaseDate":"01.12.2003","text":"verse","link":"https://example.com/song"}`)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("EXTERNAL_API_URL", server.URL)
+	if err := utils.InitSongDetailsProviders(); err != nil {
+		t.Fatal(err)
+	}
+
+	songs := repository.NewMemorySongRepository()
+	seedSongs(t, songs, "Muse", "Hysteria")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/songs/batch", CreateSongsBatch(newTestLogger(), songs, 4))
+
+	body := `[
+		{"group":"Muse","song":"Starlight"},
+		{"group":"muse","song":"HYSTERIA"},
+		{"group":"Muse","song":"Unknown"},
+		{"group":"Muse"},
+		"Uprising",
+		{"group":" Muse ","song":"starlight"},
+		{"group":"Blur","song":"Song 2"}
+	]`
+	w := serve(router, http.MethodPost, "/songs/batch", body, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	var response models.ResponseBatch
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		models.BatchCreated,
+		models.BatchDuplicate,
+		models.BatchEnrichmentFailed,
+		models.BatchInvalid,
+		models.BatchInvalid,
+		models.BatchDuplicate,
+		models.BatchCreated,
+	}
+	if response.Total != len(want) || response.Created != 2 || len(response.Results) != len(want) {
+		t.Fatalf("response %+v, want %d results with 2 created", response, len(want))
+	}
+	for i, result := range response.Results {
+		if result.Index != i || result.Status != want[i] {
+			t.Errorf("result %d: index %d, status %q, want %q", i, result.Index, result.Status, want[i])
+		}
+	}
+	if created := response.Results[0].Song; created == nil || created.Text != "verse" || created.ID == 0 {
+		t.Errorf("created song %+v, want a saved song with details from the external API", created)
+	}
+}
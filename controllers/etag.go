@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// songETag возвращает сильный ETag песни, построенный из её версии. Версия увеличивается
+// при каждом изменении песни, поэтому ETag меняется вместе с её содержимым.
+func songETag(song *models.Song) string {
+	return fmt.Sprintf("\"%d\"", song.Version)
+}
+
+// matchETag проверяет, совпадает ли etag с одним из значений заголовка If-Match или If-None-Match.
+// Значение "*" совпадает с любым ETag. При слабом сравнении (weak) префикс W/ не учитывается,
+// при сильном слабые ETag не совпадают ни с чем.
+func matchETag(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+			etag = strings.TrimPrefix(etag, "W/")
+		} else if strings.HasPrefix(candidate, "W/") || strings.HasPrefix(etag, "W/") {
+			continue
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified добавляет к ответу заголовок ETag и, если клиент передал совпадающий If-None-Match,
+// отвечает 304 Not Modified без тела. Возвращает true, если ответ уже отправлен.
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	if header := c.GetHeader("If-None-Match"); header != "" && matchETag(header, etag, true) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// respondJSONWithETag отправляет ответ 200 с телом body и слабым ETag, вычисленным по содержимому ответа.
+// Используется для списков, у которых нет собственной версии. Если клиент передал совпадающий
+// If-None-Match, отправляется 304 Not Modified без тела.
+func respondJSONWithETag(c *gin.Context, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.JSON(http.StatusOK, body)
+		return
+	}
+	sum := sha256.Sum256(data)
+	if notModified(c, "W/\""+hex.EncodeToString(sum[:16])+"\"") {
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// ifMatchFailed проверяет заголовок If-Match запроса на изменение песни. Возвращает true, если заголовок
+// передан и не совпадает с текущим ETag песни, то есть песню успели изменить: ответ 412 уже отправлен.
+// Если заголовок обязателен (required), запрос без него отклоняется с ответом 428.
+func ifMatchFailed(c *gin.Context, logger *logrus.Logger, song *models.Song, required bool) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if required {
+			logger.Warnf("Missing If-Match header for song ID: %d", song.ID)
+			c.JSON(http.StatusPreconditionRequired, models.ErrorResponse{Error: "If-Match header is required"})
+			return true
+		}
+		return false
+	}
+	if !matchETag(header, songETag(song), false) {
+		logger.Warnf("If-Match %s does not match version %d of song ID: %d", header, song.Version, song.ID)
+		c.Header("ETag", songETag(song))
+		c.JSON(http.StatusPreconditionFailed, models.ErrorResponse{Error: "Song has been modified by another request"})
+		return true
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMatchETag(t *testing.T) {
+	tests := []struct {
+		header string
+		etag   string
+		weak   bool
+		want   bool
+	}{
+		{header: `"3"`, etag: `"3"`, want: true},
+		{header: `"2"`, etag: `"3"`, want: false},
+		{header: `"1", "3"`, etag: `"3"`, want: true},
+		{header: `*`, etag: `"3"`, want: true},
+		{header: `W/"3"`, etag: `"3"`, weak: true, want: true},
+		{header: `"3"`, etag: `W/"3"`, weak: true, want: true},
+		{header: `W/"3"`, etag: `"3"`, weak: false, want: false},
+		{header: `"3"`, etag: `W/"3"`, weak: false, want: false},
+		{header: `3`, etag: `"3"`, want: false},
+	}
+	for _, tt := range tests {
+		if got := matchETag(tt.header, tt.etag, tt.weak); got != tt.want {
+			t.Errorf("matchETag(%q, %q, weak=%v) = %v, want %v", tt.header, tt.etag, tt.weak, got, tt.want)
+		}
+	}
+}
+
+func TestIfMatchFailed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	song := &models.Song{ID: 1, Version: 3}
+	tests := []struct {
+		name     string
+		header   string
+		required bool
+		failed   bool
+		status   int
+	}{
+		{name: "no header", failed: false},
+		{name: "current version", header: `"3"`, failed: false},
+		{name: "any version", header: `*`, failed: false},
+		{name: "outdated version", header: `"2"`, failed: true, status: http.StatusPreconditionFailed},
+		{name: "weak etag", header: `W/"3"`, failed: true, status: http.StatusPreconditionFailed},
+		{name: "required but missing", required: true, failed: true, status: http.StatusPreconditionRequired},
+		{name: "required and current", header: `"3"`, required: true, failed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/songs/1", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("If-Match", tt.header)
+			}
+
+			if got := ifMatchFailed(c, newTestLogger(), song, tt.required); got != tt.failed {
+				t.Fatalf("ifMatchFailed = %v, want %v", got, tt.failed)
+			}
+			if tt.failed && w.Code != tt.status {
+				t.Errorf("status %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
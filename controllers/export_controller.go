@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"MusicLibrary/importer"
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// exportContentTypes сопоставляет форматы выгрузки с типами содержимого ответа.
+var exportContentTypes = map[string]string{
+	importer.FormatCSV:    "text/csv; charset=utf-8",
+	importer.FormatJSON:   "application/json; charset=utf-8",
+	importer.FormatNDJSON: "application/x-ndjson",
+}
+
+// exportColumns — столбцы CSV при выгрузке. Названия совпадают с полями импорта,
+// поэтому выгруженный файл можно снова загрузить через /songs/import.
+var exportColumns = []string{"id", "groupId", "group", "song", "releaseDate", "text", "link", "version", "enrichmentStatus"}
+
+// ExportSongs выгружает все песни, подходящие под фильтры, в файл CSV, JSON или NDJSON.
+// @Summary Выгрузка песен
+// @Description Выгружает все песни, подходящие под те же фильтры, что и в списке песен, одним файлом без пагинации. Ответ передаётся по мере чтения песен из базы данных, поэтому размер библиотеки не ограничен памятью сервера. Столбцы CSV совпадают с полями импорта, так что файл можно загрузить обратно через /songs/import. С gzip=true файл сжимается.
+// @Tags songs
+// @Produce text/csv,json,application/x-ndjson,application/gzip
+// @Param format query string false "Формат файла" Enums(csv, json, ndjson) default(csv)
+// @Param gzip query bool false "Сжать файл gzip" default(false)
+// @Param group query string false "Название группы или одно из её альтернативных названий, в том числе в другой транслитерации"
+// @Param song query string false "Название песни, в том числе в другой транслитерации"
+// @Param album query string false "Название альбома"
+// @Param releaseDate query string false "Дата выпуска в формате DD.MM.YYYY"
+// @Param releasedFrom query string false "Дата выпуска не ранее указанной, формат DD.MM.YYYY"
+// @Param releasedTo query string false "Дата выпуска не позднее указанной, формат DD.MM.YYYY"
+// @Param year query int false "Год выпуска"
+// @Param decade query string false "Десятилетие выпуска, например 1990 или 1990s"
+// @Param sort query string false "Сортировка, как в списке песен, например -releaseDate,group"
+// @Success 200 {file} file "Файл с песнями; заголовок Content-Disposition содержит имя файла"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/export [get]
+func ExportSongs(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.DefaultQuery("format", importer.FormatCSV)
+		contentType, ok := exportContentTypes[format]
+		if !ok {
+			logger.Warnf("Invalid export format: %s", format)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid format parameter. Expected csv, json or ndjson"})
+			return
+		}
+		compress, err := strconv.ParseBool(c.DefaultQuery("gzip", "false"))
+		if err != nil {
+			logger.Warnf("Invalid gzip parameter: %s", c.Query("gzip"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid gzip parameter. Expected true or false"})
+			return
+		}
+		filters, err := parseSongFilters(c)
+		if err != nil {
+			logger.Warnf("Invalid filter parameters: %v", err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		sort, err := parseSongSort(c.Query("sort"))
+		if err != nil {
+			logger.Warnf("Invalid sort parameter: %v", err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		// Заголовки отправляются вместе с первыми данными, поэтому ошибка до первой песни
+		// ещё может быть возвращена клиенту как обычный ответ с ошибкой
+		filename := "songs." + format
+		c.Header("Content-Type", contentType)
+		if compress {
+			filename += ".gz"
+			c.Header("Content-Type", "application/gzip")
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+		var out io.Writer = c.Writer
+		var gz *gzip.Writer
+		if compress {
+			gz = gzip.NewWriter(c.Writer)
+			out = gz
+		}
+		export := newSongExport(format, out)
+
+		count := 0
+		err = songs.Each(c.Request.Context(), filters, sort, func(song models.Song) error {
+			count++
+			return export.write(song)
+		})
+		if err == nil {
+			err = export.close()
+		}
+		if err == nil && gz != nil {
+			err = gz.Close()
+		}
+		if err != nil {
+			// После отправки первых данных статус изменить нельзя: клиент получит оборванный файл
+			logger.Errorf("Failed to export songs after %d songs: %v", count, err)
+			if !c.Writer.Written() {
+				c.Header("Content-Type", "")
+				c.Header("Content-Disposition", "")
+				c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to export songs"})
+			}
+			return
+		}
+		logger.Infof("Exported %d songs as %s", count, filename)
+	}
+}
+
+// songExport записывает песни в файл выгрузки одного из форматов.
+type songExport struct {
+	format string
+	out    io.Writer
+	csv    *csv.Writer
+	count  int
+}
+
+// newSongExport создаёт запись выгрузки в формате format.
+func newSongExport(format string, out io.Writer) *songExport {
+	export := &songExport{format: format, out: out}
+	if format == importer.FormatCSV {
+		export.csv = csv.NewWriter(out)
+	}
+	return export
+}
+
+// write записывает очередную песню. Начало файла (заголовок CSV или открывающая скобка массива JSON)
+// записывается вместе с первой песней.
+func (e *songExport) write(song models.Song) error {
+	e.count++
+	switch e.format {
+	case importer.FormatCSV:
+		if e.count == 1 {
+			if err := e.csv.Write(exportColumns); err != nil {
+				return err
+			}
+		}
+		return e.csv.Write([]string{
+			strconv.FormatUint(uint64(song.ID), 10),
+			strconv.FormatUint(uint64(song.GroupID), 10),
+			song.Group,
+			song.Song,
+			song.ReleaseDate.String(),
+			song.Text,
+			song.Link,
+			strconv.FormatUint(uint64(song.Version), 10),
+			song.EnrichmentStatus,
+		})
+	default:
+		data, err := json.Marshal(song)
+		if err != nil {
+			return err
+		}
+		prefix, suffix := "", "\n"
+		if e.format == importer.FormatJSON {
+			prefix, suffix = ",\n", ""
+			if e.count == 1 {
+				prefix = "[\n"
+			}
+		}
+		_, err = io.WriteString(e.out, prefix+string(data)+suffix)
+		return err
+	}
+}
+
+// close дописывает конец файла и сбрасывает буферы. Для пустой выгрузки CSV содержит только заголовок,
+// а JSON — пустой массив.
+func (e *songExport) close() error {
+	switch e.format {
+	case importer.FormatCSV:
+		if e.count == 0 {
+			if err := e.csv.Write(exportColumns); err != nil {
+				return err
+			}
+		}
+		e.csv.Flush()
+		return e.csv.Error()
+	case importer.FormatJSON:
+		end := "\n]\n"
+		if e.count == 0 {
+			end = "[]\n"
+		}
+		_, err := io.WriteString(e.out, end)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newExportTestRouter регистрирует обработчик выгрузки поверх хранилища songs.
+func newExportTestRouter(songs repository.SongRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/songs/export", ExportSongs(newTestLogger(), songs))
+	return router
+}
+
+func TestExportSongsStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{name: "default format", target: "/songs/export", want: http.StatusOK},
+		{name: "unknown format", target: "/songs/export?format=xml", want: http.StatusBadRequest},
+		{name: "invalid gzip", target: "/songs/export?gzip=maybe", want: http.StatusBadRequest},
+		{name: "invalid filter", target: "/songs/export?year=abc", want: http.StatusBadRequest},
+		{name: "invalid sort", target: "/songs/export?sort=text", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(newExportTestRouter(repository.NewMemorySongRepository()), http.MethodGet, tt.target, "", nil)
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d, body %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestExportSongs(t *testing.T) {
+	songs := repository.NewMemorySongRepository()
+	seedSongs(t, songs, "Muse", "Hysteria", "Uprising")
+	seedSongs(t, songs, "Blur", "Song 2")
+	router := newExportTestRouter(songs)
+
+	// titles читает названия песен из выгрузки в формате format
+	titles := func(t *testing.T, format string, body io.Reader) string {
+		t.Helper()
+		var got []string
+		switch format {
+		case "csv":
+			rows, err := csv.NewReader(body).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(rows[0], ",") != "id,groupId,group,song,releaseDate,text,link,version,enrichmentStatus" {
+				t.Fatalf("header %v", rows[0])
+			}
+			for _, row := range rows[1:] {
+				got = append(got, row[3])
+			}
+		case "json":
+			var list []models.Song
+			if err := json.NewDecoder(body).Decode(&list); err != nil {
+				t.Fatal(err)
+			}
+			for _, song := range list {
+				got = append(got, song.Song)
+			}
+		case "ndjson":
+			decoder := json.NewDecoder(body)
+			for decoder.More() {
+				var song models.Song
+				if err := decoder.Decode(&song); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, song.Song)
+			}
+		}
+		return strings.Join(got, ",")
+	}
+
+	tests := []struct {
+		name        string
+		target      string
+		format      string
+		contentType string
+		filename    string
+		want        string
+	}{
+		{name: "csv", target: "/songs/export", format: "csv", contentType: "text/csv; charset=utf-8", filename: "songs.csv", want: "Hysteria,Uprising,Song 2"},
+		{name: "json", target: "/songs/export?format=json", format: "json", contentType: "application/json; charset=utf-8", filename: "songs.json", want: "Hysteria,Uprising,Song 2"},
+		{name: "ndjson", target: "/songs/export?format=ndjson", format: "ndjson", contentType: "application/x-ndjson", filename: "songs.ndjson", want: "Hysteria,Uprising,Song 2"},
+		{name: "filter and sort", target: "/songs/export?format=json&group=muse&sort=-song", format: "json", contentType: "application/json; charset=utf-8", filename: "songs.json", want: "Uprising,Hysteria"},
+		{name: "empty csv", target: "/songs/export?group=Кино", format: "csv", contentType: "text/csv; charset=utf-8", filename: "songs.csv", want: ""},
+		{name: "empty json", target: "/songs/export?format=json&group=Кино", format: "json", contentType: "application/json; charset=utf-8", filename: "songs.json", want: ""},
+		{name: "gzip", target: "/songs/export?format=ndjson&gzip=true", format: "ndjson", contentType: "application/gzip", filename: "songs.ndjson.gz", want: "Hysteria,Uprising,Song 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodGet, tt.target, "", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d, body %s", w.Code, w.Body)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type %q, want %q", got, tt.contentType)
+			}
+			if got := w.Header().Get("Content-Disposition"); !strings.Contains(got, `filename="`+tt.filename+`"`) {
+				t.Errorf("Content-Disposition %q, want file name %s", got, tt.filename)
+			}
+
+			var body io.Reader = w.Body
+			if strings.HasSuffix(tt.filename, ".gz") {
+				gz, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = gz
+			}
+			if got := titles(t, tt.format, body); got != tt.want {
+				t.Errorf("songs %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GetAllGroups возвращает список групп с фильтрацией по названию и пагинацией.
+// @Summary Получение всех групп
+// @Description Возвращает список групп с возможностью фильтрации по названию (с учетом альтернативных названий) и поддержкой пагинации.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param name query string false "Название группы или одно из её альтернативных названий"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество групп на странице" default(5)
+// @Success 200 {object} models.ResponseAllGroups "Список групп"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups [get]
+func GetAllGroups(logger *logrus.Logger, groups repository.GroupRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Query("name")
+		page := c.DefaultQuery("page", "1")
+		limit := c.DefaultQuery("limit", "5")
+
+		// Конвертация параметров пагинации в числа
+		pageInt, err := strconv.Atoi(page)
+		if err != nil || pageInt < 1 {
+			logger.Warnf("Invalid page parameter: %s", page)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid page parameter"})
+			return
+		}
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt < 1 {
+			logger.Warnf("Invalid limit parameter: %s", limit)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid limit parameter"})
+			return
+		}
+
+		// Фильтрация по названию и альтернативным названиям
+		offset := (pageInt - 1) * limitInt
+		list, total, err := groups.List(c.Request.Context(), name, offset, limitInt)
+		if err != nil {
+			logger.Errorf("Failed to retrieve groups: %v", err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve groups"})
+			return
+		}
+
+		logger.Infof("Retrieved %d groups", len(list))
+		c.JSON(http.StatusOK, models.ResponseAllGroups{
+			Total:  total,
+			Page:   pageInt,
+			Limit:  limitInt,
+			Groups: list,
+		})
+	}
+}
+
+// GetGroup возвращает группу по ID.
+// @Summary Получение группы
+// @Description Возвращает группу по её ID вместе с альтернативными названиями.
+// @Tags groups
+// @Produce json
+// @Param id path int true "ID группы"
+// @Success 200 {object} models.Group "Группа"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID группы"
+// @Failure 404 {object} models.ErrorResponse "Группа не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id} [get]
+func GetGroup(logger *logrus.Logger, groups repository.GroupRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid group ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid group ID"})
+			return
+		}
+
+		group, err := groups.Get(c.Request.Context(), id)
+		if err != nil {
+			respondGroupError(c, logger, err, id)
+			return
+		}
+
+		c.JSON(http.StatusOK, group)
+	}
+}
+
+// CreateGroup добавляет новую группу.
+// @Summary Создание группы
+// @Description Добавляет новую группу. Название и альтернативные названия не должны совпадать с названиями других групп без учета регистра и лишних пробелов.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param input body models.GroupInput true "Данные группы"
+// @Success 200 {object} models.Group "Созданная группа"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 409 {object} models.ErrorResponse "Группа уже существует"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups [post]
+func CreateGroup(logger *logrus.Logger, groups repository.GroupRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.GroupInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			logger.Warnf("Failed to bind JSON: %v", err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if utils.NormalizeName(input.Name) == "" {
+			logger.Warn("Group name is empty")
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Group name is required"})
+			return
+		}
+
+		group := models.Group{
+			Name:        utils.NormalizeName(input.Name),
+			NameKey:     utils.NameKey(input.Name),
+			Country:     input.Country,
+			FormedYear:  input.FormedYear,
+			Description: input.Description,
+		}
+		err := groups.Create(c.Request.Context(), &group, input.Aliases)
+		if errors.Is(err, repository.ErrGroupExists) {
+			logger.Warnf("Group already exists: %s", input.Name)
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Group already exists"})
+			return
+		}
+		if errors.Is(err, repository.ErrAliasTaken) {
+			logger.Warnf("Alias of group %s is already used by another group", input.Name)
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to save the group: %v", err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to save the group"})
+			return
+		}
+
+		logger.Infof("Created group: %s with ID: %d", group.Name, group.ID)
+		c.JSON(http.StatusOK, group)
+	}
+}
+
+// UpdateGroup обновляет данные группы по ID.
+// @Summary Обновление группы
+// @Description Обновляет информацию о группе. Передаются только изменяемые поля; переданный список aliases полностью заменяет текущий. При переименовании группы название обновляется и у всех её песен и альбомов; изменение каждой песни получает новую версию в её истории.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param input body models.GroupInput true "Обновлённые данные группы"
+// @Success 200 {object} models.Group "Обновлённая группа"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 404 {object} models.ErrorResponse "Группа не найдена"
+// @Failure 409 {object} models.ErrorResponse "Название уже используется другой группой"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id} [patch]
+func UpdateGroup(logger *logrus.Logger, groups repository.GroupRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid group ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid group ID"})
+			return
+		}
+
+		group, err := groups.Get(c.Request.Context(), id)
+		if err != nil {
+			respondGroupError(c, logger, err, id)
+			return
+		}
+
+		var input models.GroupInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			logger.Warnf("Failed to bind JSON for updating group ID: %d, error: %v", id, err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if input.Name != "" {
+			group.Name = utils.NormalizeName(input.Name)
+			group.NameKey = utils.NameKey(input.Name)
+		}
+		if input.Country != "" {
+			group.Country = input.Country
+		}
+		if input.FormedYear != 0 {
+			group.FormedYear = input.FormedYear
+		}
+		if input.Description != "" {
+			group.Description = input.Description
+		}
+
+		// Репозиторий проверяет, что новое название не занято другой группой
+		err = groups.Update(c.Request.Context(), group, input.Aliases)
+		if errors.Is(err, repository.ErrGroupExists) {
+			logger.Warnf("Group name %s is already used by another group", input.Name)
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Group name is already used by another group"})
+			return
+		}
+		if errors.Is(err, repository.ErrAliasTaken) {
+			logger.Warnf("Alias of group ID: %d is already used by another group", id)
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrGroupNotFound) {
+			respondGroupError(c, logger, err, id)
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to update group ID: %d, error: %v", id, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update the group"})
+			return
+		}
+
+		logger.Infof("Updated group: %s with ID: %d", group.Name, id)
+		c.JSON(http.StatusOK, group)
+	}
+}
+
+// DeleteGroup удаляет группу по ID.
+// @Summary Удаление группы
+// @Description Удаляет группу по её ID. Группу, у которой есть песни или альбомы, удалить нельзя.
+// @Tags groups
+// @Produce json
+// @Param id path int true "ID группы"
+// @Success 200 {object} models.SuccessResponse "Группа успешно удалена"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID группы"
+// @Failure 404 {object} models.ErrorResponse "Группа не найдена"
+// @Failure 409 {object} models.ErrorResponse "У группы есть песни или альбомы"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /groups/{id} [delete]
+func DeleteGroup(logger *logrus.Logger, groups repository.GroupRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid group ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid group ID"})
+			return
+		}
+
+		err = groups.Delete(c.Request.Context(), id)
+		if errors.Is(err, repository.ErrGroupInUse) {
+			logger.Warnf("Attempt to delete group ID: %d with songs or albums", id)
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Group has songs or albums and cannot be deleted"})
+			return
+		}
+		if errors.Is(err, repository.ErrGroupNotFound) {
+			respondGroupError(c, logger, err, id)
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to delete group ID: %d, error: %v", id, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to delete the group"})
+			return
+		}
+
+		logger.Infof("Deleted group with ID: %d", id)
+		c.JSON(http.StatusOK, models.SuccessResponse{Message: "Group deleted successfully"})
+	}
+}
+
+// respondGroupError отвечает 404, если группы нет, и 500 при прочих ошибках чтения группы.
+func respondGroupError(c *gin.Context, logger *logrus.Logger, err error, id uint) {
+	if errors.Is(err, repository.ErrGroupNotFound) {
+		logger.Warnf("Group not found with ID: %d", id)
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Group not found"})
+		return
+	}
+	logger.Errorf("Failed to retrieve group ID: %d, error: %v", id, err)
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve the group"})
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"MusicLibrary/importer"
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// maxImportSize ограничивает размер импортируемого файла.
+const maxImportSize = 10 << 20
+
+// ImportSongs загружает песни из файла CSV, JSON или NDJSON, обогащая не более workers песен одновременно.
+// @Summary Импорт песен из файла
+// @Description Загружает песни из файла: CSV с заголовком, массива JSON или NDJSON (объект в каждой строке). Каждая запись содержит group и song и, необязательно, releaseDate (DD.MM.YYYY), text и link. Файл передаётся телом запроса или полем file формы multipart/form-data, не более 10 МБ.
+// @Description Новые песни создаются, а у существующих заменяются поля, заполненные в файле. Если в файле у новой песни заполнены не все поля, недостающие запрашиваются во внешнем API; enrich=false отключает эти запросы. С dryRun=true ничего не сохраняется: отчёт показывает, что было бы создано, изменено и пропущено.
+// @Tags songs
+// @Accept text/csv,json,application/x-ndjson,mpfd
+// @Produce json
+// @Param format query string false "Формат файла; по умолчанию определяется по расширению имени файла или типу содержимого" Enums(csv, json, ndjson)
+// @Param map query string false "Сопоставление полей песни и столбцов файла, например group=Artist,song=Title,releaseDate=Released"
+// @Param dryRun query bool false "Пробный запуск без сохранения" default(false)
+// @Param enrich query bool false "Запрашивать недостающие данные во внешнем API" default(true)
+// @Param file formData file false "Файл импорта (для multipart/form-data)"
+// @Param X-Actor header string false "Автор изменения для истории версий"
+// @Success 200 {object} models.ImportReport "Отчёт об импорте по каждой записи"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса или файл не удалось прочитать"
+// @Failure 413 {object} models.ErrorResponse "Файл слишком большой"
+// @Router /songs/import [post]
+func ImportSongs(logger *logrus.Logger, songs repository.SongRepository, workers int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+		if err != nil {
+			logger.Warnf("Invalid dryRun parameter: %s", c.Query("dryRun"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid dryRun parameter. Expected true or false"})
+			return
+		}
+		enrich, err := strconv.ParseBool(c.DefaultQuery("enrich", "true"))
+		if err != nil {
+			logger.Warnf("Invalid enrich parameter: %s", c.Query("enrich"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid enrich parameter. Expected true or false"})
+			return
+		}
+		mapping, err := importer.ParseMapping(c.Query("map"))
+		if err != nil {
+			logger.Warnf("Invalid map parameter: %v", err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid map parameter: %v", err)})
+			return
+		}
+
+		// Файл передаётся полем формы или телом запроса целиком
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+		var body io.Reader = c.Request.Body
+		name := ""
+		if c.ContentType() == "multipart/form-data" {
+			file, err := c.FormFile("file")
+			if err != nil {
+				logger.Warnf("Failed to read import file: %v", err)
+				if status := importReadStatus(err); status == http.StatusRequestEntityTooLarge {
+					c.JSON(status, models.ErrorResponse{Error: "File is too large"})
+					return
+				}
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Form field file is required"})
+				return
+			}
+			opened, err := file.Open()
+			if err != nil {
+				logger.Errorf("Failed to open import file: %v", err)
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Failed to read the file"})
+				return
+			}
+			defer opened.Close()
+			body, name = opened, file.Filename
+		}
+
+		format := c.Query("format")
+		if format == "" {
+			format = importer.DetectFormat(name, c.ContentType())
+		}
+		if format == "" {
+			logger.Warnf("Unknown import format for file %q with content type %s", name, c.ContentType())
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Unknown file format. Specify format=csv, json or ndjson"})
+			return
+		}
+
+		records, err := importer.Read(body, format, mapping)
+		if err != nil {
+			logger.Warnf("Failed to read import file: %v", err)
+			c.JSON(importReadStatus(err), models.ErrorResponse{Error: fmt.Sprintf("Failed to read the file: %v", err)})
+			return
+		}
+
+		report := importer.Run(c.Request.Context(), logger, songs, records, importer.Options{
+			DryRun:  dryRun,
+			Enrich:  enrich,
+			Workers: workers,
+		})
+
+		logger.Infof("Imported %d records (dry run: %t): %d created, %d updated, %d skipped, %d failed",
+			report.Total, report.DryRun, report.Created, report.Updated, report.Skipped, report.Failed)
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// importReadStatus возвращает статус ответа для ошибки чтения файла: 413, если превышен размер.
+func importReadStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
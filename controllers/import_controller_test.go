@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newImportTestRouter регистрирует обработчик импорта поверх хранилища songs.
+func newImportTestRouter(songs repository.SongRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/songs/import", ImportSongs(newTestLogger(), songs, 4))
+	return router
+}
+
+func TestImportSongsStatus(t *testing.T) {
+	csv := "group,song,text\nMuse,Hysteria,verse\n"
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		want        int
+	}{
+		{name: "csv body", target: "/songs/import?enrich=false", contentType: "text/csv", body: csv, want: http.StatusOK},
+		{name: "format parameter", target: "/songs/import?enrich=false&format=csv", contentType: "text/plain", body: csv, want: http.StatusOK},
+		{name: "unknown format", target: "/songs/import", contentType: "text/plain", body: csv, want: http.StatusBadRequest},
+		{name: "invalid dry run", target: "/songs/import?dryRun=maybe", contentType: "text/csv", body: csv, want: http.StatusBadRequest},
+		{name: "invalid enrich", target: "/songs/import?enrich=maybe", contentType: "text/csv", body: csv, want: http.StatusBadRequest},
+		{name: "invalid mapping", target: "/songs/import?map=album=Album", contentType: "text/csv", body: csv, want: http.StatusBadRequest},
+		{name: "missing required column", target: "/songs/import", contentType: "text/csv", body: "group,text\nMuse,verse\n", want: http.StatusBadRequest},
+		{name: "json not an array", target: "/songs/import", contentType: "application/json", body: `{}`, want: http.StatusBadRequest},
+		{name: "multipart without file", target: "/songs/import", contentType: "multipart/form-data; boundary=x", body: "--x--\r\n", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(newImportTestRouter(repository.NewMemorySongRepository()), http.MethodPost, tt.target, tt.body,
+				map[string]string{"Content-Type": tt.contentType})
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d, body %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestImportSongsMultipart(t *testing.T) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", "songs.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte("{\"group\":\"Muse\",\"song\":\"Hysteria\",\"text\":\"verse\"}\n{\"group\":\"Muse\",\"song\":\"hysteria\"}\n"))
+	form.Close()
+
+	songs := repository.NewMemorySongRepository()
+	req := httptest.NewRequest(http.MethodPost, "/songs/import?enrich=false", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	newImportTestRouter(songs).ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+
+	var report models.ImportReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != 1 || report.Skipped != 1 || report.Results[1].Line != 2 {
+		t.Errorf("report %+v, want one created song and the repeated line skipped", report)
+	}
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GetJob возвращает фоновую задачу по ID.
+// @Summary Получение фоновой задачи
+// @Description Возвращает состояние фоновой задачи, например обогащения песни после создания: queued (ждёт первой или повторной попытки, runAt — время попытки), running, succeeded или failed (lastError содержит причину).
+// @Tags jobs
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} models.Job "Задача"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID задачи"
+// @Failure 404 {object} models.ErrorResponse "Задача не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /jobs/{id} [get]
+func GetJob(logger *logrus.Logger, jobs repository.JobRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid job ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid job ID"})
+			return
+		}
+
+		job, err := jobs.Get(c.Request.Context(), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrJobNotFound) {
+				logger.Warnf("Job not found with ID: %d", id)
+				c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Job not found"})
+				return
+			}
+			logger.Errorf("Failed to retrieve job ID: %d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve the job"})
+			return
+		}
+		c.JSON(http.StatusOK, job)
+	}
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPageLimit ограничивает количество записей на одной странице списка.
+const maxPageLimit = 100
+
+// parseIDParam извлекает числовой идентификатор из параметра пути с указанным именем.
+// Возвращает ошибку, если значение не является положительным целым числом.
+func parseIDParam(c *gin.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 0)
+	if err != nil || id == 0 {
+		return 0, strconv.ErrSyntax
+	}
+	return uint(id), nil
+}
+
+// parseReleaseDate разбирает дату выпуска в формате DD.MM.YYYY и проверяет, что она не позднее сегодняшнего дня.
+// Возвращаемое сообщение об ошибке предназначено для ответа клиенту.
+func parseReleaseDate(value string) (models.Date, error) {
+	date, err := models.ParseDate(value)
+	if err != nil {
+		return models.Date{}, err
+	}
+	if err := checkReleaseDate(date); err != nil {
+		return models.Date{}, err
+	}
+	return date, nil
+}
+
+// checkReleaseDate проверяет, что дата выпуска не позднее сегодняшнего дня.
+func checkReleaseDate(date models.Date) error {
+	if date.After(time.Now().Truncate(24 * time.Hour)) {
+		return errors.New("Release date cannot be in the future")
+	}
+	return nil
+}
+
+// selectFields возвращает JSON-представление значения, содержащее только перечисленные через запятую поля.
+// Имена полей совпадают с ключами JSON. Для неизвестного поля возвращается ошибка.
+func selectFields(value interface{}, fields string) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]json.RawMessage)
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		raw, ok := all[field]
+		if !ok {
+			return nil, fmt.Errorf("Unknown field: %q", field)
+		}
+		selected[field] = raw
+	}
+	return selected, nil
+}
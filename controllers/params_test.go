@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseIDParam(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		value   string
+		want    uint
+		wantErr bool
+	}{
+		{value: "1", want: 1},
+		{value: "42", want: 42},
+		{value: "0", wantErr: true},
+		{value: "-1", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "", wantErr: true},
+		{value: "+1", wantErr: true},
+		{value: "1.0", wantErr: true},
+		{value: "1e3", wantErr: true},
+		{value: " 1", wantErr: true},
+		{value: "18446744073709551616", wantErr: true},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Params = gin.Params{{Key: "id", Value: tt.value}}
+		id, err := parseIDParam(c, "id")
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseIDParam(%q) = %d, want an error", tt.value, id)
+			}
+			continue
+		}
+		if err != nil || id != tt.want {
+			t.Errorf("parseIDParam(%q) = %d, %v, want %d", tt.value, id, err, tt.want)
+		}
+	}
+}
+
+func TestParseReleaseDate(t *testing.T) {
+	tests := []struct {
+		date    string
+		wantErr bool
+	}{
+		{date: "01.12.2003"},
+		{date: time.Now().AddDate(0, 0, -1).Format("02.01.2006")},
+		{date: "2003-12-01", wantErr: true},
+		{date: "31.02.2003", wantErr: true},
+		{date: "", wantErr: true},
+		{date: time.Now().AddDate(0, 0, 2).Format("02.01.2006"), wantErr: true},
+	}
+	for _, tt := range tests {
+		if _, err := parseReleaseDate(tt.date); (err != nil) != tt.wantErr {
+			t.Errorf("parseReleaseDate(%q) = %v, want error %v", tt.date, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	song := models.Song{ID: 7, Group: "Muse", Song: "Hysteria"}
+	tests := []struct {
+		fields  string
+		want    string
+		wantErr bool
+	}{
+		{fields: "id,song", want: `{"id":7,"song":"Hysteria"}`},
+		{fields: " group , ,id", want: `{"group":"Muse","id":7}`},
+		{fields: ",", want: `{}`},
+		{fields: "id,lyrics", wantErr: true},
+		{fields: "Song", wantErr: true},
+	}
+	for _, tt := range tests {
+		selected, err := selectFields(song, tt.fields)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("selectFields(%q) = %v, want an error", tt.fields, selected)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("selectFields(%q): %v", tt.fields, err)
+			continue
+		}
+		if data, _ := json.Marshal(selected); string(data) != tt.want {
+			t.Errorf("selectFields(%q) = %s, want %s", tt.fields, data, tt.want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// parseRevisionParam разбирает номер версии из параметра пути rev.
+func parseRevisionParam(c *gin.Context) (int, error) {
+	revision, err := strconv.Atoi(c.Param("rev"))
+	if err != nil || revision < 1 {
+		return 0, fmt.Errorf("invalid revision number %q", c.Param("rev"))
+	}
+	return revision, nil
+}
+
+// GetSongRevisions возвращает историю изменений песни.
+// @Summary История изменений песни
+// @Description Возвращает версии песни от новых к старым: действие, изменённые поля, автора и время изменения. Автор берётся из заголовка X-Actor запроса, изменившего песню, или равен IP-адресу клиента. История доступна и для песен в корзине.
+// @Tags songs
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество версий на странице, не более 100" default(20)
+// @Success 200 {object} models.ResponseSongRevisions "Версии песни"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions [get]
+func GetSongRevisions(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid song ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid song ID"})
+			return
+		}
+
+		page := c.DefaultQuery("page", "1")
+		limit := c.DefaultQuery("limit", "20")
+
+		// Конвертация параметров пагинации в числа
+		pageInt, err := strconv.Atoi(page)
+		if err != nil || pageInt < 1 {
+			logger.Warnf("Invalid page parameter: %s", page)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid page parameter"})
+			return
+		}
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt < 1 || limitInt > maxPageLimit {
+			logger.Warnf("Invalid limit parameter: %s", limit)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid limit parameter. Expected a number from 1 to %d", maxPageLimit)})
+			return
+		}
+
+		revisions, total, err := songs.ListRevisions(c.Request.Context(), id, (pageInt-1)*limitInt, limitInt)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				logger.Warnf("Song not found with ID: %d", id)
+				c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Song not found"})
+				return
+			}
+			logger.Errorf("Failed to retrieve revisions of song ID: %d, error: %v", id, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve song revisions"})
+			return
+		}
+
+		logger.Infof("Retrieved %d revisions of song ID: %d", len(revisions), id)
+		c.JSON(http.StatusOK, models.ResponseSongRevisions{
+			Total:     total,
+			Page:      pageInt,
+			Limit:     limitInt,
+			Revisions: revisions,
+		})
+	}
+}
+
+// GetSongRevision возвращает версию песни.
+// @Summary Версия песни
+// @Description Возвращает версию песни с полным снимком и построчным сравнением текста с предыдущей версией. Для первой версии весь текст считается добавленным.
+// @Tags songs
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param rev path int true "Номер версии"
+// @Success 200 {object} models.ResponseSongRevision "Версия песни"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID песни или номер версии"
+// @Failure 404 {object} models.ErrorResponse "Версия не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions/{rev} [get]
+func GetSongRevision(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid song ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid song ID"})
+			return
+		}
+		rev, err := parseRevisionParam(c)
+		if err != nil {
+			logger.Warnf("Invalid revision number: %s", c.Param("rev"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid revision number"})
+			return
+		}
+
+		revision, err := songs.GetRevision(c.Request.Context(), id, rev)
+		if err != nil {
+			if errors.Is(err, repository.ErrRevisionNotFound) {
+				logger.Warnf("Revision %d not found for song ID: %d", rev, id)
+				c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Revision not found"})
+				return
+			}
+			logger.Errorf("Failed to retrieve revision %d of song ID: %d, error: %v", rev, id, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve the revision"})
+			return
+		}
+
+		// Текст сравнивается с предыдущей версией; у первой версии предыдущего текста нет
+		var previousText string
+		if rev > 1 {
+			previous, err := songs.GetRevision(c.Request.Context(), id, rev-1)
+			if err != nil && !errors.Is(err, repository.ErrRevisionNotFound) {
+				logger.Errorf("Failed to retrieve revision %d of song ID: %d, error: %v", rev-1, id, err)
+				c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve the revision"})
+				return
+			}
+			if previous != nil && previous.Snapshot != nil {
+				previousText = previous.Snapshot.Text
+			}
+		}
+		var text string
+		if revision.Snapshot != nil {
+			text = revision.Snapshot.Text
+		}
+
+		logger.Infof("Returning revision %d of song ID: %d", rev, id)
+		c.JSON(http.StatusOK, models.ResponseSongRevision{
+			SongRevision: *revision,
+			TextDiff:     utils.DiffLines(previousText, text),
+		})
+	}
+}
+
+// RevertSongRevision возвращает песню к версии.
+// @Summary Откат песни к версии
+// @Description Возвращает все поля песни (группу, название, дату выпуска, текст и ссылку) к снимку указанной версии. Откат записывается в историю как новая версия, поэтому его тоже можно отменить.
+// @Tags songs
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param rev path int true "Номер версии"
+// @Param X-Actor header string false "Автор изменения для истории версий"
+// @Success 200 {object} models.Song "Песня после отката. Заголовок ETag содержит её новую версию"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID песни или номер версии"
+// @Failure 404 {object} models.ErrorResponse "Песня или версия не найдена"
+// @Failure 409 {object} models.ErrorResponse "У группы уже есть песня с таким названием"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions/{rev}/revert [post]
+func RevertSongRevision(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid song ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid song ID"})
+			return
+		}
+		rev, err := parseRevisionParam(c)
+		if err != nil {
+			logger.Warnf("Invalid revision number: %s", c.Param("rev"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid revision number"})
+			return
+		}
+
+		song, err := songs.Revert(c.Request.Context(), id, rev)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrNotFound):
+				logger.Warnf("Song not found with ID: %d", id)
+				c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Song not found"})
+			case errors.Is(err, repository.ErrRevisionNotFound):
+				logger.Warnf("Revision %d not found for song ID: %d", rev, id)
+				c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Revision not found"})
+			case errors.Is(err, repository.ErrDuplicate):
+				logger.Warnf("Reverting song ID: %d to revision %d would duplicate another song of the group", id, rev)
+				c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Song already exists in the library"})
+			default:
+				logger.Errorf("Failed to revert song ID: %d to revision %d, error: %v", id, rev, err)
+				c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to revert the song"})
+			}
+			return
+		}
+
+		logger.Infof("Reverted song ID: %d to revision %d", id, rev)
+		c.Header("ETag", songETag(song))
+		c.JSON(http.StatusOK, song)
+	}
+}
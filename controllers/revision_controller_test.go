@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSongRevisionHandlers(t *testing.T) {
+	songs := repository.NewMemorySongRepository()
+	router := newSongTestRouter(songs)
+	seedSongs(t, songs, "Muse", "Hysteria")
+	editor := map[string]string{"X-Actor": "editor"}
+	if w := serve(router, http.MethodPatch, "/songs/1", `{"text":"verse\nchorus"}`, editor); w.Code != http.StatusOK {
+		t.Fatalf("patch: status %d, body %s", w.Code, w.Body)
+	}
+
+	tests := []struct {
+		method string
+		target string
+		want   int
+	}{
+		{method: http.MethodGet, target: "/songs/42/revisions", want: http.StatusNotFound},
+		{method: http.MethodGet, target: "/songs/1/revisions?limit=0", want: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/songs/1/revisions/abc", want: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/songs/1/revisions/0", want: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/songs/1/revisions/9", want: http.StatusNotFound},
+		{method: http.MethodPost, target: "/songs/1/revisions/9/revert", want: http.StatusNotFound},
+		{method: http.MethodPost, target: "/songs/42/revisions/1/revert", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := serve(router, tt.method, tt.target, "", nil); w.Code != tt.want {
+			t.Errorf("%s %s: status %d, want %d, body %s", tt.method, tt.target, w.Code, tt.want, w.Body)
+		}
+	}
+
+	w := serve(router, http.MethodGet, "/songs/1/revisions", "", nil)
+	var list models.ResponseSongRevisions
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || list.Total != 2 || len(list.Revisions) != 2 || list.Revisions[0].Actor != "editor" {
+		t.Fatalf("revisions: status %d, %+v, want 2 revisions, the latest by editor", w.Code, list)
+	}
+
+	w = serve(router, http.MethodGet, "/songs/1/revisions/2", "", nil)
+	var revision models.ResponseSongRevision
+	if err := json.Unmarshal(w.Body.Bytes(), &revision); err != nil {
+		t.Fatal(err)
+	}
+	want := []models.DiffLine{{Op: utils.DiffInsert, Text: "verse"}, {Op: utils.DiffInsert, Text: "chorus"}}
+	if w.Code != http.StatusOK || revision.Snapshot == nil || len(revision.TextDiff) != len(want) || revision.TextDiff[0] != want[0] || revision.TextDiff[1] != want[1] {
+		t.Fatalf("revision 2: status %d, %+v, want the text diff %v", w.Code, revision, want)
+	}
+
+	w = serve(router, http.MethodPost, "/songs/1/revisions/1/revert", "", editor)
+	var song models.Song
+	if err := json.Unmarshal(w.Body.Bytes(), &song); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || song.Text != "" {
+		t.Errorf("revert: status %d, %+v, want the song without text", w.Code, song)
+	}
+}
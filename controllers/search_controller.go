@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SearchSongs выполняет полнотекстовый поиск песен по названию, группе и тексту.
+// @Summary Полнотекстовый поиск песен
+// @Description Ищет песни по названию, группе и тексту с учетом словоформ русского и английского языков. Поддерживается синтаксис веб-поиска: фраза в кавычках ищется целиком, "or" означает любое из слов, "-" исключает слово. Результаты упорядочены по релевантности и содержат куплет с подсвеченными совпадениями.
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param q query string true "Поисковый запрос, например \"группа крови\" или love -war"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество результатов на странице, не более 100" default(5)
+// @Success 200 {object} models.ResponseSearchSongs "Найденные песни"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 501 {object} models.ErrorResponse "Поиск недоступен при хранении данных в SQLite"
+// @Router /songs/search [get]
+func SearchSongs(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := strings.TrimSpace(c.Query("q"))
+		if query == "" {
+			logger.Warn("Empty search query")
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Search query is required"})
+			return
+		}
+
+		page := c.DefaultQuery("page", "1")
+		limit := c.DefaultQuery("limit", "5")
+
+		// Конвертация параметров пагинации в числа
+		pageInt, err := strconv.Atoi(page)
+		if err != nil || pageInt < 1 {
+			logger.Warnf("Invalid page parameter: %s", page)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid page parameter"})
+			return
+		}
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt < 1 || limitInt > maxPageLimit {
+			logger.Warnf("Invalid limit parameter: %s", limit)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid limit parameter. Expected a number from 1 to %d", maxPageLimit)})
+			return
+		}
+
+		results, total, err := songs.Search(c.Request.Context(), query, (pageInt-1)*limitInt, limitInt)
+		// Полнотекстовый поиск есть только в PostgreSQL
+		if errors.Is(err, repository.ErrNotSupported) {
+			logger.Warn("Full-text search is not supported by the storage")
+			c.JSON(http.StatusNotImplemented, models.ErrorResponse{Error: "Full-text search requires PostgreSQL"})
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to search songs for %q: %v", query, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to search songs"})
+			return
+		}
+
+		logger.Infof("Found %d songs for query %q", total, query)
+		c.JSON(http.StatusOK, models.ResponseSearchSongs{
+			Query:   query,
+			Total:   total,
+			Page:    pageInt,
+			Limit:   limitInt,
+			Results: results,
+		})
+	}
+}
+
+// FuzzySearchSongs выполняет поиск песен по названию и группе с учетом опечаток.
+// @Summary Поиск песен с опечатками
+// @Description Ищет песни, название или группа которых похожи на запрос, даже если в запросе есть опечатки. Для каждой песни возвращается степень сходства от 0 до 1.
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param q query string true "Название песни или группы, возможно с опечатками"
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество результатов на странице, не более 100" default(5)
+// @Success 200 {object} models.ResponseFuzzySongs "Найденные песни"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 501 {object} models.ErrorResponse "Поиск недоступен при хранении данных в SQLite"
+// @Router /songs/fuzzy [get]
+func FuzzySearchSongs(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := utils.NormalizeName(c.Query("q"))
+		if query == "" {
+			logger.Warn("Empty fuzzy search query")
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Search query is required"})
+			return
+		}
+
+		page := c.DefaultQuery("page", "1")
+		limit := c.DefaultQuery("limit", "5")
+
+		// Конвертация параметров пагинации в числа
+		pageInt, err := strconv.Atoi(page)
+		if err != nil || pageInt < 1 {
+			logger.Warnf("Invalid page parameter: %s", page)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid page parameter"})
+			return
+		}
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt < 1 || limitInt > maxPageLimit {
+			logger.Warnf("Invalid limit parameter: %s", limit)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid limit parameter. Expected a number from 1 to %d", maxPageLimit)})
+			return
+		}
+
+		results, total, err := songs.FuzzySearch(c.Request.Context(), query, (pageInt-1)*limitInt, limitInt)
+		// Поиск по триграммам есть только в PostgreSQL
+		if errors.Is(err, repository.ErrNotSupported) {
+			logger.Warn("Fuzzy search is not supported by the storage")
+			c.JSON(http.StatusNotImplemented, models.ErrorResponse{Error: "Fuzzy search requires PostgreSQL"})
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to fuzzy search songs for %q: %v", query, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to search songs"})
+			return
+		}
+
+		logger.Infof("Found %d similar songs for query %q", total, query)
+		c.JSON(http.StatusOK, models.ResponseFuzzySongs{
+			Query:   query,
+			Total:   total,
+			Page:    pageInt,
+			Limit:   limitInt,
+			Results: results,
+		})
+	}
+}
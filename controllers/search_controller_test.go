@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"MusicLibrary/repository"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newTestLogger возвращает логгер, который ничего не выводит.
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// newGormTestRepository создаёт хранилище песен поверх подключения dialector без проверки соединения.
+func newGormTestRepository(t *testing.T, dialector gorm.Dialector) repository.SongRepository {
+	t.Helper()
+	db, err := gorm.Open(dialector, &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repository.NewGormSongRepository(db)
+}
+
+// TestSearchSongsInvalidQuery проверяет, что некорректные параметры поиска отклоняются до обращения к базе данных.
+func TestSearchSongsInvalidQuery(t *testing.T) {
+	// Подключение к PostgreSQL без сервера: до запроса к базе данных дело доходить не должно
+	songs := newGormTestRepository(t, postgres.Open("host=127.0.0.1 port=1"))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/songs/search", SearchSongs(newTestLogger(), songs))
+	router.GET("/songs/fuzzy", FuzzySearchSongs(newTestLogger(), songs))
+
+	for _, path := range []string{"/songs/search", "/songs/fuzzy"} {
+		for _, query := range []string{
+			"",
+			"?q=%20%20",
+			"?q=love&page=0",
+			"?q=love&page=first",
+			"?q=love&limit=0",
+			"?q=love&limit=101",
+		} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path+query, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("GET %s%s: status %d, want %d", path, query, w.Code, http.StatusBadRequest)
+			}
+		}
+	}
+}
+
+func TestSearchSongsRequiresPostgres(t *testing.T) {
+	repositories := map[string]repository.SongRepository{
+		"sqlite": newGormTestRepository(t, sqlite.Open(":memory:")),
+		"memory": repository.NewMemorySongRepository(),
+	}
+	gin.SetMode(gin.TestMode)
+	for name, songs := range repositories {
+		router := gin.New()
+		router.GET("/songs/search", SearchSongs(newTestLogger(), songs))
+		router.GET("/songs/fuzzy", FuzzySearchSongs(newTestLogger(), songs))
+
+		for _, target := range []string{"/songs/search?q=love", "/songs/fuzzy?q=love"} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+			if w.Code != http.StatusNotImplemented {
+				t.Errorf("%s: GET %s: status %d, want %d", name, target, w.Code, http.StatusNotImplemented)
+			}
+		}
+	}
+}
@@ -7,9 +7,12 @@ Package controllers содержит обработчики HTTP-запросо
 package controllers
 
 import (
-	"MusicLibrary/database"
 	"MusicLibrary/models"
+	"MusicLibrary/repository"
 	"MusicLibrary/utils"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -21,32 +24,57 @@ import (
 
 // GetAllSongs возвращает список всех песен с фильтрацией и пагинацией.
 // @Summary Получение всех песен
-// @Description Возвращает список песен с возможностью фильтрации по группе, названию и дате выпуска, а также поддержкой пагинации.
+// @Description Возвращает список песен с возможностью фильтрации по группе (с учетом альтернативных названий), названию, альбому, дате и периоду выпуска (диапазон дат, год, десятилетие), а также поддержкой сортировки и пагинации по смещению или по ключу (курсору).
 // @Tags songs
 // @Accept json
 // @Produce json
-// @Param group query string false "Название группы"
-// @Param song query string false "Название песни"
+// @Param group query string false "Название группы или одно из её альтернативных названий, в том числе в другой транслитерации"
+// @Param song query string false "Название песни, в том числе в другой транслитерации"
+// @Param album query string false "Название альбома"
 // @Param releaseDate query string false "Дата выпуска в формате DD.MM.YYYY"
-// @Param page query int false "Номер страницы" default(1)
-// @Param limit query int false "Количество песен на странице" default(5)
-// @Success 200 {object} models.ResponseAllSongs "Список песен"
+// @Param releasedFrom query string false "Дата выпуска не ранее указанной, формат DD.MM.YYYY"
+// @Param releasedTo query string false "Дата выпуска не позднее указанной, формат DD.MM.YYYY"
+// @Param year query int false "Год выпуска"
+// @Param decade query string false "Десятилетие выпуска, например 1990 или 1990s"
+// @Param sort query string false "Сортировка: поля id, group, song, releaseDate через запятую; префикс - означает убывание, например -releaseDate,group,song. При равенстве значений песни упорядочиваются по id"
+// @Param pagination query string false "Режим пагинации: offset (по номеру страницы) или cursor (по ключу)" Enums(offset, cursor) default(offset)
+// @Param cursor query string false "Курсор nextCursor или prevCursor из предыдущего ответа; включает пагинацию по ключу. Сортировка должна совпадать с той, для которой выдан курсор"
+// @Param page query int false "Номер страницы (только для пагинации по смещению)" default(1)
+// @Param limit query int false "Количество песен на странице, не более 100" default(5)
+// @Param If-None-Match header string false "ETag из предыдущего ответа; если список не изменился, возвращается 304"
+// @Success 200 {object} models.ResponseAllSongs "Список песен; если по фильтрам group и song ничего не найдено, поле didYouMean содержит похожие названия"
+// @Success 304 "Список не изменился"
 // @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /songs [get]
-func GetAllSongs(logger *logrus.Logger) gin.HandlerFunc {
+func GetAllSongs(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var songs []models.Song
-		var total int64
-
 		// Получение параметров фильтрации
-		group := c.Query("group")
-		song := c.Query("song")
-		releaseDate := c.Query("releaseDate")
+		filters, err := parseSongFilters(c)
+		if err != nil {
+			logger.Warnf("Invalid filter parameters: %v", err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		// Получение параметров сортировки
+		sort, err := parseSongSort(c.Query("sort"))
+		if err != nil {
+			logger.Warnf("Invalid sort parameter: %v", err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
 
 		// Получение параметров пагинации
 		page := c.DefaultQuery("page", "1")
 		limit := c.DefaultQuery("limit", "5")
+		cursorToken := c.Query("cursor")
+		cursorMode := c.Query("pagination") == "cursor" || cursorToken != ""
+		if pagination := c.Query("pagination"); pagination != "" && pagination != "cursor" && pagination != "offset" {
+			logger.Warnf("Invalid pagination parameter: %s", pagination)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid pagination parameter. Expected offset or cursor"})
+			return
+		}
 
 		// Конвертация параметров пагинации в числа
 		pageInt, err := strconv.Atoi(page)
@@ -61,64 +89,147 @@ func GetAllSongs(logger *logrus.Logger) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid limit parameter"})
 			return
 		}
-
-		// Фильтрация
-		query := database.DB.Model(&models.Song{})
-		if group != "" {
-			query = query.Where("\"group\" ILIKE ?", "%"+group+"%")
-		}
-		if song != "" {
-			query = query.Where("song ILIKE ?", "%"+song+"%")
+		if limitInt > maxPageLimit {
+			logger.Warnf("Limit parameter exceeds maximum: %d", limitInt)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Limit parameter must not exceed %d", maxPageLimit)})
+			return
 		}
-		if releaseDate != "" {
-			// Проверка формата даты
-			parsedDate, err := time.Parse("02.01.2006", releaseDate)
-			if err != nil {
-				logger.Warnf("Invalid date format for releaseDate: %s, error: %v", releaseDate, err)
-				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid date format. Expected format: DD.MM.YYYY"})
-				return
-			}
 
-			// Проверка, что дата не позднее сегодняшнего дня
-			if parsedDate.After(time.Now().Truncate(24 * time.Hour)) {
-				logger.Warnf("Release date cannot be in the future: %s", releaseDate)
-				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Release date cannot be in the future"})
+		// Разбор курсора, если он передан
+		var cursor *songCursor
+		if cursorToken != "" {
+			cursor, err = decodeSongCursor(cursorToken, sort)
+			if err != nil {
+				logger.Warnf("Invalid cursor parameter: %v", err)
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
 				return
 			}
-
-			query = query.Where("\"releaseDate\" = ?", releaseDate)
 		}
 
-		// Получение общего количества записей
-		if err := query.Count(&total).Error; err != nil {
-			logger.Errorf("Failed to count songs: %v", err)
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve total count"})
-			return
+		// Пагинация по ключу: выбираем на одну песню больше, чтобы узнать, есть ли следующая страница.
+		// Страница перед курсором выбирается в обратном порядке и затем переворачивается.
+		listQuery := repository.SongListQuery{Filter: filters, Sort: sort, Limit: limitInt}
+		backward := cursor != nil && cursor.Backward
+		if cursorMode {
+			listQuery.Limit = limitInt + 1
+			if backward {
+				listQuery.Sort = sort.reversed()
+			}
+			if cursor != nil {
+				listQuery.After = cursor.keyset()
+			}
+		} else {
+			listQuery.Offset = (pageInt - 1) * limitInt
 		}
 
-		// Пагинация
-		offset := (pageInt - 1) * limitInt
-		if err := query.Offset(offset).Limit(limitInt).Find(&songs).Error; err != nil {
+		result, total, err := songs.List(c.Request.Context(), listQuery)
+		if err != nil {
 			logger.Errorf("Failed to retrieve songs: %v", err)
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve songs"})
 			return
 		}
 
+		response := models.ResponseAllSongs{
+			Total: total,
+			Limit: limitInt,
+		}
+
+		if cursorMode {
+			hasMore := len(result) > limitInt
+			if hasMore {
+				result = result[:limitInt]
+			}
+			if backward {
+				for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+					result[i], result[j] = result[j], result[i]
+				}
+			}
+
+			if len(result) > 0 {
+				if hasMore || backward {
+					response.NextCursor = encodeSongCursor(sort, result[len(result)-1], false)
+				}
+				if (backward && hasMore) || (!backward && cursor != nil) {
+					response.PrevCursor = encodeSongCursor(sort, result[0], true)
+				}
+			}
+		} else {
+			response.Page = pageInt
+		}
+
 		// Логируем полученные данные
-		if len(songs) == 0 {
+		if len(result) == 0 {
 			logger.Warn("No songs found matching the provided filters")
+
+			// Если по фильтрам ничего не найдено, подсказываем похожие названия
+			if total == 0 && (filters.Group != "" || filters.Song != "") {
+				suggestion, err := songs.Suggest(c.Request.Context(), filters)
+				if err != nil {
+					logger.Errorf("Failed to suggest song filters: %v", err)
+				}
+				response.DidYouMean = suggestion
+			}
 		} else {
-			logger.Infof("Retrieved %d songs", len(songs))
+			logger.Infof("Retrieved %d songs", len(result))
 		}
 
-		// Подготовка ответа с метаданными
-		response := models.ResponseAllSongs{
-			Total: total,
-			Page:  pageInt,
-			Limit: limitInt,
-			Songs: songs,
+		response.Songs = result
+		respondJSONWithETag(c, response)
+	}
+}
+
+// GetSong возвращает песню по ID.
+// @Summary Получение песни
+// @Description Возвращает песню по её ID со всеми полями. Параметр fields позволяет запросить только нужные поля (sparse fieldset), например fields=id,song,group.
+// @Tags songs
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param fields query string false "Список полей через запятую: id, groupId, group, song, releaseDate, text, link, version, enrichmentStatus, enrichmentSources"
+// @Param If-None-Match header string false "ETag песни из предыдущего ответа; если песня не изменилась, возвращается 304"
+// @Success 200 {object} models.Song "Песня (при указании fields — только запрошенные поля). Заголовок ETag содержит версию песни"
+// @Success 304 "Песня не изменилась"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID песни или неизвестное поле"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id} [get]
+func GetSong(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid song ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid song ID"})
+			return
 		}
-		c.JSON(http.StatusOK, response)
+
+		song, err := songs.Get(c.Request.Context(), id)
+		if err != nil {
+			respondRepositoryError(c, logger, err, id)
+			return
+		}
+
+		// ETag строится по версии песни и не зависит от набора полей
+		if notModified(c, songETag(song)) {
+			logger.Infof("Song ID: %d not modified", id)
+			return
+		}
+
+		// Без параметра fields возвращаем песню целиком
+		fields := c.Query("fields")
+		if fields == "" {
+			logger.Infof("Returning song ID: %d", id)
+			c.JSON(http.StatusOK, song)
+			return
+		}
+
+		sparse, err := selectFields(song, fields)
+		if err != nil {
+			logger.Warnf("Invalid fields parameter for song ID: %d: %v", id, err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		logger.Infof("Returning fields %s of song ID: %d", fields, id)
+		c.JSON(http.StatusOK, sparse)
 	}
 }
 
@@ -131,20 +242,26 @@ func GetAllSongs(logger *logrus.Logger) gin.HandlerFunc {
 // @Param id path int true "ID песни"
 // @Param page query int false "Номер страницы" default(1)
 // @Param limit query int false "Лимит на куплеты" default(1)
+// @Param If-None-Match header string false "ETag песни из предыдущего ответа; если песня не изменилась, возвращается 304"
 // @Success 200 {object} models.ResponseSongVerses "Информация о песне и ее куплеты, пустой список, если куплеты отсутствуют на запрашиваемой странице"
+// @Success 304 "Песня не изменилась"
 // @Failure 400 {object} models.ErrorResponse "Неверный параметр запроса"
 // @Failure 404 {object} models.ErrorResponse "Песня не найдена"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /songs/{id}/verses [get]
-func GetSongVerses(logger *logrus.Logger) gin.HandlerFunc {
+func GetSongVerses(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var song models.Song
-		id := c.Param("id")
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid song ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid song ID"})
+			return
+		}
 
 		// Проверяем, существует ли песня с данным ID.
-		if err := database.DB.First(&song, id).Error; err != nil {
-			logger.Warnf("Song not found with ID: %s", id)
-			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Song not found"})
+		song, err := songs.Get(c.Request.Context(), id)
+		if err != nil {
+			respondRepositoryError(c, logger, err, id)
 			return
 		}
 
@@ -167,6 +284,11 @@ func GetSongVerses(logger *logrus.Logger) gin.HandlerFunc {
 			return
 		}
 
+		if notModified(c, songETag(song)) {
+			logger.Infof("Song ID: %d not modified", id)
+			return
+		}
+
 		// Разделяем текст песни на куплеты, используя \n\n как разделитель.
 		verses := strings.Split(song.Text, "\n\n")
 
@@ -176,7 +298,7 @@ func GetSongVerses(logger *logrus.Logger) gin.HandlerFunc {
 
 		// Проверка границ.
 		if start >= len(verses) {
-			logger.Warnf("No more verses available for song ID: %s", id)
+			logger.Warnf("No more verses available for song ID: %d", id)
 			// Возвращаем пустой список, соответствующий REST.
 			c.JSON(http.StatusOK, models.ResponseSongVerses{
 				Song:        song.Song,
@@ -194,7 +316,7 @@ func GetSongVerses(logger *logrus.Logger) gin.HandlerFunc {
 		}
 
 		// Логируем успешное получение куплетов.
-		logger.Infof("Returning verses for song ID: %s, page: %d, limit: %d", id, pageInt, limitInt)
+		logger.Infof("Returning verses for song ID: %d, page: %d, limit: %d", id, pageInt, limitInt)
 
 		// Создаем ответ.
 		response := models.ResponseSongVerses{
@@ -212,19 +334,21 @@ func GetSongVerses(logger *logrus.Logger) gin.HandlerFunc {
 	}
 }
 
-// CreateSong добавляет новую песню и обогащает её данные из внешнего API.
+// CreateSong добавляет новую песню и ставит в очередь задачу обогащения её данных из внешнего API
+// не более чем с maxAttempts попытками.
 // @Summary Создание новой песни
-// @Description Добавляет новую песню в библиотеку и обогащает её данные из внешнего API.
+// @Description Сохраняет новую песню со статусом обогащения pending и сразу возвращает 202. Дата выпуска, текст и ссылка запрашиваются во внешнем API в фоне с повторными попытками; за выполнением задачи можно следить по GET /jobs/{id} (заголовок Location), а по завершении у песни меняется enrichmentStatus на enriched или failed.
 // @Tags songs
 // @Accept json
 // @Produce json
 // @Param input body models.SongInput true "Данные песни"
-// @Success 200 {object} models.Song "Созданная песня"
+// @Param X-Actor header string false "Автор изменения для истории версий"
+// @Success 202 {object} models.ResponseSongAccepted "Сохранённая песня и задача обогащения. Заголовок Location содержит адрес задачи, ETag — версию песни"
 // @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
 // @Failure 409 {object} models.ErrorResponse "Песня уже существует"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /songs [post]
-func CreateSong(logger *logrus.Logger) gin.HandlerFunc {
+func CreateSong(logger *logrus.Logger, songs repository.SongRepository, maxAttempts int) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var input models.SongInput
 
@@ -235,141 +359,364 @@ func CreateSong(logger *logrus.Logger) gin.HandlerFunc {
 			return
 		}
 
-		// Проверяем, существует ли песня с таким же названием и группой.
-		var existingSong models.Song
-		if err := database.DB.Where("song = ? AND \"group\" = ?", input.Song, input.Group).First(&existingSong).Error; err == nil {
-			logger.Warnf("Song already exists: %s by %s", input.Song, input.Group)
-			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Song already exists in the library"})
+		// Проверяем, существует ли песня с таким же названием у этой группы.
+		// Группа ищется по нормализованному названию и альтернативным названиям.
+		title := utils.NormalizeName(input.Song)
+		exists, err := songs.ExistsByGroupAndTitle(c.Request.Context(), input.Group, title)
+		if err != nil {
+			logger.Errorf("Failed to check song %s by %s for duplicates: %v", input.Song, input.Group, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to check for duplicate songs"})
 			return
 		}
-
-		// Запрос обогащенной информации из внешнего API.
-		enrichedData, err := utils.FetchSongDetails(input.Group, input.Song)
-		if err != nil {
-			logger.Errorf("Failed to fetch song details: %v", err)
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch song details"})
+		if exists {
+			logger.Warnf("Song already exists: %s by %s", input.Song, input.Group)
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Song already exists in the library"})
 			return
 		}
 
-		// Создаем новую песню из данных запроса.
-		newSong := models.Song{
-			Group:       input.Group,
-			Song:        input.Song,
-			ReleaseDate: enrichedData.ReleaseDate,
-			Text:        enrichedData.Text,
-			Link:        enrichedData.Link,
+		// Песня сохраняется сразу, а данные из внешнего API запрашиваются в фоне, поэтому
+		// недоступность внешнего API не приводит к потере песни.
+		newSong := models.Song{Group: input.Group, Song: title, EnrichmentStatus: models.EnrichmentPending}
+		job := models.Job{
+			Type:        models.JobEnrichSong,
+			Status:      models.JobQueued,
+			MaxAttempts: maxAttempts,
+			RunAt:       time.Now().UTC(),
 		}
 
-		// Сохранение в базу данных.
-		if err := database.DB.Create(&newSong).Error; err != nil {
+		// Сохранение вместе с группой, если она ещё не существует.
+		// Проверка выше не защищает от одновременного создания одной песни, поэтому дубликат
+		// может обнаружить и уникальный индекс базы данных.
+		if err := songs.CreateWithJob(c.Request.Context(), &newSong, &job); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				logger.Warnf("Song already exists: %s by %s", input.Song, input.Group)
+				c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Song already exists in the library"})
+				return
+			}
 			logger.Errorf("Failed to save the song: %v", err)
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to save the song"})
 			return
 		}
 
-		logger.Infof("Created song: %s by %s", newSong.Song, newSong.Group)
-		c.JSON(http.StatusOK, newSong)
+		logger.Infof("Created song: %s by %s, enrichment job %d queued", newSong.Song, newSong.Group, job.ID)
+		c.Header("ETag", songETag(&newSong))
+		c.Header("Location", fmt.Sprintf("/jobs/%d", job.ID))
+		c.JSON(http.StatusAccepted, models.ResponseSongAccepted{Song: newSong, Job: job})
 	}
 }
 
-// UpdateSong обновляет данные песни по ID.
+// enrichSong создаёт песню из данных запроса и дополняет её датой выпуска, текстом и ссылкой из внешнего API
+// вместе с их источниками. title — нормализованное название песни.
+func enrichSong(ctx context.Context, logger *logrus.Logger, input models.SongInput, title string) (models.Song, error) {
+	enrichedData, err := utils.FetchSongDetails(ctx, input.Group, input.Song)
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	// Дата выпуска из внешнего API приходит в формате DD.MM.YYYY; некорректная дата не сохраняется.
+	releaseDate, err := models.ParseDate(enrichedData.ReleaseDate)
+	if err != nil && enrichedData.ReleaseDate != "" {
+		logger.Warnf("Invalid release date %q received for song %s by %s", enrichedData.ReleaseDate, input.Song, input.Group)
+	}
+
+	song := models.Song{
+		Group:       input.Group,
+		Song:        title,
+		ReleaseDate: releaseDate,
+		Text:        enrichedData.Text,
+		Link:        enrichedData.Link,
+	}
+	// Источник некорректной даты не сохраняется вместе с ней
+	if releaseDate.IsZero() {
+		song.EnrichmentSources = enrichedData.Sources.Only("text", "link")
+	} else {
+		song.EnrichmentSources = enrichedData.Sources.Only("releaseDate", "text", "link")
+	}
+	return song, nil
+}
+
+// UpdateSong частично обновляет песню по ID. При requireIfMatch запрос без заголовка If-Match отклоняется.
 // @Summary Обновление песни
-// @Description Обновляет информацию о песне по её ID. Можно передавать только те поля модели, которые требуется изменить; остальные останутся без изменений. Изменение ID песни не допускается.
+// @Description Частично обновляет песню по её ID. Тело запроса — JSON Merge Patch (RFC 7396, application/merge-patch+json или application/json): переданные поля заменяются, null очищает поле, отсутствующие поля не меняются. Очистить можно releaseDate, text и link; group, groupId и song очистить нельзя. Изменение ID песни не допускается. Группу можно сменить по названию (group) или по её ID (groupId).
+// @Description Также принимается JSON Patch (RFC 6902, application/json-patch+json) с путями к полям верхнего уровня, например [{"op":"remove","path":"/link"}].
+// @Description Чтобы не перезаписать чужие изменения, передайте в If-Match ETag песни из последнего ответа: если песню успели изменить, вернётся 412.
 // Ожидаемый формат даты: DD.MM.YYYY
 // @Tags songs
-// @Accept json
+// @Accept json,application/merge-patch+json,application/json-patch+json
 // @Produce json
 // @Param id path int true "ID песни. Изменение ID не допускается."
-// @Param song body models.Song true "Обновлённые данные песни (частичное обновление допускается). Формат даты releaseDate: DD.MM.YYYY"
-// @Success 200 {object} models.Song "Обновлённая песня"
-// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Param song body models.Song true "Изменяемые поля песни; null очищает поле. Формат даты releaseDate: DD.MM.YYYY"
+// @Param X-Actor header string false "Автор изменения для истории версий"
+// @Param If-Match header string false "ETag песни, которую изменяет клиент; обязателен при REQUIRE_IF_MATCH=true"
+// @Success 200 {object} models.Song "Обновлённая песня, заново прочитанная из базы данных. Заголовок ETag содержит её новую версию"
+// @Failure 400 {object} models.ValidationErrorResponse "Ошибка запроса или некорректные значения полей"
 // @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 409 {object} models.ErrorResponse "У группы уже есть песня с таким названием или не прошла операция test JSON Patch"
+// @Failure 412 {object} models.ErrorResponse "Песню изменили после получения ETag"
+// @Failure 415 {object} models.ErrorResponse "Неподдерживаемый тип содержимого"
+// @Failure 428 {object} models.ErrorResponse "Не передан обязательный заголовок If-Match"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /songs/{id} [patch]
-func UpdateSong(logger *logrus.Logger) gin.HandlerFunc {
+func UpdateSong(logger *logrus.Logger, songs repository.SongRepository, requireIfMatch bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var song models.Song
-		id := c.Param("id")
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid song ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid song ID"})
+			return
+		}
 
 		// Проверка на существование песни по ID
-		if err := database.DB.First(&song, id).Error; err != nil {
-			logger.Warnf("Song not found with ID: %s", id)
-			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Song not found"})
+		song, err := songs.Get(c.Request.Context(), id)
+		if err != nil {
+			respondRepositoryError(c, logger, err, id)
+			return
+		}
+		if ifMatchFailed(c, logger, song, requireIfMatch) {
 			return
 		}
 
-		// Обновление данных
-		var input models.Song
-		if err := c.ShouldBindJSON(&input); err != nil {
-			logger.Warnf("Failed to bind JSON for updating song ID: %s, error: %v", id, err)
+		body, err := c.GetRawData()
+		if err != nil {
+			logger.Warnf("Failed to read request body for updating song ID: %d, error: %v", id, err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Failed to read the request body"})
+			return
+		}
+
+		// JSON Patch применяется к текущей песне и приводится к Merge Patch
+		merge, err := songMergePatch(c.ContentType(), body, song)
+		if err != nil {
+			switch {
+			case errors.Is(err, errUnsupportedPatch):
+				logger.Warnf("Unsupported content type %s for updating song ID: %d", c.ContentType(), id)
+				c.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{Error: err.Error()})
+			case errors.Is(err, utils.ErrPatchTestFailed):
+				logger.Warnf("JSON Patch test failed for song ID: %d: %v", id, err)
+				c.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+			default:
+				logger.Warnf("Invalid patch for song ID: %d: %v", id, err)
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			}
+			return
+		}
+
+		// Проверка каждого поля: тип значения, формат и допустимость очистки
+		patch, fieldErrors, err := songPatchFromMerge(merge, song)
+		if err != nil {
+			logger.Warnf("Patch of song ID: %d is based on an outdated version", id)
+			c.JSON(http.StatusPreconditionFailed, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if len(fieldErrors) > 0 {
+			logger.Warnf("Invalid fields in patch for song ID: %d: %v", id, fieldErrors)
+			c.JSON(http.StatusBadRequest, models.ValidationErrorResponse{Error: "Invalid song fields", Fields: fieldErrors})
+			return
+		}
+
+		// Применение изменений. Группа меняется по названию (с созданием группы при необходимости) или по ID.
+		// Версия проверяется ещё раз при записи: песню могли изменить после проверки If-Match
+		song, err = songs.Update(c.Request.Context(), id, patch, song.Version)
+		if err != nil {
+			respondRepositoryError(c, logger, err, id)
+			return
+		}
+
+		logger.Infof("Updated song: %s by %s with ID: %d", song.Song, song.Group, id)
+		c.Header("ETag", songETag(song))
+		c.JSON(http.StatusOK, song)
+	}
+}
+
+// ReplaceSong полностью заменяет песню по ID. При requireIfMatch запрос без заголовка If-Match отклоняется.
+// @Summary Полная замена песни
+// @Description Заменяет все изменяемые поля песни значениями из запроса. В отличие от PATCH, который меняет только переданные поля, PUT принимает песню целиком: обязательны song и group (или groupId), а не переданные releaseDate, text и link очищаются. Повторный запрос с тем же телом не меняет песню, поэтому PUT подходит для синхронизации с внешними системами.
+// @Description Значения проверяются так же, как в PATCH: дата в формате DD.MM.YYYY не позднее сегодняшнего дня, ссылка — абсолютный URL http или https. Поле id, если передано, должно совпадать с ID в пути; version только для чтения.
+// @Description С параметром upsert=true отсутствующая песня создаётся с указанным ID без обогащения из внешнего API; при создании группа определяется по названию group, если оно передано.
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param song body models.Song true "Песня целиком. Формат даты releaseDate: DD.MM.YYYY"
+// @Param upsert query bool false "Создать песню, если песни с таким ID нет" default(false)
+// @Param X-Actor header string false "Автор изменения для истории версий"
+// @Param If-Match header string false "ETag заменяемой песни; обязателен при REQUIRE_IF_MATCH=true"
+// @Success 200 {object} models.Song "Заменённая песня. Заголовок ETag содержит её новую версию"
+// @Success 201 {object} models.Song "Созданная песня (upsert=true)"
+// @Failure 400 {object} models.ValidationErrorResponse "Ошибка запроса или некорректные значения полей"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 409 {object} models.ErrorResponse "У группы уже есть песня с таким названием или ID занят песней в корзине"
+// @Failure 412 {object} models.ErrorResponse "Песню изменили после получения ETag"
+// @Failure 428 {object} models.ErrorResponse "Не передан обязательный заголовок If-Match"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id} [put]
+func ReplaceSong(logger *logrus.Logger, songs repository.SongRepository, requireIfMatch bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid song ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid song ID"})
+			return
+		}
+		upsert := c.DefaultQuery("upsert", "false")
+		if upsert != "true" && upsert != "false" {
+			logger.Warnf("Invalid upsert parameter: %s", upsert)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid upsert parameter. Expected true or false"})
+			return
+		}
+
+		body, err := c.GetRawData()
+		if err != nil {
+			logger.Warnf("Failed to read request body for replacing song ID: %d, error: %v", id, err)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Failed to read the request body"})
+			return
+		}
+		merge, fieldErrors, err := songReplacementMerge(body)
+		if err != nil {
+			logger.Warnf("Invalid song for replacing song ID: %d: %v", id, err)
 			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
 			return
 		}
 
-		// Проверка на изменение ID
-		if input.ID != 0 && input.ID != song.ID {
-			logger.Warnf("Attempt to change ID for song ID: %s, new ID: %d", id, input.ID)
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Changing the song ID is not allowed"})
+		song, err := songs.Get(c.Request.Context(), id)
+		if err != nil && (upsert == "false" || !errors.Is(err, repository.ErrNotFound)) {
+			respondRepositoryError(c, logger, err, id)
 			return
 		}
 
-		// Проверка поля ReleaseDate на соответствие формату DD.MM.YYYY и на то, что дата не позднее сегодняшнего дня
-		if input.ReleaseDate != "" {
-			parsedDate, err := time.Parse("02.01.2006", input.ReleaseDate)
-			if err != nil {
-				logger.Warnf("Invalid date format for song ID: %s, error: %v", id, err)
-				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid date format. Expected format: DD.MM.YYYY"})
-				return
+		// Песни нет: она будет создана, поэтому поля проверяются относительно пустой песни с ID из пути
+		creating := song == nil
+		if creating {
+			song = &models.Song{ID: id}
+			delete(merge, "version")
+			delete(merge, "enrichmentStatus")
+			delete(merge, "enrichmentSources")
+			if _, ok := merge["group"]; ok {
+				delete(merge, "groupId")
 			}
+		} else if ifMatchFailed(c, logger, song, requireIfMatch) {
+			return
+		}
+
+		patch, patchErrors, err := songPatchFromMerge(merge, song)
+		if err != nil {
+			logger.Warnf("Replacement of song ID: %d is based on an outdated version", id)
+			c.JSON(http.StatusPreconditionFailed, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		for field, message := range patchErrors {
+			fieldErrors[field] = message
+		}
+		if len(fieldErrors) > 0 {
+			logger.Warnf("Invalid fields in replacement of song ID: %d: %v", id, fieldErrors)
+			c.JSON(http.StatusBadRequest, models.ValidationErrorResponse{Error: "Invalid song fields", Fields: fieldErrors})
+			return
+		}
 
-			// Проверка, что дата не позднее сегодняшнего дня
-			if parsedDate.After(time.Now().Truncate(24 * time.Hour)) {
-				logger.Warnf("Release date cannot be in the future for song ID: %s", id)
-				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Release date cannot be in the future"})
+		if creating {
+			newSong := models.Song{ID: id, Song: *patch.Song, ReleaseDate: *patch.ReleaseDate, Text: *patch.Text, Link: *patch.Link}
+			if patch.Group != nil {
+				newSong.Group = *patch.Group
+			} else {
+				newSong.GroupID = *patch.GroupID
+			}
+			if err := songs.Create(c.Request.Context(), &newSong); err != nil {
+				respondRepositoryError(c, logger, err, id)
 				return
 			}
+
+			logger.Infof("Created song: %s by %s with ID: %d", newSong.Song, newSong.Group, id)
+			c.Header("ETag", songETag(&newSong))
+			c.Header("Location", fmt.Sprintf("/songs/%d", id))
+			c.JSON(http.StatusCreated, newSong)
+			return
 		}
 
-		// Применение изменений к базе данных
-		if err := database.DB.Model(&song).Updates(input).Error; err != nil {
-			logger.Errorf("Failed to update song ID: %s, error: %v", id, err)
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to update the song"})
+		song, err = songs.Update(c.Request.Context(), id, patch, song.Version)
+		if err != nil {
+			respondRepositoryError(c, logger, err, id)
 			return
 		}
 
-		logger.Infof("Updated song: %s by %s with ID: %s", song.Song, song.Group, id)
+		logger.Infof("Replaced song: %s by %s with ID: %d", song.Song, song.Group, id)
+		c.Header("ETag", songETag(song))
 		c.JSON(http.StatusOK, song)
 	}
 }
 
-// DeleteSong удаляет песню по ID.
+// DeleteSong перемещает песню в корзину по ID. При requireIfMatch запрос без заголовка If-Match отклоняется.
 // @Summary Удаление песни
-// @Description Удаляет песню из библиотеки по её ID.
+// @Description Перемещает песню в корзину по её ID. Песня перестаёт возвращаться в списках и поиске, но её можно восстановить до окончательного удаления.
+// @Description Если передан If-Match, песня удаляется, только если она не менялась с получения этого ETag.
 // @Tags songs
 // @Produce json
 // @Param id path int true "ID песни"
+// @Param X-Actor header string false "Автор изменения для истории версий"
+// @Param If-Match header string false "ETag удаляемой песни; обязателен при REQUIRE_IF_MATCH=true"
 // @Success 200 {object} models.SuccessResponse "Песня успешно удалена"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID песни"
 // @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 412 {object} models.ErrorResponse "Песню изменили после получения ETag"
+// @Failure 428 {object} models.ErrorResponse "Не передан обязательный заголовок If-Match"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /songs/{id} [delete]
-func DeleteSong(logger *logrus.Logger) gin.HandlerFunc {
+func DeleteSong(logger *logrus.Logger, songs repository.SongRepository, requireIfMatch bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var song models.Song
-		id := c.Param("id")
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid song ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid song ID"})
+			return
+		}
 
-		if err := database.DB.First(&song, id).Error; err != nil {
-			logger.Warnf("Song not found with ID: %s", id)
-			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Song not found"})
+		song, err := songs.Get(c.Request.Context(), id)
+		if err != nil {
+			respondRepositoryError(c, logger, err, id)
+			return
+		}
+		if ifMatchFailed(c, logger, song, requireIfMatch) {
 			return
 		}
 
-		if err := database.DB.Delete(&song).Error; err != nil {
-			logger.Errorf("Failed to delete song ID: %s, error: %v", id, err)
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to delete the song"})
+		if err := songs.Delete(c.Request.Context(), id, song.Version); err != nil {
+			respondRepositoryError(c, logger, err, id)
 			return
 		}
 
-		logger.Infof("Deleted song: %s by %s with ID: %s", song.Song, song.Group, id)
+		logger.Infof("Deleted song: %s by %s with ID: %d", song.Song, song.Group, id)
 		c.JSON(http.StatusOK, models.SuccessResponse{Message: "Song deleted successfully"})
 	}
 }
+
+// songFailureMessages задаёт текст ответа 500 для ошибок хранилища по методу запроса к песне.
+var songFailureMessages = map[string]string{
+	http.MethodGet:    "Failed to retrieve the song",
+	http.MethodPatch:  "Failed to update the song",
+	http.MethodPut:    "Failed to update the song",
+	http.MethodDelete: "Failed to delete the song",
+}
+
+// respondRepositoryError отвечает на ошибку хранилища при работе с песней id: неизвестная группа — 400,
+// отсутствующая песня — 404, дубликат — 409, устаревшая версия — 412, остальные ошибки — 500.
+func respondRepositoryError(c *gin.Context, logger *logrus.Logger, err error, id uint) {
+	switch {
+	case errors.Is(err, repository.ErrGroupNotFound):
+		logger.Warnf("Group not found for song ID: %d", id)
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Group not found"})
+	case errors.Is(err, repository.ErrNotFound):
+		logger.Warnf("Song not found with ID: %d", id)
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Song not found"})
+	case errors.Is(err, repository.ErrDuplicate):
+		logger.Warnf("Song ID: %d would duplicate another song of the group or its ID is taken", id)
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Song already exists in the library"})
+	case errors.Is(err, repository.ErrVersionMismatch):
+		logger.Warnf("Song ID: %d was modified by another request", id)
+		c.JSON(http.StatusPreconditionFailed, models.ErrorResponse{Error: "Song has been modified by another request"})
+	default:
+		logger.Errorf("Failed to %s song ID: %d, error: %v", c.Request.Method, id, err)
+		message, ok := songFailureMessages[c.Request.Method]
+		if !ok {
+			message = "Failed to process the song"
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: message})
+	}
+}
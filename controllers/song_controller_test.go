@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newSongTestRouter регистрирует обработчики песен поверх хранилища songs так же, как routes.SetupRouter.
+func newSongTestRouter(songs repository.SongRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := newTestLogger()
+
+	r := gin.New()
+	r.Use(Actor())
+	r.GET("/songs", GetAllSongs(logger, songs))
+	r.GET("/songs/trash", GetTrash(logger, songs))
+	r.DELETE("/songs/trash/:id", PurgeSong(logger, songs))
+	r.GET("/songs/:id", GetSong(logger, songs))
+	r.GET("/songs/:id/verses", GetSongVerses(logger, songs))
+	r.POST("/songs", CreateSong(logger, songs, 3))
+	r.PATCH("/songs/:id", UpdateSong(logger, songs, false))
+	r.PUT("/songs/:id", ReplaceSong(logger, songs, false))
+	r.DELETE("/songs/:id", DeleteSong(logger, songs, false))
+	r.POST("/songs/:id/restore", RestoreSong(logger, songs))
+	r.GET("/songs/:id/revisions", GetSongRevisions(logger, songs))
+	r.GET("/songs/:id/revisions/:rev", GetSongRevision(logger, songs))
+	r.POST("/songs/:id/revisions/:rev/revert", RevertSongRevision(logger, songs))
+	return r
+}
+
+// serve выполняет запрос к router и возвращает ответ.
+func serve(router http.Handler, method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// seedSongs сохраняет песни группы group с названиями titles.
+func seedSongs(t *testing.T, songs repository.SongRepository, group string, titles ...string) {
+	t.Helper()
+	for _, title := range titles {
+		if err := songs.Create(context.Background(), &models.Song{Group: group, Song: title}); err != nil {
+			t.Fatalf("create %q: %v", title, err)
+		}
+	}
+}
+
+// staleSongRepository имитирует изменение песни другим запросом между чтением и записью.
+type staleSongRepository struct {
+	repository.SongRepository
+}
+
+func (r staleSongRepository) Update(ctx context.Context, id uint, patch models.SongPatch, expected uint) (*models.Song, error) {
+	return nil, repository.ErrVersionMismatch
+}
+
+func (r staleSongRepository) Delete(ctx context.Context, id uint, expected uint) error {
+	return repository.ErrVersionMismatch
+}
+
+// brokenSongRepository имитирует сбой базы данных при любом обращении к песням.
+type brokenSongRepository struct {
+	repository.SongRepository
+}
+
+// errBroken — ошибка brokenSongRepository.
+var errBroken = errors.New("connection refused")
+
+func (r brokenSongRepository) Get(ctx context.Context, id uint) (*models.Song, error) {
+	return nil, errBroken
+}
+
+func (r brokenSongRepository) ExistsByGroupAndTitle(ctx context.Context, group, title string) (bool, error) {
+	return false, errBroken
+}
+
+func TestSongHandlersRepositoryFailure(t *testing.T) {
+	router := newSongTestRouter(brokenSongRepository{repository.NewMemorySongRepository()})
+	tests := []struct {
+		method string
+		target string
+		body   string
+		want   string
+	}{
+		{method: http.MethodGet, target: "/songs/1", want: "Failed to retrieve the song"},
+		{method: http.MethodGet, target: "/songs/1/verses", want: "Failed to retrieve the song"},
+		{method: http.MethodPatch, target: "/songs/1", body: `{"text":"a"}`, want: "Failed to update the song"},
+		{method: http.MethodPut, target: "/songs/1", body: `{"group":"Muse","song":"Hysteria"}`, want: "Failed to update the song"},
+		{method: http.MethodDelete, target: "/songs/1", want: "Failed to delete the song"},
+		{method: http.MethodPost, target: "/songs", body: `{"group":"Muse","song":"Hysteria"}`, want: "Failed to check for duplicate songs"},
+	}
+	for _, tt := range tests {
+		w := serve(router, tt.method, tt.target, tt.body, nil)
+		var response models.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusInternalServerError || response.Error != tt.want {
+			t.Errorf("%s %s: status %d, error %q, want %d and %q", tt.method, tt.target, w.Code, response.Error, http.StatusInternalServerError, tt.want)
+		}
+	}
+}
+
+func TestSongHandlersStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		headers map[string]string
+		stale   bool
+		want    int
+	}{
+		{name: "get", method: http.MethodGet, target: "/songs/1", want: http.StatusOK},
+		{name: "get fields", method: http.MethodGet, target: "/songs/1?fields=id,song", want: http.StatusOK},
+		{name: "get unknown field", method: http.MethodGet, target: "/songs/1?fields=lyrics", want: http.StatusBadRequest},
+		{name: "get missing", method: http.MethodGet, target: "/songs/42", want: http.StatusNotFound},
+		{name: "get invalid id", method: http.MethodGet, target: "/songs/abc", want: http.StatusBadRequest},
+		{name: "not modified", method: http.MethodGet, target: "/songs/1", headers: map[string]string{"If-None-Match": `"1"`}, want: http.StatusNotModified},
+		{name: "modified", method: http.MethodGet, target: "/songs/1", headers: map[string]string{"If-None-Match": `"7"`}, want: http.StatusOK},
+		{name: "list not modified", method: http.MethodGet, target: "/songs", headers: map[string]string{"If-None-Match": "*"}, want: http.StatusNotModified},
+		{name: "verses", method: http.MethodGet, target: "/songs/1/verses", want: http.StatusOK},
+		{name: "verses missing", method: http.MethodGet, target: "/songs/42/verses", want: http.StatusNotFound},
+		{name: "list invalid sort", method: http.MethodGet, target: "/songs?sort=text", want: http.StatusBadRequest},
+		{name: "list invalid cursor", method: http.MethodGet, target: "/songs?cursor=abc", want: http.StatusBadRequest},
+		{name: "create duplicate", method: http.MethodPost, target: "/songs", body: `{"group":"muse","song":"hysteria"}`, want: http.StatusConflict},
+		{name: "patch missing", method: http.MethodPatch, target: "/songs/42", body: `{"text":"a"}`, want: http.StatusNotFound},
+		{name: "patch unknown group id", method: http.MethodPatch, target: "/songs/1", body: `{"groupId":42}`, want: http.StatusBadRequest},
+		{name: "patch duplicate title", method: http.MethodPatch, target: "/songs/2", body: `{"song":"HYSTERIA"}`, want: http.StatusConflict},
+		{name: "patch id", method: http.MethodPatch, target: "/songs/1", body: `{"id":2}`, want: http.StatusBadRequest},
+		{name: "patch stale if-match", method: http.MethodPatch, target: "/songs/1", body: `{"text":"a"}`, headers: map[string]string{"If-Match": `"7"`}, want: http.StatusPreconditionFailed},
+		{name: "patch concurrent change", method: http.MethodPatch, target: "/songs/1", body: `{"text":"a"}`, stale: true, want: http.StatusPreconditionFailed},
+		{name: "patch", method: http.MethodPatch, target: "/songs/1", body: `{"text":"a"}`, want: http.StatusOK},
+		{name: "merge patch", method: http.MethodPatch, target: "/songs/1", body: `{"link":null}`, headers: map[string]string{"Content-Type": "application/merge-patch+json"}, want: http.StatusOK},
+		{name: "json patch", method: http.MethodPatch, target: "/songs/1", body: `[{"op":"replace","path":"/song","value":"Uprising"}]`, headers: map[string]string{"Content-Type": "application/json-patch+json"}, want: http.StatusOK},
+		{name: "json patch test failed", method: http.MethodPatch, target: "/songs/1", body: `[{"op":"test","path":"/song","value":"Uprising"}]`, headers: map[string]string{"Content-Type": "application/json-patch+json"}, want: http.StatusConflict},
+		{name: "patch unsupported content type", method: http.MethodPatch, target: "/songs/1", body: `text`, headers: map[string]string{"Content-Type": "text/plain"}, want: http.StatusUnsupportedMediaType},
+		{name: "patch clear title", method: http.MethodPatch, target: "/songs/1", body: `{"song":""}`, want: http.StatusBadRequest},
+		{name: "patch if-match", method: http.MethodPatch, target: "/songs/1", body: `{"text":"a"}`, headers: map[string]string{"If-Match": `"1"`}, want: http.StatusOK},
+		{name: "put", method: http.MethodPut, target: "/songs/1", body: `{"group":"Muse","song":"Hysteria","text":"verse"}`, want: http.StatusOK},
+		{name: "put missing", method: http.MethodPut, target: "/songs/42", body: `{"group":"Muse","song":"Uprising"}`, want: http.StatusNotFound},
+		{name: "put without title", method: http.MethodPut, target: "/songs/1", body: `{"group":"Muse"}`, want: http.StatusBadRequest},
+		{name: "put duplicate", method: http.MethodPut, target: "/songs/2", body: `{"group":"Muse","song":"Hysteria"}`, want: http.StatusConflict},
+		{name: "put stale if-match", method: http.MethodPut, target: "/songs/1", body: `{"group":"Muse","song":"Hysteria"}`, headers: map[string]string{"If-Match": `"7"`}, want: http.StatusPreconditionFailed},
+		{name: "put upsert", method: http.MethodPut, target: "/songs/42?upsert=true", body: `{"group":"Muse","song":"Uprising"}`, want: http.StatusCreated},
+		{name: "put upsert duplicate", method: http.MethodPut, target: "/songs/42?upsert=true", body: `{"group":"Muse","song":"Hysteria"}`, want: http.StatusConflict},
+		{name: "delete missing", method: http.MethodDelete, target: "/songs/42", want: http.StatusNotFound},
+		{name: "delete stale if-match", method: http.MethodDelete, target: "/songs/1", headers: map[string]string{"If-Match": `"7"`}, want: http.StatusPreconditionFailed},
+		{name: "delete concurrent change", method: http.MethodDelete, target: "/songs/1", stale: true, want: http.StatusPreconditionFailed},
+		{name: "delete", method: http.MethodDelete, target: "/songs/1", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := repository.NewMemorySongRepository()
+			seedSongs(t, memory, "Muse", "Hysteria", "Starlight")
+
+			var songs repository.SongRepository = memory
+			if tt.stale {
+				songs = staleSongRepository{memory}
+			}
+			w := serve(newSongTestRouter(songs), tt.method, tt.target, tt.body, tt.headers)
+			if w.Code != tt.want {
+				t.Fatalf("%s %s: status %d, want %d, body %s", tt.method, tt.target, w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestReplaceSongClearsOmittedFields(t *testing.T) {
+	songs := repository.NewMemorySongRepository()
+	if err := songs.Create(context.Background(), &models.Song{Group: "Muse", Song: "Hysteria", Text: "verse", Link: "https://example.com/hysteria"}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := serve(newSongTestRouter(songs), http.MethodPut, "/songs/1", `{"group":"Muse","song":"Hysteria","text":"new verse"}`, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	song, err := songs.Get(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if song.Text != "new verse" || song.Link != "" || song.Version != 2 {
+		t.Errorf("replaced song %+v, want the new text, no link and version 2", song)
+	}
+	if got := w.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("ETag %q, want \"2\"", got)
+	}
+}
+
+func TestCreateSongQueuesEnrichment(t *testing.T) {
+	songs := repository.NewMemorySongRepository()
+	router := newSongTestRouter(songs)
+	router.GET("/jobs/:id", GetJob(newTestLogger(), repository.NewMemoryJobRepository(songs)))
+
+	w := serve(router, http.MethodPost, "/songs", `{"group":" Muse ","song":"Hysteria"}`, nil)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status %d, want %d, body %s", w.Code, http.StatusAccepted, w.Body)
+	}
+	var accepted models.ResponseSongAccepted
+	if err := json.Unmarshal(w.Body.Bytes(), &accepted); err != nil {
+		t.Fatal(err)
+	}
+	if accepted.Song.Group != "Muse" || accepted.Song.EnrichmentStatus != models.EnrichmentPending || accepted.Job.Status != models.JobQueued {
+		t.Errorf("song %+v, job status %q, want pending song of the normalized group and queued job", accepted.Song, accepted.Job.Status)
+	}
+	if accepted.Job.MaxAttempts != 3 {
+		t.Errorf("job max attempts %d, want 3 passed to the handler", accepted.Job.MaxAttempts)
+	}
+	location := w.Header().Get("Location")
+	if location != "/jobs/1" {
+		t.Fatalf("Location %q, want /jobs/1", location)
+	}
+
+	w = serve(router, http.MethodGet, location, "", nil)
+	var job models.Job
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	if job.SongID != accepted.Song.ID {
+		t.Errorf("queued job %+v, want job for song %d", job, accepted.Song.ID)
+	}
+	if w := serve(router, http.MethodGet, "/jobs/42", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("missing job: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestGetAllSongsCursorPages(t *testing.T) {
+	songs := repository.NewMemorySongRepository()
+	seedSongs(t, songs, "Muse", "E", "C", "A", "D", "B")
+	router := newSongTestRouter(songs)
+
+	page := func(query string) models.ResponseAllSongs {
+		t.Helper()
+		w := serve(router, http.MethodGet, "/songs?sort=song&limit=2&"+query, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("query %s: status %d, body %s", query, w.Code, w.Body)
+		}
+		var response models.ResponseAllSongs
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+	titles := func(response models.ResponseAllSongs) string {
+		var names []string
+		for _, song := range response.Songs {
+			names = append(names, song.Song)
+		}
+		return strings.Join(names, ",")
+	}
+
+	first := page("pagination=cursor")
+	if got := titles(first); got != "A,B" || first.PrevCursor != "" || first.NextCursor == "" {
+		t.Fatalf("first page %s, prev %q, next %q", got, first.PrevCursor, first.NextCursor)
+	}
+	second := page("cursor=" + first.NextCursor)
+	if got := titles(second); got != "C,D" || second.PrevCursor == "" || second.NextCursor == "" {
+		t.Fatalf("second page %s, prev %q, next %q", got, second.PrevCursor, second.NextCursor)
+	}
+	last := page("cursor=" + second.NextCursor)
+	if got := titles(last); got != "E" || last.NextCursor != "" {
+		t.Fatalf("last page %s, next %q", got, last.NextCursor)
+	}
+	back := page("cursor=" + second.PrevCursor)
+	if got := titles(back); got != "A,B" || back.PrevCursor != "" {
+		t.Fatalf("page before the second %s, prev %q", got, back.PrevCursor)
+	}
+	if first.Total != 5 {
+		t.Errorf("total %d, want 5", first.Total)
+	}
+
+	// Курсор выдан для другой сортировки
+	w := serve(router, http.MethodGet, "/songs?sort=-song&cursor="+first.NextCursor, "", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("cursor with another sort: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetAllSongsDidYouMean(t *testing.T) {
+	songs := repository.NewMemorySongRepository()
+	seedSongs(t, songs, "Muse", "Hysteria")
+
+	w := serve(newSongTestRouter(songs), http.MethodGet, "/songs?song=Hystera", "", nil)
+	var response models.ResponseAllSongs
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Total != 0 || response.DidYouMean == nil || response.DidYouMean.Song != "Hysteria" {
+		t.Errorf("response %+v, want no songs and a suggestion for Hysteria", response)
+	}
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// errInvalidCursor возвращается, если курсор не удалось разобрать.
+var errInvalidCursor = errors.New("Invalid cursor")
+
+// songCursor описывает позицию в упорядоченном списке песен для постраничной навигации по ключу (keyset).
+// Клиент получает курсор в непрозрачном виде и передаёт его без изменений.
+type songCursor struct {
+	Sort     string   `json:"s"`           // Порядок сортировки, для которого выдан курсор
+	Values   []string `json:"v"`           // Значения полей сортировки (кроме id) у граничной песни
+	ID       uint     `json:"id"`          // ID граничной песни
+	Backward bool     `json:"b,omitempty"` // Направление: true — страница перед граничной песней
+}
+
+// encodeSongCursor формирует курсор, указывающий на песню song при порядке сортировки sort.
+func encodeSongCursor(sort songSort, song models.Song, backward bool) string {
+	cursor := songCursor{Sort: sort.String(), ID: song.ID, Backward: backward}
+	for _, field := range sort {
+		if field.Name != "id" {
+			cursor.Values = append(cursor.Values, repository.SongSortValue(field.Name, song))
+		}
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSongCursor разбирает курсор и проверяет, что он выдан для того же порядка сортировки.
+func decodeSongCursor(token string, sort songSort) (*songCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var cursor songCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errInvalidCursor
+	}
+	if cursor.Sort != sort.String() {
+		return nil, errors.New("Cursor does not match the sort parameter")
+	}
+	if len(cursor.Values) != len(sort)-1 {
+		return nil, errInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// keyset возвращает позицию в списке песен, на которую указывает курсор.
+func (c *songCursor) keyset() *repository.SongKeyset {
+	return &repository.SongKeyset{Values: c.Values, ID: c.ID}
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSongCursorRoundTrip(t *testing.T) {
+	song := models.Song{
+		ID:          7,
+		Group:       "Muse",
+		Song:        "Hysteria",
+		ReleaseDate: models.Date{Time: time.Date(2003, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	tests := []struct {
+		sort     string
+		backward bool
+		values   []string
+	}{
+		{sort: "", values: nil},
+		{sort: "song", values: []string{"Hysteria"}},
+		{sort: "-releaseDate,group", backward: true, values: []string{"2003-12-01", "Muse"}},
+		{sort: "group,-id", values: []string{"Muse"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			sort, err := parseSongSort(tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cursor, err := decodeSongCursor(encodeSongCursor(sort, song, tt.backward), sort)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if cursor.ID != song.ID || cursor.Backward != tt.backward || !reflect.DeepEqual(cursor.Values, tt.values) {
+				t.Errorf("cursor %+v, want id %d, backward %v, values %v", cursor, song.ID, tt.backward, tt.values)
+			}
+			keyset := cursor.keyset()
+			if keyset.ID != song.ID || !reflect.DeepEqual(keyset.Values, tt.values) {
+				t.Errorf("keyset %+v does not match the cursor", keyset)
+			}
+		})
+	}
+}
+
+func TestDecodeSongCursorErrors(t *testing.T) {
+	songSortOrder, _ := parseSongSort("song")
+	valid := encodeSongCursor(songSortOrder, models.Song{ID: 1, Song: "A"}, false)
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	tests := []struct {
+		name  string
+		token string
+		sort  string
+	}{
+		{name: "not base64", token: "%%%", sort: "song"},
+		{name: "not json", token: encode("song"), sort: "song"},
+		{name: "another sort", token: valid, sort: "-song"},
+		{name: "missing values", token: encode(`{"s":"song,id","id":1}`), sort: "song"},
+		{name: "extra values", token: encode(`{"s":"song,id","v":["A","B"],"id":1}`), sort: "song"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := parseSongSort(tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := decodeSongCursor(tt.token, sort); err == nil {
+				t.Errorf("decodeSongCursor(%q) succeeded, want an error", tt.token)
+			}
+		})
+	}
+}
+
+func TestSongSortReversed(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: "-id"},
+		{value: "-releaseDate,group", want: "releaseDate,-group,-id"},
+		{value: "song,-id", want: "-song,id"},
+	}
+	for _, tt := range tests {
+		sort, err := parseSongSort(tt.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := sort.reversed().String(); got != tt.want {
+			t.Errorf("parseSongSort(%q).reversed() = %s, want %s", tt.value, got, tt.want)
+		}
+		if got := sort.reversed().reversed().String(); got != sort.String() {
+			t.Errorf("reversing %s twice gives %s", sort, got)
+		}
+	}
+}
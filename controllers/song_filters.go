@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseSongFilters извлекает параметры фильтрации песен из строки запроса.
+// Ограничения по датам (releasedFrom, releasedTo, year, decade) сводятся к полуинтервалу [ReleasedFrom, ReleasedBefore).
+// Возвращаемая ошибка содержит сообщение, предназначенное для ответа клиенту.
+func parseSongFilters(c *gin.Context) (repository.SongFilter, error) {
+	filters := repository.SongFilter{
+		Group: utils.NormalizeName(c.Query("group")),
+		Song:  utils.NormalizeName(c.Query("song")),
+		Album: utils.NormalizeName(c.Query("album")),
+	}
+
+	if releaseDate := c.Query("releaseDate"); releaseDate != "" {
+		date, err := parseReleaseDate(releaseDate)
+		if err != nil {
+			return filters, err
+		}
+		filters.ReleaseDate = date
+	}
+
+	if releasedFrom := c.Query("releasedFrom"); releasedFrom != "" {
+		date, err := models.ParseDate(releasedFrom)
+		if err != nil {
+			return filters, errors.New("Invalid releasedFrom parameter. Expected format: DD.MM.YYYY")
+		}
+		narrowReleasePeriod(&filters, date, models.Date{})
+	}
+	if releasedTo := c.Query("releasedTo"); releasedTo != "" {
+		date, err := models.ParseDate(releasedTo)
+		if err != nil {
+			return filters, errors.New("Invalid releasedTo parameter. Expected format: DD.MM.YYYY")
+		}
+		narrowReleasePeriod(&filters, models.Date{}, models.Date{Time: date.AddDate(0, 0, 1)})
+	}
+	if year := c.Query("year"); year != "" {
+		yearInt, err := strconv.Atoi(year)
+		if err != nil || yearInt < 1 || yearInt > 9999 {
+			return filters, errors.New("Invalid year parameter")
+		}
+		narrowReleasePeriod(&filters, models.NewDate(yearInt, time.January, 1), models.NewDate(yearInt+1, time.January, 1))
+	}
+	if decade := c.Query("decade"); decade != "" {
+		// Десятилетие задаётся первым годом: 1990 или 1990s
+		decadeInt, err := strconv.Atoi(strings.TrimSuffix(decade, "s"))
+		if err != nil || decadeInt < 10 || decadeInt > 9990 || decadeInt%10 != 0 {
+			return filters, errors.New("Invalid decade parameter. Expected format: 1990 or 1990s")
+		}
+		narrowReleasePeriod(&filters, models.NewDate(decadeInt, time.January, 1), models.NewDate(decadeInt+10, time.January, 1))
+	}
+
+	if !filters.ReleasedFrom.IsZero() && !filters.ReleasedBefore.IsZero() && !filters.ReleasedFrom.Before(filters.ReleasedBefore.Time) {
+		return filters, errors.New("Release date range is empty")
+	}
+	return filters, nil
+}
+
+// narrowReleasePeriod сужает полуинтервал дат выпуска до пересечения с [from, before).
+// Нулевая граница означает отсутствие ограничения с соответствующей стороны.
+func narrowReleasePeriod(f *repository.SongFilter, from, before models.Date) {
+	if !from.IsZero() && (f.ReleasedFrom.IsZero() || from.After(f.ReleasedFrom.Time)) {
+		f.ReleasedFrom = from
+	}
+	if !before.IsZero() && (f.ReleasedBefore.IsZero() || before.Before(f.ReleasedBefore.Time)) {
+		f.ReleasedBefore = before
+	}
+}
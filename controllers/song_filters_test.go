@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseSongFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		query      string
+		wantFrom   string
+		wantBefore string
+		wantErr    bool
+	}{
+		{query: ""},
+		{query: "releasedFrom=01.06.2003", wantFrom: "01.06.2003"},
+		{query: "releasedTo=31.12.2003", wantBefore: "01.01.2004"},
+		{query: "year=2003", wantFrom: "01.01.2003", wantBefore: "01.01.2004"},
+		{query: "decade=1990s", wantFrom: "01.01.1990", wantBefore: "01.01.2000"},
+		{query: "decade=1990&year=1997&releasedTo=30.06.1997", wantFrom: "01.01.1997", wantBefore: "01.07.1997"},
+		{query: "year=1997&releasedFrom=01.01.1990", wantFrom: "01.01.1997", wantBefore: "01.01.1998"},
+		{query: "releasedFrom=2003-06-01", wantErr: true},
+		{query: "releasedTo=31.13.2003", wantErr: true},
+		{query: "year=0", wantErr: true},
+		{query: "decade=1995", wantErr: true},
+		{query: "decade=nineties", wantErr: true},
+		{query: "year=1997&decade=2000", wantErr: true},
+		{query: "releaseDate=01.01.2999", wantErr: true},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/songs?"+tt.query, nil)
+		filters, err := parseSongFilters(c)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: parsed %+v, want an error", tt.query, filters)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.query, err)
+			continue
+		}
+		if from, before := filters.ReleasedFrom.String(), filters.ReleasedBefore.String(); from != tt.wantFrom || before != tt.wantBefore {
+			t.Errorf("%q: period [%s, %s), want [%s, %s)", tt.query, from, before, tt.wantFrom, tt.wantBefore)
+		}
+	}
+}
+
+func TestParseSongFiltersNormalizesNames(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/songs?group=%20Red%20%20Hot%20&song=Otherside%20&album=%20Californication", nil)
+	filters, err := parseSongFilters(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filters.Group != "Red Hot" || filters.Song != "Otherside" || filters.Album != "Californication" {
+		t.Errorf("filters %+v, want normalized names", filters)
+	}
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// Типы содержимого запроса на частичное обновление песни.
+const (
+	mergePatchContentType = "application/merge-patch+json" // JSON Merge Patch (RFC 7396)
+	jsonPatchContentType  = "application/json-patch+json"  // JSON Patch (RFC 6902)
+)
+
+// errSongModified сообщает, что версия песни в теле запроса не совпадает с текущей.
+var errSongModified = errors.New("Song has been modified by another request")
+
+// errUnsupportedPatch сообщает о неподдерживаемом типе содержимого запроса на изменение.
+var errUnsupportedPatch = errors.New("Unsupported content type. Use application/json, application/merge-patch+json or application/json-patch+json")
+
+// songMergePatch приводит тело запроса PATCH к JSON Merge Patch. Тело в формате JSON Patch применяется
+// к текущему представлению песни, и изменённые поля превращаются в эквивалентный Merge Patch,
+// поэтому оба формата проверяются одинаково.
+func songMergePatch(contentType string, body []byte, song *models.Song) (map[string]json.RawMessage, error) {
+	switch contentType {
+	case "", "application/json", mergePatchContentType:
+		var patch map[string]json.RawMessage
+		if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+			return nil, errors.New("Merge patch must be a JSON object")
+		}
+		return patch, nil
+	case jsonPatchContentType:
+		data, err := json.Marshal(song)
+		if err != nil {
+			return nil, err
+		}
+		var doc map[string]json.RawMessage
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		patched, err := utils.ApplyJSONPatch(doc, body)
+		if err != nil {
+			return nil, err
+		}
+		return utils.MergePatchFromDiff(doc, patched), nil
+	default:
+		return nil, errUnsupportedPatch
+	}
+}
+
+// songPatchFromMerge проверяет каждое поле Merge Patch и переводит его в изменения песни.
+// Значение null очищает необязательные поля (releaseDate, text, link); group, groupId и song
+// очистить нельзя. Поля, значение которых совпадает с текущим, пропускаются, поэтому клиент может
+// отправить песню целиком. Ошибки возвращаются по полям. Поле version только для чтения: если оно
+// не совпадает с текущей версией, возвращается errSongModified. Поля enrichmentStatus и enrichmentSources
+// тоже только для чтения и могут совпадать лишь с текущими значениями.
+func songPatchFromMerge(merge map[string]json.RawMessage, song *models.Song) (models.SongPatch, map[string]string, error) {
+	var patch models.SongPatch
+	fieldErrors := make(map[string]string)
+
+	for field, raw := range merge {
+		isNull := string(raw) == "null"
+		switch field {
+		case "id":
+			var id uint
+			if isNull || json.Unmarshal(raw, &id) != nil || id != song.ID {
+				fieldErrors[field] = "Changing the song ID is not allowed"
+			}
+		case "version":
+			var version uint
+			if isNull || json.Unmarshal(raw, &version) != nil {
+				fieldErrors[field] = "Version must be a positive integer"
+			} else if version != song.Version {
+				return patch, nil, errSongModified
+			}
+		case "enrichmentStatus":
+			var status string
+			if isNull || json.Unmarshal(raw, &status) != nil || status != song.EnrichmentStatus {
+				fieldErrors[field] = "Enrichment status is set by the server"
+			}
+		case "enrichmentSources":
+			var sources models.SongSources
+			if json.Unmarshal(raw, &sources) != nil || !maps.Equal(sources, song.EnrichmentSources) {
+				fieldErrors[field] = "Enrichment sources are set by the server"
+			}
+		case "groupId":
+			var groupID uint
+			switch {
+			case isNull:
+				fieldErrors[field] = "Group cannot be cleared"
+			case json.Unmarshal(raw, &groupID) != nil || groupID == 0:
+				fieldErrors[field] = "Group ID must be a positive integer"
+			case groupID != song.GroupID:
+				patch.GroupID = &groupID
+			}
+		case "group":
+			name, err := patchString(raw, isNull, "Group")
+			if err != nil {
+				fieldErrors[field] = err.Error()
+			} else if name != song.Group {
+				patch.Group = &name
+			}
+		case "song":
+			title, err := patchString(raw, isNull, "Song title")
+			if err != nil {
+				fieldErrors[field] = err.Error()
+			} else if title = utils.NormalizeName(title); title != song.Song {
+				patch.Song = &title
+			}
+		case "releaseDate":
+			var value string
+			if !isNull && json.Unmarshal(raw, &value) != nil {
+				fieldErrors[field] = models.ErrInvalidDate.Error()
+				continue
+			}
+			var date models.Date
+			if value != "" {
+				parsed, err := parseReleaseDate(value)
+				if err != nil {
+					fieldErrors[field] = err.Error()
+					continue
+				}
+				date = parsed
+			}
+			patch.ReleaseDate = &date
+		case "text":
+			var text string
+			if !isNull && json.Unmarshal(raw, &text) != nil {
+				fieldErrors[field] = "Text must be a string"
+				continue
+			}
+			patch.Text = &text
+		case "link":
+			var link string
+			if !isNull && json.Unmarshal(raw, &link) != nil {
+				fieldErrors[field] = "Link must be a string"
+				continue
+			}
+			if link != "" && !utils.IsHTTPURL(link) {
+				fieldErrors[field] = "Link must be an absolute http or https URL"
+				continue
+			}
+			patch.Link = &link
+		default:
+			fieldErrors[field] = "Unknown field"
+		}
+	}
+
+	if patch.Group != nil && patch.GroupID != nil {
+		fieldErrors["group"] = "Specify either group or groupId"
+	}
+	return patch, fieldErrors, nil
+}
+
+// patchString разбирает обязательное строковое поле Merge Patch: null и пустая строка не допускаются.
+func patchString(raw json.RawMessage, isNull bool, name string) (string, error) {
+	if isNull {
+		return "", fmt.Errorf("%s cannot be cleared", name)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("%s must be a string", name)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%s cannot be empty", name)
+	}
+	return value, nil
+}
+
+// replacementOptionalFields — необязательные поля песни, которые при полной замене очищаются, если их нет в запросе.
+var replacementOptionalFields = []string{"releaseDate", "text", "link"}
+
+// songReplacementMerge приводит полное представление песни из запроса PUT к Merge Patch:
+// необязательные поля, отсутствующие в запросе, очищаются. Название песни и группа (group или groupId)
+// обязательны; их отсутствие возвращается ошибками по полям.
+func songReplacementMerge(body []byte) (map[string]json.RawMessage, map[string]string, error) {
+	var merge map[string]json.RawMessage
+	if err := json.Unmarshal(body, &merge); err != nil || merge == nil {
+		return nil, nil, errors.New("Song must be a JSON object")
+	}
+
+	fieldErrors := make(map[string]string)
+	if _, ok := merge["song"]; !ok {
+		fieldErrors["song"] = "Song title is required"
+	}
+	_, hasGroup := merge["group"]
+	_, hasGroupID := merge["groupId"]
+	if !hasGroup && !hasGroupID {
+		fieldErrors["group"] = "Group or groupId is required"
+	}
+	for _, field := range replacementOptionalFields {
+		if _, ok := merge[field]; !ok {
+			merge[field] = json.RawMessage("null")
+		}
+	}
+	return merge, fieldErrors, nil
+}
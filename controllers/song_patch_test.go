@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// patchTestSong возвращает песню, к которой применяются изменения в тестах.
+func patchTestSong() *models.Song {
+	return &models.Song{
+		ID:                1,
+		GroupID:           3,
+		Group:             "Muse",
+		Song:              "Hysteria",
+		Text:              "verse",
+		Link:              "https://example.com/hysteria",
+		Version:           2,
+		EnrichmentStatus:  models.EnrichmentEnriched,
+		EnrichmentSources: models.SongSources{"text": "lyrics"},
+	}
+}
+
+// patchedFields перечисляет изменённые поля SongPatch в порядке имён.
+func patchedFields(patch models.SongPatch) []string {
+	var fields []string
+	value := reflect.ValueOf(patch)
+	for i := 0; i < value.NumField(); i++ {
+		if !value.Field(i).IsNil() {
+			fields = append(fields, value.Type().Field(i).Name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// errorFields перечисляет поля с ошибками в порядке имён.
+func errorFields(fieldErrors map[string]string) []string {
+	var fields []string
+	for field := range fieldErrors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// errAnyPatch отмечает в таблице тестов, что подходит любая ошибка.
+var errAnyPatch = errors.New("any error")
+
+func TestSongMergePatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+		wantErr     error
+	}{
+		{name: "json", contentType: "application/json", body: `{"text":"new"}`, want: `{"text":"new"}`},
+		{name: "merge patch", contentType: mergePatchContentType, body: `{"link":null}`, want: `{"link":null}`},
+		{name: "json patch", contentType: jsonPatchContentType, body: `[{"op":"replace","path":"/song","value":"Uprising"},{"op":"remove","path":"/link"}]`, want: `{"song":"Uprising","link":null}`},
+		{name: "json patch without changes", contentType: jsonPatchContentType, body: `[{"op":"test","path":"/version","value":2}]`, want: `{}`},
+		{name: "merge patch not an object", contentType: mergePatchContentType, body: `[]`, wantErr: errAnyPatch},
+		{name: "json patch test failed", contentType: jsonPatchContentType, body: `[{"op":"test","path":"/song","value":"Uprising"}]`, wantErr: errAnyPatch},
+		{name: "unsupported content type", contentType: "text/plain", body: `text`, wantErr: errUnsupportedPatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merge, err := songMergePatch(tt.contentType, []byte(tt.body), patchTestSong())
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("songMergePatch succeeded with %v, want an error", merge)
+				}
+				if tt.wantErr != errAnyPatch && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := json.Marshal(merge)
+			expected, _ := json.Marshal(want)
+			if string(got) != string(expected) {
+				t.Errorf("merge patch %s, want %s", got, expected)
+			}
+		})
+	}
+}
+
+func TestSongPatchFromMerge(t *testing.T) {
+	tests := []struct {
+		name       string
+		merge      string
+		wantFields []string
+		wantErrors []string
+		wantErr    error
+	}{
+		{name: "read-only and unchanged fields", merge: `{"id":1,"groupId":3,"group":"Muse","song":"Hysteria","version":2,"enrichmentStatus":"enriched","enrichmentSources":{"text":"lyrics"}}`},
+		{name: "rename", merge: `{"song":"  Uprising "}`, wantFields: []string{"Song"}},
+		{name: "clear optional fields", merge: `{"releaseDate":null,"text":null,"link":null}`, wantFields: []string{"Link", "ReleaseDate", "Text"}},
+		{name: "release date", merge: `{"releaseDate":"01.12.2003"}`, wantFields: []string{"ReleaseDate"}},
+		{name: "move to group", merge: `{"groupId":4}`, wantFields: []string{"GroupID"}},
+		{name: "stale version", merge: `{"version":1,"text":"new"}`, wantErr: errSongModified},
+		{name: "invalid version", merge: `{"version":"2"}`, wantErrors: []string{"version"}},
+		{name: "change id", merge: `{"id":2}`, wantErrors: []string{"id"}},
+		{name: "change enrichment sources", merge: `{"enrichmentSources":{"text":"video"}}`, wantErrors: []string{"enrichmentSources"}},
+		{name: "clear enrichment sources", merge: `{"enrichmentSources":null}`, wantErrors: []string{"enrichmentSources"}},
+		{name: "change enrichment status", merge: `{"enrichmentStatus":"pending"}`, wantErrors: []string{"enrichmentStatus"}},
+		{name: "clear required fields", merge: `{"group":null,"song":"","groupId":null}`, wantErrors: []string{"group", "groupId", "song"}},
+		{name: "group and group id", merge: `{"group":"Queen","groupId":4}`, wantFields: []string{"Group", "GroupID"}, wantErrors: []string{"group"}},
+		{name: "future release date", merge: `{"releaseDate":"01.01.2999"}`, wantErrors: []string{"releaseDate"}},
+		{name: "invalid types", merge: `{"text":1,"link":false,"releaseDate":3}`, wantErrors: []string{"link", "releaseDate", "text"}},
+		{name: "relative link", merge: `{"link":"/hysteria"}`, wantErrors: []string{"link"}},
+		{name: "unknown field", merge: `{"lyrics":"verse"}`, wantErrors: []string{"lyrics"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var merge map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.merge), &merge); err != nil {
+				t.Fatal(err)
+			}
+			patch, fieldErrors, err := songPatchFromMerge(merge, patchTestSong())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := errorFields(fieldErrors); !reflect.DeepEqual(got, tt.wantErrors) {
+				t.Errorf("field errors %v, want errors for %v", fieldErrors, tt.wantErrors)
+			}
+			if got := patchedFields(patch); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("patched fields %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestSongReplacementMerge(t *testing.T) {
+	merge, fieldErrors, err := songReplacementMerge([]byte(`{"group":"Muse","song":"Hysteria","text":"verse"}`))
+	if err != nil || len(fieldErrors) != 0 {
+		t.Fatalf("error %v, field errors %v", err, fieldErrors)
+	}
+	if string(merge["text"]) != `"verse"` || string(merge["link"]) != "null" || string(merge["releaseDate"]) != "null" {
+		t.Errorf("replacement merge %v should keep text and clear link and releaseDate", merge)
+	}
+
+	_, fieldErrors, err = songReplacementMerge([]byte(`{"text":"verse"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := errorFields(fieldErrors); !reflect.DeepEqual(got, []string{"group", "song"}) {
+		t.Errorf("field errors %v, want errors for group and song", fieldErrors)
+	}
+
+	if _, _, err := songReplacementMerge([]byte(`null`)); err == nil {
+		t.Error("songReplacementMerge(null) succeeded, want an error")
+	}
+}
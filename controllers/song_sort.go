@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"MusicLibrary/repository"
+	"fmt"
+	"strings"
+)
+
+// songSort описывает порядок сортировки песен. Последним полем всегда идёт id,
+// что делает порядок детерминированным и стабильным между страницами.
+type songSort []repository.SortField
+
+// parseSongSort разбирает параметр sort: список полей через запятую, префикс "-" означает
+// сортировку по убыванию, префикс "+" или его отсутствие — по возрастанию.
+// Возвращаемое сообщение об ошибке предназначено для ответа клиенту.
+func parseSongSort(value string) (songSort, error) {
+	var sort songSort
+	seen := make(map[string]bool)
+
+	if strings.TrimSpace(value) != "" {
+		for _, part := range strings.Split(value, ",") {
+			field := repository.SortField{Name: strings.TrimSpace(part)}
+			if strings.HasPrefix(field.Name, "-") {
+				field.Desc = true
+				field.Name = field.Name[1:]
+			} else {
+				field.Name = strings.TrimPrefix(field.Name, "+")
+			}
+
+			if !isSongSortField(field.Name) {
+				return nil, fmt.Errorf("Invalid sort field: %q", part)
+			}
+			if seen[field.Name] {
+				return nil, fmt.Errorf("Duplicate sort field: %q", field.Name)
+			}
+			seen[field.Name] = true
+			sort = append(sort, field)
+		}
+	}
+
+	// Добавляем id в качестве последнего поля для детерминированного порядка
+	if !seen["id"] {
+		sort = append(sort, repository.SortField{Name: "id"})
+	}
+	return sort, nil
+}
+
+// isSongSortField проверяет, допустимо ли поле в параметре sort.
+func isSongSortField(name string) bool {
+	for _, field := range repository.SongSortFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// String возвращает порядок сортировки в формате параметра sort:
+// поля через запятую, с префиксом "-" для сортировки по убыванию.
+func (s songSort) String() string {
+	fields := make([]string, len(s))
+	for i, field := range s {
+		if field.Desc {
+			fields[i] = "-" + field.Name
+		} else {
+			fields[i] = field.Name
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// reversed возвращает обратный порядок сортировки. Используется для выборки страницы перед курсором.
+func (s songSort) reversed() songSort {
+	reversed := make(songSort, len(s))
+	for i, field := range s {
+		reversed[i] = repository.SortField{Name: field.Name, Desc: !field.Desc}
+	}
+	return reversed
+}
@@ -0,0 +1,37 @@
+package controllers
+
+import "testing"
+
+func TestParseSongSort(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: "id"},
+		{value: "  ", want: "id"},
+		{value: "-releaseDate, group", want: "-releaseDate,group,id"},
+		{value: "+song,-id", want: "song,-id"},
+		{value: "id,song", want: "id,song"},
+		{value: "text", wantErr: true},
+		{value: "song,-song", wantErr: true},
+		{value: "song,", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			sort, err := parseSongSort(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseSongSort(%q) = %s, want an error", tt.value, sort)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sort.String() != tt.want {
+				t.Errorf("parseSongSort(%q) = %s, want %s", tt.value, sort, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// suggestTimeout ограничивает время выполнения запроса автодополнения,
+	// так как эндпоинт вызывается на каждое нажатие клавиши.
+	suggestTimeout = 300 * time.Millisecond
+
+	// maxSuggestLimit ограничивает количество вариантов автодополнения.
+	maxSuggestLimit = 20
+)
+
+// Suggest возвращает варианты автодополнения для названий групп или песен.
+// @Summary Автодополнение названий групп и песен
+// @Description Возвращает до limit различных названий групп или песен, начинающихся с prefix. Сначала идут названия, начинающиеся с prefix целиком, затем названия, в которых с prefix начинается одно из слов; внутри каждой категории — более популярные (с большим количеством песен в библиотеке).
+// @Tags suggest
+// @Produce json
+// @Param prefix query string true "Начало названия"
+// @Param type query string false "Что дополнять: group или song" Enums(group, song) default(song)
+// @Param limit query int false "Количество вариантов, не более 20" default(10)
+// @Success 200 {object} models.ResponseSuggest "Варианты автодополнения"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 503 {object} models.ErrorResponse "Запрос не уложился в отведённое время"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /suggest [get]
+func Suggest(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		prefix := utils.NormalizeName(c.Query("prefix"))
+		if prefix == "" {
+			logger.Warn("Empty suggest prefix")
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Prefix is required"})
+			return
+		}
+
+		suggestType := c.DefaultQuery("type", "song")
+		if !slices.Contains(repository.AutocompleteFields, suggestType) {
+			logger.Warnf("Invalid suggest type: %s", suggestType)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid type parameter. Expected group or song"})
+			return
+		}
+
+		limit := c.DefaultQuery("limit", "10")
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt < 1 || limitInt > maxSuggestLimit {
+			logger.Warnf("Invalid limit parameter: %s", limit)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid limit parameter. Expected a number from 1 to %d", maxSuggestLimit)})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), suggestTimeout)
+		defer cancel()
+
+		suggestions, err := songs.Autocomplete(ctx, suggestType, prefix, limitInt)
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			logger.Warnf("Suggest query for prefix %q exceeded %s", prefix, suggestTimeout)
+			c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{Error: "Suggestions are temporarily unavailable"})
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to retrieve suggestions for prefix %q: %v", prefix, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve suggestions"})
+			return
+		}
+
+		// Подсказки меняются редко, поэтому разрешаем клиентам и прокси кэшировать ответ
+		c.Header("Cache-Control", "public, max-age=60")
+		c.JSON(http.StatusOK, models.ResponseSuggest{
+			Prefix:      prefix,
+			Type:        suggestType,
+			Suggestions: suggestions,
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"MusicLibrary/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestSuggestInvalidQuery проверяет, что некорректные параметры автодополнения отклоняются до обращения к базе данных.
+func TestSuggestInvalidQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/suggest", Suggest(newTestLogger(), repository.NewMemorySongRepository()))
+
+	for _, target := range []string{
+		"/suggest",
+		"/suggest?prefix=%20",
+		"/suggest?prefix=mu&type=album",
+		"/suggest?prefix=mu&limit=0",
+		"/suggest?prefix=mu&limit=21",
+		"/suggest?prefix=mu&limit=ten",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: status %d, want %d", target, w.Code, http.StatusBadRequest)
+		}
+	}
+}
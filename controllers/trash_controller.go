@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GetTrash возвращает песни в корзине.
+// @Summary Получение корзины
+// @Description Возвращает удалённые песни, начиная с удалённых последними. Песни из корзины можно восстановить или удалить окончательно; по истечении срока хранения они удаляются автоматически.
+// @Tags songs
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param limit query int false "Количество песен на странице, не более 100" default(5)
+// @Success 200 {object} models.ResponseTrash "Песни в корзине"
+// @Failure 400 {object} models.ErrorResponse "Ошибка запроса"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/trash [get]
+func GetTrash(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page := c.DefaultQuery("page", "1")
+		limit := c.DefaultQuery("limit", "5")
+
+		// Конвертация параметров пагинации в числа
+		pageInt, err := strconv.Atoi(page)
+		if err != nil || pageInt < 1 {
+			logger.Warnf("Invalid page parameter: %s", page)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid page parameter"})
+			return
+		}
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil || limitInt < 1 || limitInt > maxPageLimit {
+			logger.Warnf("Invalid limit parameter: %s", limit)
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid limit parameter. Expected a number from 1 to %d", maxPageLimit)})
+			return
+		}
+
+		trashed, total, err := songs.ListDeleted(c.Request.Context(), (pageInt-1)*limitInt, limitInt)
+		if err != nil {
+			logger.Errorf("Failed to retrieve deleted songs: %v", err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to retrieve deleted songs"})
+			return
+		}
+
+		result := make([]models.TrashedSong, 0, len(trashed))
+		for _, song := range trashed {
+			result = append(result, models.TrashedSong{Song: song, DeletedAt: song.DeletedAt.Time})
+		}
+
+		logger.Infof("Retrieved %d deleted songs", len(result))
+		c.JSON(http.StatusOK, models.ResponseTrash{
+			Total: total,
+			Page:  pageInt,
+			Limit: limitInt,
+			Songs: result,
+		})
+	}
+}
+
+// RestoreSong возвращает песню из корзины.
+// @Summary Восстановление песни
+// @Description Возвращает удалённую песню из корзины в библиотеку. Если за это время у группы появилась песня с таким же названием, восстановление невозможно.
+// @Tags songs
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param X-Actor header string false "Автор изменения для истории версий"
+// @Success 200 {object} models.Song "Восстановленная песня. Заголовок ETag содержит её новую версию"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID песни"
+// @Failure 404 {object} models.ErrorResponse "Песни нет в корзине"
+// @Failure 409 {object} models.ErrorResponse "У группы уже есть песня с таким названием"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/restore [post]
+func RestoreSong(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid song ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid song ID"})
+			return
+		}
+
+		song, err := songs.Restore(c.Request.Context(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrNotFound):
+				logger.Warnf("Song not found in trash with ID: %d", id)
+				c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Song not found in trash"})
+			case errors.Is(err, repository.ErrDuplicate):
+				logger.Warnf("Restoring song ID: %d would duplicate another song of the group", id)
+				c.JSON(http.StatusConflict, models.ErrorResponse{Error: "Song already exists in the library"})
+			default:
+				logger.Errorf("Failed to restore song ID: %d, error: %v", id, err)
+				c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to restore the song"})
+			}
+			return
+		}
+
+		logger.Infof("Restored song: %s by %s with ID: %d", song.Song, song.Group, id)
+		c.Header("ETag", songETag(song))
+		c.JSON(http.StatusOK, song)
+	}
+}
+
+// PurgeSong окончательно удаляет песню из корзины.
+// @Summary Окончательное удаление песни
+// @Description Удаляет песню из корзины без возможности восстановления вместе с её позициями в треклистах альбомов. Удалить так можно только песню, которая уже находится в корзине.
+// @Tags songs
+// @Produce json
+// @Param id path int true "ID песни"
+// @Success 200 {object} models.SuccessResponse "Песня удалена окончательно"
+// @Failure 400 {object} models.ErrorResponse "Некорректный ID песни"
+// @Failure 404 {object} models.ErrorResponse "Песни нет в корзине"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/trash/{id} [delete]
+func PurgeSong(logger *logrus.Logger, songs repository.SongRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := parseIDParam(c, "id")
+		if err != nil {
+			logger.Warnf("Invalid song ID: %s", c.Param("id"))
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid song ID"})
+			return
+		}
+
+		if err := songs.Purge(c.Request.Context(), id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				logger.Warnf("Song not found in trash with ID: %d", id)
+				c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Song not found in trash"})
+				return
+			}
+			logger.Errorf("Failed to purge song ID: %d, error: %v", id, err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to purge the song"})
+			return
+		}
+
+		logger.Infof("Purged song with ID: %d", id)
+		c.JSON(http.StatusOK, models.SuccessResponse{Message: "Song purged successfully"})
+	}
+}
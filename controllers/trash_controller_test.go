@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestTrashHandlers(t *testing.T) {
+	songs := repository.NewMemorySongRepository()
+	seedSongs(t, songs, "Muse", "Hysteria", "Starlight")
+	router := newSongTestRouter(songs)
+
+	steps := []struct {
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{method: http.MethodDelete, target: "/songs/1", want: http.StatusOK},
+		{method: http.MethodGet, target: "/songs/1", want: http.StatusNotFound},
+		{method: http.MethodGet, target: "/songs/trash?limit=0", want: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/songs/trash?page=abc", want: http.StatusBadRequest},
+		{method: http.MethodPost, target: "/songs/2/restore", want: http.StatusNotFound},
+		{method: http.MethodPost, target: "/songs/abc/restore", want: http.StatusBadRequest},
+		{method: http.MethodDelete, target: "/songs/trash/2", want: http.StatusNotFound},
+		{method: http.MethodPatch, target: "/songs/2", body: `{"song":"hysteria"}`, want: http.StatusOK},
+		{method: http.MethodPost, target: "/songs/1/restore", want: http.StatusConflict},
+		{method: http.MethodPatch, target: "/songs/2", body: `{"song":"Starlight"}`, want: http.StatusOK},
+		{method: http.MethodPost, target: "/songs/1/restore", want: http.StatusOK},
+		{method: http.MethodGet, target: "/songs/1", want: http.StatusOK},
+		{method: http.MethodDelete, target: "/songs/2", want: http.StatusOK},
+		{method: http.MethodDelete, target: "/songs/trash/2", want: http.StatusOK},
+		{method: http.MethodPost, target: "/songs/2/restore", want: http.StatusNotFound},
+	}
+	for _, step := range steps {
+		w := serve(router, step.method, step.target, step.body, nil)
+		if w.Code != step.want {
+			t.Fatalf("%s %s: status %d, want %d, body %s", step.method, step.target, w.Code, step.want, w.Body)
+		}
+	}
+
+	if err := songs.Delete(context.Background(), 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	w := serve(router, http.MethodGet, "/songs/trash", "", nil)
+	var trash models.ResponseTrash
+	if err := json.Unmarshal(w.Body.Bytes(), &trash); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || trash.Total != 1 || len(trash.Songs) != 1 || trash.Songs[0].ID != 1 || trash.Songs[0].DeletedAt.IsZero() {
+		t.Errorf("trash: status %d, %+v, want song 1 with the deletion time", w.Code, trash)
+	}
+}
@@ -1,38 +1,81 @@
 /*
-Package database предоставляет функциональность для инициализации подключения к базе данных PostgreSQL.
-Он использует GORM для работы с базой данных и управляет миграцией моделей, загружая параметры конфигурации из файла .env.
-Этот пакет обеспечивает глобальный доступ к подключению к базе данных через переменную DB.
+Package database предоставляет функциональность для инициализации подключения к базе данных PostgreSQL или SQLite.
+Он использует GORM для работы с базой данных, загружая параметры конфигурации из файла .env, и применяет
+версионированные SQL-миграции схемы из каталога migrations, встроенного в исполняемый файл.
+Init возвращает подключение, поверх которого создаются хранилища из пакета repository; глобального
+подключения пакет не хранит. Пакет также содержит вспомогательные функции для поиска и создания групп
+по нормализованному названию.
 */
 
 package database
 
 import (
-	"MusicLibrary/models"
 	"fmt"
 	"os"
 
 	// Библиотека для работы с файлами .env
+	"github.com/glebarez/sqlite" // Драйвер для SQLite на чистом Go
 	"github.com/sirupsen/logrus" // Логирование
 	"gorm.io/driver/postgres"    // Драйвер для PostgreSQL
 	"gorm.io/gorm"               // GORM — ORM-библиотека для Go
 )
 
-// DB является глобальной переменной для хранения подключения к базе данных
-var DB *gorm.DB
-
-// Init инициализирует подключение к базе данных PostgreSQL и выполняет миграцию моделей.
+// Init подключается к базе данных, применяет недостающие миграции схемы и возвращает подключение.
+// Автоматическое применение миграций при запуске отключается переменной окружения DB_AUTO_MIGRATE=false,
+// тогда схему обновляют командой migrate up.
 // @Summary Инициализация базы данных
-// @Description Устанавливает соединение с PostgreSQL и загружает параметры из .env файла.
+// @Description Устанавливает соединение с PostgreSQL или SQLite и загружает параметры из .env файла.
 // @Tags database
-func Init(logger *logrus.Logger) {
+func Init(logger *logrus.Logger) *gorm.DB {
+	db := Connect(logger)
+
+	if os.Getenv("DB_AUTO_MIGRATE") != "false" {
+		if err := MigrateUp(db, logger); err != nil {
+			logger.Fatalf("Error during database migration: %v", err)
+		}
+	}
+
+	return db
+}
+
+// Connect открывает подключение к базе данных без миграции схемы.
+// СУБД выбирается переменной окружения DB_DRIVER: postgres (по умолчанию) или sqlite.
+func Connect(logger *logrus.Logger) *gorm.DB {
+	driver := os.Getenv("DB_DRIVER")
+	if driver == "" {
+		driver = DriverPostgres
+	}
+
+	var db *gorm.DB
+	var err error
+	switch driver {
+	case DriverPostgres:
+		db, err = openPostgres(logger)
+	case DriverSQLite:
+		db, err = openSQLite()
+	default:
+		logger.Fatalf("Unsupported DB_DRIVER %q. Expected %s or %s", driver, DriverPostgres, DriverSQLite)
+	}
+	if err != nil {
+		logger.Fatalf("Could not connect to the database: %v", err)
+	}
+
+	logger.Infof("Database connection established successfully (driver: %s)", driver)
+	return db
+}
+
+// openPostgres открывает подключение к PostgreSQL с параметрами из переменных окружения DB_HOST, DB_PORT,
+// DB_USER, DB_NAME и DB_PASSWORD.
+func openPostgres(logger *logrus.Logger) (*gorm.DB, error) {
 	// Формируем строку подключения к базе данных
 	dbURI := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=disable",
 		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_NAME"), os.Getenv("DB_PASSWORD"))
 
 	// Открываем подключение к базе данных
-	db, err := gorm.Open(postgres.Open(dbURI), &gorm.Config{})
+	// TranslateError переводит ошибки драйвера, например нарушение уникального индекса, в ошибки GORM
+	db, err := gorm.Open(postgres.Open(dbURI), &gorm.Config{TranslateError: true})
 	if err != nil {
-		logger.Fatalf("Could not connect to the database: %v", err)
+		return nil, err
 	}
 
 	// Включаем стандартное экранирование строк в PostgreSQL
@@ -41,15 +84,34 @@ func Init(logger *logrus.Logger) {
 	} else {
 		logger.Infof("Successfully set standard_conforming_strings to on")
 	}
+	return db, nil
+}
 
-	// Проводим автоматическую миграцию модели Song
-	if err := db.AutoMigrate(&models.Song{}); err != nil {
-		logger.Fatalf("Error during database migration: %v", err)
-	} else {
-		logger.Infof("Database migration completed successfully")
+// openSQLite открывает файл базы данных SQLite, путь к которому задаёт переменная окружения DB_PATH
+// (по умолчанию musiclibrary.db). Драйвер написан на чистом Go и не требует cgo.
+func openSQLite() (*gorm.DB, error) {
+	if err := registerSQLiteFunctions(); err != nil {
+		return nil, err
+	}
+
+	path := os.Getenv("DB_PATH")
+	if path == "" {
+		path = "musiclibrary.db"
+	}
+
+	// Внешние ключи в SQLite по умолчанию не проверяются; журнал WAL и ожидание блокировки
+	// позволяют читать базу во время записи
+	dsn := path + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, err
 	}
 
-	// Сохраняем подключение к базе данных в глобальную переменную DB
-	DB = db
-	logger.Infof("Database connection established successfully")
+	// SQLite допускает только одну пишущую транзакцию, поэтому все запросы идут через одно соединение
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return db, nil
 }
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"MusicLibrary/models"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// migrateReleaseDates переводит столбец releaseDate таблицы из текстового формата DD.MM.YYYY в тип date.
+// Нужна базам, созданным до перехода на тип date: PostgreSQL не умеет неявно приводить текст в формате DD.MM.YYYY к дате.
+// Значения, которые не удаётся разобрать, заменяются на NULL и записываются в лог.
+func migrateReleaseDates(db *gorm.DB, logger *logrus.Logger, table string) error {
+	if !db.Migrator().HasTable(table) {
+		return nil
+	}
+
+	columnTypes, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return err
+	}
+	converted := true
+	for _, columnType := range columnTypes {
+		if columnType.Name() == "releaseDate" && !strings.EqualFold(columnType.DatabaseTypeName(), "date") {
+			converted = false
+		}
+	}
+	if converted {
+		return nil
+	}
+
+	logger.Infof("Converting column releaseDate of table %s to date", table)
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN "releaseDateConverted" date`, table)).Error; err != nil {
+			return err
+		}
+
+		var rows []struct {
+			ID          uint
+			ReleaseDate string `gorm:"column:releaseDate"`
+		}
+		if err := tx.Table(table).Select(`id, "releaseDate"`).Where(`"releaseDate" <> ''`).Find(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			date, err := models.ParseDate(strings.TrimSpace(row.ReleaseDate))
+			if err != nil {
+				logger.Warnf("Cannot convert release date %q of %s ID: %d, the value is cleared", row.ReleaseDate, table, row.ID)
+				continue
+			}
+			if err := tx.Table(table).Where("id = ?", row.ID).Update("releaseDateConverted", date).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s DROP COLUMN "releaseDate"`, table)).Error; err != nil {
+			return err
+		}
+		return tx.Exec(fmt.Sprintf(`ALTER TABLE %s RENAME COLUMN "releaseDateConverted" TO "releaseDate"`, table)).Error
+	})
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"database/sql/driver"
+	"strings"
+	"sync"
+
+	sqlite "github.com/glebarez/go-sqlite"
+	"gorm.io/gorm"
+)
+
+// Поддерживаемые значения переменной окружения DB_DRIVER.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// sqliteLowerFunction — имя функции SQLite для перевода строки в нижний регистр с учетом Unicode.
+// Встроенные в SQLite lower() и LIKE различают регистр всех букв, кроме латинских, поэтому "кино" не совпало бы с "Кино".
+const sqliteLowerFunction = "unicode_lower"
+
+// registerSQLiteFunctions регистрирует пользовательские функции SQLite. Функции доступны
+// во всех подключениях, открытых после регистрации, поэтому она выполняется до открытия базы данных.
+var registerSQLiteFunctions = sync.OnceValue(func() error {
+	return sqlite.RegisterDeterministicScalarFunction(sqliteLowerFunction, 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch value := args[0].(type) {
+		case string:
+			return strings.ToLower(value), nil
+		case []byte:
+			return strings.ToLower(string(value)), nil
+		}
+		return args[0], nil
+	})
+})
+
+// IsSQLite проверяет, работает ли подключение с SQLite.
+func IsSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == DriverSQLite
+}
+
+// SyncIDSequence продвигает последовательность столбца id таблицы table до максимального значения id.
+// Нужна после вставки строки с явно заданным id: PostgreSQL не сдвигает последовательность сам,
+// и следующая вставка без id получила бы занятое значение. В SQLite счётчик AUTOINCREMENT
+// обновляется автоматически.
+func SyncIDSequence(db *gorm.DB, table string) error {
+	if IsSQLite(db) {
+		return nil
+	}
+	return db.Exec("SELECT setval(pg_get_serial_sequence(?, 'id'), (SELECT MAX(id) FROM "+table+"))", table).Error
+}
+
+// Lower возвращает выражение SQL, переводящее expr в нижний регистр с учетом букв любого алфавита.
+func Lower(db *gorm.DB, expr string) string {
+	if IsSQLite(db) {
+		return sqliteLowerFunction + "(" + expr + ")"
+	}
+	return "lower(" + expr + ")"
+}
+
+// LikeEscape возвращает предложение ESCAPE для шаблонов LIKE, экранированных utils.EscapeLike.
+// В PostgreSQL обратная косая черта экранирует символы шаблона и по умолчанию, а в SQLite — только
+// с этим предложением; оно указывается в обоих диалектах, чтобы шаблон не зависел от настроек сервера.
+func LikeEscape(db *gorm.DB) string {
+	return ` ESCAPE '\'`
+}
+
+// ILike возвращает условие регистронезависимого сравнения column с шаблоном LIKE, переданным параметром
+// и экранированным utils.EscapeLike. В PostgreSQL используется ILIKE, в SQLite — сравнение в нижнем регистре.
+func ILike(db *gorm.DB, column string) string {
+	if IsSQLite(db) {
+		return Lower(db, column) + " LIKE " + Lower(db, "?") + LikeEscape(db)
+	}
+	return column + " ILIKE ?" + LikeEscape(db)
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestDialectExpressions(t *testing.T) {
+	open := func(dialector gorm.Dialector) *gorm.DB {
+		t.Helper()
+		db, err := gorm.Open(dialector, &gorm.Config{DisableAutomaticPing: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+	tests := []struct {
+		name   string
+		db     *gorm.DB
+		sqlite bool
+		lower  string
+		ilike  string
+	}{
+		{name: "postgres", db: open(postgres.Open("host=127.0.0.1 port=1")), lower: "lower(name)", ilike: `name ILIKE ? ESCAPE '\'`},
+		{name: "sqlite", db: open(sqlite.Open(":memory:")), sqlite: true, lower: "unicode_lower(name)", ilike: `unicode_lower(name) LIKE unicode_lower(?) ESCAPE '\'`},
+	}
+	for _, tt := range tests {
+		if got := IsSQLite(tt.db); got != tt.sqlite {
+			t.Errorf("%s: IsSQLite = %v, want %v", tt.name, got, tt.sqlite)
+		}
+		if got := Lower(tt.db, "name"); got != tt.lower {
+			t.Errorf("%s: Lower = %q, want %q", tt.name, got, tt.lower)
+		}
+		if got := ILike(tt.db, "name"); got != tt.ilike {
+			t.Errorf("%s: ILike = %q, want %q", tt.name, got, tt.ilike)
+		}
+	}
+}
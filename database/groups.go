@@ -0,0 +1,118 @@
+package database
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/utils"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAliasTaken возвращается, если альтернативное название уже принадлежит другой группе.
+var ErrAliasTaken = errors.New("alias is already used by another group")
+
+// FindGroupByName ищет группу по каноническому названию или по одному из альтернативных
+// названий без учета регистра и лишних пробелов. Если группа не найдена, возвращается gorm.ErrRecordNotFound.
+func FindGroupByName(tx *gorm.DB, name string) (*models.Group, error) {
+	key := utils.NameKey(name)
+
+	var group models.Group
+	err := tx.Where("\"nameKey\" = ?", key).
+		Or("id IN (?)", tx.Model(&models.GroupAlias{}).Select("\"groupId\"").Where("\"aliasKey\" = ?", key)).
+		First(&group).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// FindOrCreateGroup возвращает группу с указанным названием, создавая её при отсутствии.
+// Название нормализуется, поэтому "Muse", "muse " и "MUSE" разрешаются в одну и ту же группу.
+func FindOrCreateGroup(tx *gorm.DB, name string) (*models.Group, error) {
+	group, err := FindGroupByName(tx, name)
+	if err == nil {
+		return group, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// Группа могла быть создана параллельным запросом, поэтому конфликт по ключу не считается ошибкой.
+	group = &models.Group{Name: utils.NormalizeName(name), NameKey: utils.NameKey(name)}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(group).Error; err != nil {
+		return nil, err
+	}
+	if group.ID == 0 {
+		return FindGroupByName(tx, name)
+	}
+	return group, nil
+}
+
+// ReplaceGroupAliases заменяет список альтернативных названий группы.
+// Названия, совпадающие с каноническим названием группы, и повторы пропускаются.
+// Если альтернативное название уже принадлежит другой группе, возвращается ErrAliasTaken.
+func ReplaceGroupAliases(tx *gorm.DB, group *models.Group, aliases []string) error {
+	if err := tx.Where("\"groupId\" = ?", group.ID).Delete(&models.GroupAlias{}).Error; err != nil {
+		return err
+	}
+
+	group.Aliases = nil
+	seen := map[string]bool{group.NameKey: true}
+	for _, alias := range aliases {
+		key := utils.NameKey(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		owner, err := FindGroupByName(tx, alias)
+		if err == nil && owner.ID != group.ID {
+			return ErrAliasTaken
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		groupAlias := models.GroupAlias{GroupID: group.ID, Alias: utils.NormalizeName(alias), AliasKey: key}
+		if err := tx.Create(&groupAlias).Error; err != nil {
+			return err
+		}
+		group.Aliases = append(group.Aliases, groupAlias)
+	}
+	return nil
+}
+
+// migrateSongGroups переносит текстовые названия групп из таблицы songs в таблицу groups
+// и проставляет песням внешний ключ groupId. Различные написания одного названия
+// ("Muse", "muse ", "MUSE") объединяются в одну группу, каноническим становится самое частое написание.
+func migrateSongGroups(db *gorm.DB, logger *logrus.Logger) error {
+	// Unscoped: перенос выполняется первой миграцией, когда столбца deletedAt ещё нет
+	var names []string
+	if err := db.Unscoped().Model(&models.Song{}).
+		Select("\"group\"").
+		Where("\"groupId\" IS NULL").
+		Group("group").
+		Order("COUNT(*) DESC").
+		Pluck("\"group\"", &names).Error; err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			group, err := FindOrCreateGroup(tx, name)
+			if err != nil {
+				return err
+			}
+			return tx.Unscoped().Model(&models.Song{}).
+				Where("\"groupId\" IS NULL AND \"group\" = ?", name).
+				Updates(map[string]interface{}{"groupId": group.ID, "group": group.Name, "groupKey": utils.SearchKey(group.Name)}).Error
+		})
+		if err != nil {
+			return err
+		}
+		logger.Infof("Migrated songs of group %q", name)
+	}
+	return nil
+}
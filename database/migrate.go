@@ -0,0 +1,266 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// migrationFiles содержит SQL-миграции для каждой поддерживаемой СУБД: migrations/<driver>/<версия>_<название>.<up|down>.sql.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationFileName разбирает имя файла миграции, например 0001_initial_schema.up.sql.
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// migrationHook — шаг миграции, выполняемый в Go в транзакции миграции.
+type migrationHook func(tx *gorm.DB, logger *logrus.Logger) error
+
+// migrationChecks содержит проверки данных, которые выполняются перед SQL-скриптом миграции с той же версией.
+// Ошибка проверки отменяет миграцию, например если существующие данные нарушают добавляемое ограничение.
+var migrationChecks = map[string]map[int]migrationHook{
+	DriverPostgres: {6: checkDuplicateSongs},
+	DriverSQLite:   {2: checkDuplicateSongs},
+}
+
+// migrationHooks содержит шаги переноса данных, которые нельзя выразить на SQL, например вычисление
+// поисковых ключей функцией utils.SearchKey. Шаг выполняется после SQL-скрипта миграции с той же версией
+// в той же транзакции.
+var migrationHooks = map[string]map[int]migrationHook{
+	DriverPostgres: {1: migrateLegacyData, 8: migrateSongRevisions},
+	DriverSQLite:   {4: migrateSongRevisions},
+}
+
+// Migration описывает одну версию схемы базы данных.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string // SQL для применения миграции
+	Down    string // SQL для отката миграции
+}
+
+// MigrationState описывает миграцию и её состояние в базе данных.
+type MigrationState struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// schemaMigration — запись таблицы schema_migrations о применённой миграции.
+type schemaMigration struct {
+	Version   int       `gorm:"column:version;primaryKey"`
+	Name      string    `gorm:"column:name"`
+	AppliedAt time.Time `gorm:"column:appliedAt"`
+}
+
+// TableName возвращает имя таблицы учета миграций.
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// loadMigrations читает встроенные миграции для СУБД подключения и упорядочивает их по версии.
+// У каждой версии должны быть скрипты применения и отката.
+func loadMigrations(db *gorm.DB) ([]Migration, error) {
+	dir := path.Join("migrations", db.Dialector.Name())
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for driver %s: %w", db.Dialector.Name(), err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file name %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down scripts", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// ensureMigrationsTable создаёт таблицу schema_migrations, если её ещё нет.
+func ensureMigrationsTable(db *gorm.DB) error {
+	return db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+	version bigint PRIMARY KEY,
+	name text NOT NULL,
+	"appliedAt" timestamp NOT NULL
+)`).Error
+}
+
+// appliedMigrations возвращает применённые миграции по версиям.
+func appliedMigrations(db *gorm.DB) (map[int]schemaMigration, error) {
+	if err := ensureMigrationsTable(db); err != nil {
+		return nil, err
+	}
+	var rows []schemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// MigrateUp применяет все ещё не применённые миграции по возрастанию версий.
+// Каждая миграция выполняется в отдельной транзакции вместе с записью в schema_migrations,
+// поэтому ошибка откатывает только её и не оставляет схему в промежуточном состоянии.
+func MigrateUp(db *gorm.DB, logger *logrus.Logger) error {
+	migrations, err := loadMigrations(db)
+	if err != nil {
+		return err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+
+	checks, hooks := migrationChecks[db.Dialector.Name()], migrationHooks[db.Dialector.Name()]
+	count := 0
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		logger.Infof("Applying migration %04d_%s", migration.Version, migration.Name)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if check, ok := checks[migration.Version]; ok {
+				if err := check(tx, logger); err != nil {
+					return err
+				}
+			}
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			if hook, ok := hooks[migration.Version]; ok {
+				if err := hook(tx, logger); err != nil {
+					return err
+				}
+			}
+			return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+
+	if count == 0 {
+		logger.Infof("Database schema is up to date")
+	} else {
+		logger.Infof("Applied %d migrations", count)
+	}
+	return nil
+}
+
+// MigrateDown откатывает steps последних применённых миграций по убыванию версий.
+func MigrateDown(db *gorm.DB, logger *logrus.Logger, steps int) error {
+	migrations, err := loadMigrations(db)
+	if err != nil {
+		return err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		logger.Infof("Rolling back migration %04d_%s", migration.Version, migration.Name)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback of migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		steps--
+	}
+	return nil
+}
+
+// MigrationStatus возвращает все известные миграции с отметкой, применены ли они.
+// Версии, записанные в schema_migrations, но отсутствующие в приложении, считаются ошибкой:
+// база данных обновлена более новой версией приложения.
+func MigrationStatus(db *gorm.DB) ([]MigrationState, error) {
+	migrations, err := loadMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, migration := range migrations {
+		row, ok := applied[migration.Version]
+		states = append(states, MigrationState{Migration: migration, Applied: ok, AppliedAt: row.AppliedAt})
+		delete(applied, migration.Version)
+	}
+	for version, row := range applied {
+		return states, fmt.Errorf("database has migration %04d_%s unknown to this version of the application", version, row.Name)
+	}
+	return states, nil
+}
+
+// migrateLegacyData переносит данные баз, созданных до появления версионированных миграций:
+// переводит текстовые даты выпуска в тип date, выносит названия групп в таблицу groups
+// и заполняет поисковые ключи. Для новой базы данных шаг ничего не делает.
+func migrateLegacyData(tx *gorm.DB, logger *logrus.Logger) error {
+	for _, table := range []string{"songs", "albums"} {
+		if err := migrateReleaseDates(tx, logger, table); err != nil {
+			return fmt.Errorf("release dates of table %s: %w", table, err)
+		}
+	}
+	if err := migrateSongGroups(tx, logger); err != nil {
+		return fmt.Errorf("song groups: %w", err)
+	}
+	if err := migrateSearchKeys(tx, logger); err != nil {
+		return fmt.Errorf("search keys: %w", err)
+	}
+	return nil
+}
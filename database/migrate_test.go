@@ -0,0 +1,284 @@
+package database
+
+import (
+	"MusicLibrary/models"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newTestLogger возвращает логгер, который ничего не выводит.
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// openTestSQLite создаёт пустую базу данных SQLite во временном каталоге теста без применения миграций.
+func openTestSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	t.Setenv("DB_DRIVER", DriverSQLite)
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "test.db"))
+
+	db := Connect(newTestLogger())
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// appliedVersions возвращает число применённых миграций и общее число известных миграций.
+func appliedVersions(t *testing.T, db *gorm.DB) (applied, total int) {
+	t.Helper()
+	states, err := MigrationStatus(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, state := range states {
+		if state.Applied {
+			applied++
+		}
+	}
+	return applied, len(states)
+}
+
+// migrateDownTo откатывает миграции базы данных с применённой схемой так, чтобы миграция name
+// и все следующие за ней оказались не применены, и возвращает версию миграции name.
+func migrateDownTo(t *testing.T, db *gorm.DB, name string) int {
+	t.Helper()
+	migrations, err := loadMigrations(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, migration := range migrations {
+		if migration.Name == name {
+			if err := MigrateDown(db, newTestLogger(), len(migrations)-migration.Version+1); err != nil {
+				t.Fatal(err)
+			}
+			return migration.Version
+		}
+	}
+	t.Fatalf("no migration %s", name)
+	return 0
+}
+
+func TestLoadMigrations(t *testing.T) {
+	postgresDB, err := gorm.Open(postgres.Open("host=127.0.0.1 port=1"), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, db := range []*gorm.DB{postgresDB, openTestSQLite(t)} {
+		migrations, err := loadMigrations(db)
+		if err != nil {
+			t.Fatalf("%s: %v", db.Dialector.Name(), err)
+		}
+		if len(migrations) == 0 {
+			t.Fatalf("%s: no migrations", db.Dialector.Name())
+		}
+		for i, migration := range migrations {
+			if migration.Version != i+1 {
+				t.Errorf("%s: migration %04d_%s follows version %d", db.Dialector.Name(), migration.Version, migration.Name, i)
+			}
+		}
+	}
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	db := openTestSQLite(t)
+	logger := newTestLogger()
+
+	// Повторный запуск не применяет миграции заново и не падает на уже созданных объектах
+	for i := 0; i < 2; i++ {
+		if err := MigrateUp(db, logger); err != nil {
+			t.Fatalf("run %d: %v", i+1, err)
+		}
+		if applied, total := appliedVersions(t, db); applied != total {
+			t.Fatalf("run %d: %d of %d migrations applied", i+1, applied, total)
+		}
+	}
+	group, err := FindOrCreateGroup(db, "Muse")
+	if err != nil {
+		t.Fatalf("schema does not accept a group: %v", err)
+	}
+	if err := db.Create(&models.Song{GroupID: group.ID, Group: group.Name, Song: "Hysteria"}).Error; err != nil {
+		t.Fatalf("schema does not accept a song: %v", err)
+	}
+
+	// Откат всех миграций удаляет схему, после чего её можно создать снова
+	_, total := appliedVersions(t, db)
+	if err := MigrateDown(db, logger, total); err != nil {
+		t.Fatal(err)
+	}
+	if applied, _ := appliedVersions(t, db); applied != 0 || db.Migrator().HasTable("songs") {
+		t.Fatalf("%d migrations applied after rolling back all of them", applied)
+	}
+	if err := MigrateUp(db, logger); err != nil {
+		t.Fatal(err)
+	}
+	if applied, total := appliedVersions(t, db); applied != total {
+		t.Fatalf("%d of %d migrations applied after migrating up again", applied, total)
+	}
+
+	// Миграция из более новой версии приложения
+	if err := db.Create(&schemaMigration{Version: 999, Name: "future"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrationStatus(db); err == nil {
+		t.Error("MigrationStatus succeeded with an unknown applied migration, want an error")
+	}
+}
+
+func TestMigrateLegacyData(t *testing.T) {
+	db := openTestSQLite(t)
+
+	// Таблица songs из версии без групп: названия групп в тексте, даты выпуска строками DD.MM.YYYY
+	if err := db.Exec(`CREATE TABLE songs (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    "groupId" integer,
+    "group" text,
+    song text,
+    "releaseDate" text,
+    text text,
+    link text,
+    "groupKey" text,
+    "songKey" text
+)`).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec(`INSERT INTO songs ("group", song, "releaseDate") VALUES
+    ('Muse', 'Hysteria', '01.12.2003'),
+    ('muse ', 'Starlight', '04.09.2006'),
+    ('Muse', 'Uprising', 'not a date'),
+    ('Кино', 'Кукушка', '')`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Первая миграция схемы с переносом данных, как в PostgreSQL
+	migrations, err := loadMigrations(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migrations[0].Up).Error; err != nil {
+			return err
+		}
+		return migrateLegacyData(tx, newTestLogger())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var songs []models.Song
+	if err := db.Unscoped().Order("id").Find(&songs).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		group, releaseDate, groupKey string
+	}{
+		{group: "Muse", releaseDate: "01.12.2003", groupKey: "muse"},
+		{group: "Muse", releaseDate: "04.09.2006", groupKey: "muse"},
+		{group: "Muse", groupKey: "muse"},
+		{group: "Кино", groupKey: "kino"},
+	}
+	for i, song := range songs {
+		if song.GroupID == 0 || song.Group != want[i].group || song.ReleaseDate.String() != want[i].releaseDate || song.GroupKey != want[i].groupKey || song.SongKey == "" {
+			t.Errorf("song %d: %+v, want group %q, release date %q and search keys", song.ID, song, want[i].group, want[i].releaseDate)
+		}
+	}
+	if songs[0].GroupID != songs[1].GroupID {
+		t.Errorf("spellings of one group were not merged: group IDs %d and %d", songs[0].GroupID, songs[1].GroupID)
+	}
+	var groups int64
+	if err := db.Model(&models.Group{}).Count(&groups).Error; err != nil || groups != 2 {
+		t.Errorf("%d groups, error %v, want 2", groups, err)
+	}
+
+	// Повторный перенос ничего не меняет
+	if err := db.Transaction(func(tx *gorm.DB) error { return migrateLegacyData(tx, newTestLogger()) }); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&models.Group{}).Count(&groups).Error; err != nil || groups != 2 {
+		t.Errorf("%d groups after the second run, error %v, want 2", groups, err)
+	}
+}
+
+func TestMigrateRejectsDuplicateSongs(t *testing.T) {
+	db := openTestSQLite(t)
+	logger := newTestLogger()
+	if err := MigrateUp(db, logger); err != nil {
+		t.Fatal(err)
+	}
+
+	// Откат до миграции с уникальным индексом и дубликаты, созданные до её появления
+	version := migrateDownTo(t, db, "unique_group_song")
+	group, err := FindOrCreateGroup(db, "Muse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"Hysteria", "HYSTERIA", "Starlight"} {
+		if err := db.Exec(`INSERT INTO songs ("groupId", "group", song) VALUES (?, ?, ?)`, group.ID, group.Name, title).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := MigrateUp(db, logger); err == nil {
+		t.Fatal("MigrateUp succeeded with duplicate songs, want an error")
+	}
+	if applied, _ := appliedVersions(t, db); applied != version-1 {
+		t.Fatalf("%d migrations applied, want %d", applied, version-1)
+	}
+
+	// После переименования дубликата миграция применяется
+	if err := db.Exec(`UPDATE songs SET song = ? WHERE song = ?`, "Hysteria (live)", "HYSTERIA").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateUp(db, logger); err != nil {
+		t.Fatal(err)
+	}
+	err = db.Create(&models.Song{GroupID: group.ID, Group: group.Name, Song: "starlight"}).Error
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("insert duplicate: error %v, want gorm.ErrDuplicatedKey", err)
+	}
+}
+
+func TestMigrateSongRevisionsBaseline(t *testing.T) {
+	db := openTestSQLite(t)
+	logger := newTestLogger()
+	if err := MigrateUp(db, logger); err != nil {
+		t.Fatal(err)
+	}
+
+	// Песни, созданные до появления истории изменений, в том числе песня в корзине
+	migrateDownTo(t, db, "song_revisions")
+	group, err := FindOrCreateGroup(db, "Muse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec(`INSERT INTO songs ("groupId", "group", song, text, "deletedAt") VALUES (?, ?, ?, ?, NULL), (?, ?, ?, ?, CURRENT_TIMESTAMP)`,
+		group.ID, group.Name, "Hysteria", "verse", group.ID, group.Name, "Starlight", "chorus").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateUp(db, logger); err != nil {
+		t.Fatal(err)
+	}
+
+	var revisions []models.SongRevision
+	if err := db.Order("\"songId\"").Find(&revisions).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("%d revisions, want a baseline for each of 2 songs", len(revisions))
+	}
+	for i, title := range []string{"Hysteria", "Starlight"} {
+		revision := revisions[i]
+		if revision.Revision != 1 || revision.Action != models.RevisionBaseline || revision.Snapshot == nil || revision.Snapshot.Song != title {
+			t.Errorf("revision of song %d: %+v, want the baseline of %s", revision.SongID, revision, title)
+		}
+	}
+}
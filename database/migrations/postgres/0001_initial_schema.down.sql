@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS album_tracks;
+DROP TABLE IF EXISTS albums;
+DROP TABLE IF EXISTS songs;
+DROP TABLE IF EXISTS group_aliases;
+DROP TABLE IF EXISTS groups;
@@ -0,0 +1,58 @@
+-- Исходная схема библиотеки: группы, альтернативные названия, песни, альбомы и треклисты.
+-- Таблицы и индексы создаются только при их отсутствии, поэтому миграция применима и к базам,
+-- созданным до появления версионированных миграций. В таблицу songs первой версии схемы
+-- добавляются недостающие столбцы; перенос данных выполняется в Go после этого скрипта.
+
+CREATE TABLE IF NOT EXISTS groups (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    "nameKey" text NOT NULL,
+    country text,
+    "formedYear" bigint,
+    description text
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_name_key ON groups ("nameKey");
+
+CREATE TABLE IF NOT EXISTS group_aliases (
+    id bigserial PRIMARY KEY,
+    "groupId" bigint NOT NULL,
+    alias text NOT NULL,
+    "aliasKey" text NOT NULL,
+    CONSTRAINT fk_groups_aliases FOREIGN KEY ("groupId") REFERENCES groups (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_aliases_alias_key ON group_aliases ("aliasKey");
+CREATE INDEX IF NOT EXISTS idx_group_aliases_group_id ON group_aliases ("groupId");
+
+CREATE TABLE IF NOT EXISTS songs (
+    id bigserial PRIMARY KEY,
+    "group" text,
+    song text,
+    "releaseDate" date,
+    text text,
+    link text
+);
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS "groupId" bigint;
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS "groupKey" text;
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS "songKey" text;
+CREATE INDEX IF NOT EXISTS idx_songs_group_id ON songs ("groupId");
+
+CREATE TABLE IF NOT EXISTS albums (
+    id bigserial PRIMARY KEY,
+    title text NOT NULL,
+    "groupId" bigint NOT NULL,
+    "group" text,
+    "releaseDate" date,
+    "coverLink" text,
+    label text
+);
+CREATE INDEX IF NOT EXISTS idx_albums_group_id ON albums ("groupId");
+
+CREATE TABLE IF NOT EXISTS album_tracks (
+    "albumId" bigint,
+    "songId" bigint,
+    "discNumber" bigint NOT NULL DEFAULT 1,
+    "trackNumber" bigint NOT NULL,
+    PRIMARY KEY ("albumId", "songId")
+);
+CREATE INDEX IF NOT EXISTS idx_album_tracks_song_id ON album_tracks ("songId");
+CREATE UNIQUE INDEX IF NOT EXISTS idx_album_tracks_position ON album_tracks ("albumId", "discNumber", "trackNumber");
@@ -0,0 +1,4 @@
+ALTER TABLE album_tracks DROP CONSTRAINT IF EXISTS fk_album_tracks_song;
+ALTER TABLE album_tracks DROP CONSTRAINT IF EXISTS fk_album_tracks_album;
+ALTER TABLE albums DROP CONSTRAINT IF EXISTS fk_albums_group;
+ALTER TABLE songs DROP CONSTRAINT IF EXISTS fk_songs_group;
@@ -0,0 +1,19 @@
+-- Внешние ключи песен и альбомов на группы и позиций треклиста на альбомы и песни.
+-- Ключ песен добавляется после того, как предыдущая миграция заполнила groupId у существующих песен.
+-- При удалении альбома или песни соответствующие позиции треклиста удаляются каскадно.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_songs_group') THEN
+        ALTER TABLE songs ADD CONSTRAINT fk_songs_group FOREIGN KEY ("groupId") REFERENCES groups (id);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_albums_group') THEN
+        ALTER TABLE albums ADD CONSTRAINT fk_albums_group FOREIGN KEY ("groupId") REFERENCES groups (id);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_album_tracks_album') THEN
+        ALTER TABLE album_tracks ADD CONSTRAINT fk_album_tracks_album FOREIGN KEY ("albumId") REFERENCES albums (id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_album_tracks_song') THEN
+        ALTER TABLE album_tracks ADD CONSTRAINT fk_album_tracks_song FOREIGN KEY ("songId") REFERENCES songs (id) ON DELETE CASCADE;
+    END IF;
+END $$;
@@ -0,0 +1,10 @@
+-- Генерируемый столбец searchVector для полнотекстового поиска и GIN-индекс по нему.
+-- Конфигурация 'russian' применяет русский стеммер к словам на кириллице и английский стеммер
+-- к словам на латинице. Название песни имеет наибольший вес, затем название группы и текст песни.
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS "searchVector" tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', coalesce(song, '')), 'A') ||
+    setweight(to_tsvector('russian', coalesce("group", '')), 'B') ||
+    setweight(to_tsvector('russian', coalesce(text, '')), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_songs_search_vector ON songs USING GIN ("searchVector");
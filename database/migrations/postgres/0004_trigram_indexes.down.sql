@@ -0,0 +1,7 @@
+-- Расширение pg_trgm не удаляется: оно может использоваться другими объектами базы данных.
+DROP INDEX IF EXISTS idx_songs_song_key_trgm;
+DROP INDEX IF EXISTS idx_songs_group_key_trgm;
+DROP INDEX IF EXISTS idx_group_aliases_alias_trgm;
+DROP INDEX IF EXISTS idx_groups_name_trgm;
+DROP INDEX IF EXISTS idx_songs_song_trgm;
+DROP INDEX IF EXISTS idx_songs_group_trgm;
@@ -0,0 +1,10 @@
+-- Расширение pg_trgm и GIN-индексы по триграммам названий групп и песен и их поисковых ключей.
+-- Индексы используются поиском с опечатками и ускоряют фильтры ILIKE '%...%' и LIKE по ключам.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_songs_group_trgm ON songs USING GIN ("group" gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_songs_song_trgm ON songs USING GIN (song gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_groups_name_trgm ON groups USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_group_aliases_alias_trgm ON group_aliases USING GIN (alias gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_songs_group_key_trgm ON songs USING GIN ("groupKey" gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_songs_song_key_trgm ON songs USING GIN ("songKey" gin_trgm_ops);
@@ -0,0 +1,5 @@
+-- Индексы для автодополнения по началу названий групп и песен.
+-- Класс операторов text_pattern_ops позволяет использовать индекс в условиях lower(...) LIKE 'префикс%'.
+
+CREATE INDEX IF NOT EXISTS idx_songs_group_prefix ON songs (lower("group") text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_songs_song_prefix ON songs (lower(song) text_pattern_ops);
@@ -0,0 +1,5 @@
+-- Уникальность названия песни в пределах группы без учета регистра. Группа определяется по groupId,
+-- поэтому разные написания названия группы и её альтернативные названия считаются одной группой.
+-- Перед созданием индекса приложение проверяет существующие данные и перечисляет дубликаты в логе.
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_songs_group_song ON songs ("groupId", lower(song));
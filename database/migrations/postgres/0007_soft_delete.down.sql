@@ -0,0 +1,10 @@
+-- Без столбца deletedAt песни в корзине стали бы обычными песнями и могли бы нарушить уникальность
+-- названий, поэтому при откате они удаляются окончательно.
+
+DELETE FROM songs WHERE "deletedAt" IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_songs_group_song;
+CREATE UNIQUE INDEX idx_songs_group_song ON songs ("groupId", lower(song));
+
+DROP INDEX IF EXISTS idx_songs_deleted_at;
+ALTER TABLE songs DROP COLUMN IF EXISTS "deletedAt";
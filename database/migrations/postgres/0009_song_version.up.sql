@@ -0,0 +1,4 @@
+-- Версия песни для оптимистичной блокировки: увеличивается при каждом изменении песни
+-- и возвращается клиентам в заголовке ETag. Существующие песни получают версию 1.
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+-- Провайдеры внешнего API, предоставившие поля песни: JSON-объект вида {"text": "lyrics"}.
+-- NULL означает, что ни одно поле не получено из внешнего API или песня обогащена до появления столбца.
+
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS "enrichmentSources" text;
@@ -0,0 +1,61 @@
+-- Исходная схема библиотеки для SQLite. SQLite не умеет добавлять ограничения в существующую таблицу,
+-- поэтому внешние ключи объявляются сразу при создании таблиц. Полнотекстовый поиск и триграммные
+-- индексы доступны только в PostgreSQL.
+
+CREATE TABLE IF NOT EXISTS groups (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    name text NOT NULL,
+    "nameKey" text NOT NULL,
+    country text,
+    "formedYear" integer,
+    description text
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_name_key ON groups ("nameKey");
+
+CREATE TABLE IF NOT EXISTS group_aliases (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    "groupId" integer NOT NULL,
+    alias text NOT NULL,
+    "aliasKey" text NOT NULL,
+    CONSTRAINT fk_groups_aliases FOREIGN KEY ("groupId") REFERENCES groups (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_aliases_alias_key ON group_aliases ("aliasKey");
+CREATE INDEX IF NOT EXISTS idx_group_aliases_group_id ON group_aliases ("groupId");
+
+CREATE TABLE IF NOT EXISTS songs (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    "groupId" integer,
+    "group" text,
+    song text,
+    "releaseDate" date,
+    text text,
+    link text,
+    "groupKey" text,
+    "songKey" text,
+    CONSTRAINT fk_songs_group FOREIGN KEY ("groupId") REFERENCES groups (id)
+);
+CREATE INDEX IF NOT EXISTS idx_songs_group_id ON songs ("groupId");
+
+CREATE TABLE IF NOT EXISTS albums (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    title text NOT NULL,
+    "groupId" integer NOT NULL,
+    "group" text,
+    "releaseDate" date,
+    "coverLink" text,
+    label text,
+    CONSTRAINT fk_albums_group FOREIGN KEY ("groupId") REFERENCES groups (id)
+);
+CREATE INDEX IF NOT EXISTS idx_albums_group_id ON albums ("groupId");
+
+CREATE TABLE IF NOT EXISTS album_tracks (
+    "albumId" integer,
+    "songId" integer,
+    "discNumber" integer NOT NULL DEFAULT 1,
+    "trackNumber" integer NOT NULL,
+    PRIMARY KEY ("albumId", "songId"),
+    CONSTRAINT fk_album_tracks_album FOREIGN KEY ("albumId") REFERENCES albums (id) ON DELETE CASCADE,
+    CONSTRAINT fk_album_tracks_song FOREIGN KEY ("songId") REFERENCES songs (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_album_tracks_song_id ON album_tracks ("songId");
+CREATE UNIQUE INDEX IF NOT EXISTS idx_album_tracks_position ON album_tracks ("albumId", "discNumber", "trackNumber");
@@ -0,0 +1,6 @@
+-- Уникальность названия песни в пределах группы без учета регистра. Встроенная lower() в SQLite
+-- меняет регистр только латинских букв, поэтому используется функция unicode_lower, которую
+-- приложение регистрирует при подключении. Перед созданием индекса приложение проверяет
+-- существующие данные и перечисляет дубликаты в логе.
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_songs_group_song ON songs ("groupId", unicode_lower(song));
@@ -0,0 +1,9 @@
+-- Мягкое удаление песен: удалённая песня получает время удаления в столбце deletedAt и попадает в корзину.
+-- Уникальность названия в пределах группы проверяется только среди песен вне корзины, поэтому
+-- удалённую песню можно создать заново.
+
+ALTER TABLE songs ADD COLUMN "deletedAt" datetime;
+CREATE INDEX IF NOT EXISTS idx_songs_deleted_at ON songs ("deletedAt");
+
+DROP INDEX IF EXISTS idx_songs_group_song;
+CREATE UNIQUE INDEX idx_songs_group_song ON songs ("groupId", unicode_lower(song)) WHERE "deletedAt" IS NULL;
@@ -0,0 +1,16 @@
+-- История изменений песен: каждая версия хранит действие, изменённые поля, автора, время
+-- и полный снимок песни в JSON. Номер версии возрастает в пределах песни.
+-- При окончательном удалении песни её история удаляется каскадно.
+
+CREATE TABLE IF NOT EXISTS song_revisions (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    "songId" integer NOT NULL,
+    revision integer NOT NULL,
+    action text NOT NULL,
+    "changedFields" text NOT NULL,
+    actor text NOT NULL,
+    "createdAt" datetime NOT NULL,
+    snapshot text NOT NULL,
+    CONSTRAINT fk_song_revisions_song FOREIGN KEY ("songId") REFERENCES songs (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_revisions_song_revision ON song_revisions ("songId", revision);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS jobs;
+ALTER TABLE songs DROP COLUMN "enrichmentStatus";
@@ -0,0 +1,24 @@
+-- Фоновое обогащение песен данными из внешнего API. Песня сохраняется сразу со статусом pending,
+-- а задача обогащения ставится в очередь в таблице jobs и выполняется обработчиками приложения
+-- с повторными попытками. Существующие песни уже обогащены при создании.
+-- Задачи песни удаляются каскадно при её окончательном удалении.
+
+ALTER TABLE songs ADD COLUMN "enrichmentStatus" text NOT NULL DEFAULT 'enriched';
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    type text NOT NULL,
+    "songId" integer NOT NULL,
+    status text NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    "maxAttempts" integer NOT NULL,
+    "lastError" text NOT NULL DEFAULT '',
+    "runAt" datetime NOT NULL,
+    "lockedUntil" datetime,
+    "createdAt" datetime NOT NULL,
+    "updatedAt" datetime NOT NULL,
+    "finishedAt" datetime,
+    CONSTRAINT fk_jobs_song FOREIGN KEY ("songId") REFERENCES songs (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_status_run_at ON jobs (status, "runAt");
+CREATE INDEX IF NOT EXISTS idx_jobs_song ON jobs ("songId");
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// migrateSearchKeys заполняет поисковые ключи groupKey и songKey у песен, для которых они ещё не вычислены.
+// Ключи вычисляются в Go функцией utils.SearchKey, поэтому заполняются построчно.
+func migrateSearchKeys(db *gorm.DB, logger *logrus.Logger) error {
+	// Unscoped: ключи заполняются первой миграцией, когда столбца deletedAt ещё нет
+	var songs []models.Song
+	var filled int
+	err := db.Unscoped().Select("id", "group", "song").
+		Where("\"groupKey\" IS NULL OR \"songKey\" IS NULL").
+		FindInBatches(&songs, 500, func(tx *gorm.DB, batch int) error {
+			for _, song := range songs {
+				err := db.Unscoped().Model(&models.Song{}).Where("id = ?", song.ID).Updates(map[string]interface{}{
+					"groupKey": utils.SearchKey(song.Group),
+					"songKey":  utils.SearchKey(song.Song),
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+			filled += len(songs)
+			return nil
+		}).Error
+	if err != nil {
+		return err
+	}
+	if filled > 0 {
+		logger.Infof("Filled search keys for %d songs", filled)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"MusicLibrary/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// checkDuplicateSongs ищет песни одной группы с одинаковым без учета регистра названием и записывает их в лог.
+// Выполняется перед созданием уникального индекса idx_songs_group_song: если дубликаты есть, миграция
+// отменяется, чтобы их можно было объединить или переименовать вручную.
+func checkDuplicateSongs(tx *gorm.DB, logger *logrus.Logger) error {
+	var songs []struct {
+		ID      uint
+		GroupID *uint  `gorm:"column:groupId"`
+		Group   string `gorm:"column:group"`
+		Song    string
+	}
+	if err := tx.Table("songs").Select(`id, "groupId", "group", song`).Where(`"groupId" IS NOT NULL`).Order("id").Find(&songs).Error; err != nil {
+		return err
+	}
+
+	type key struct {
+		groupID uint
+		title   string
+	}
+	ids := make(map[key][]uint)
+	var order []key
+	for _, song := range songs {
+		k := key{*song.GroupID, strings.ToLower(song.Song)}
+		if _, ok := ids[k]; !ok {
+			order = append(order, k)
+		}
+		ids[k] = append(ids[k], song.ID)
+	}
+
+	duplicates := 0
+	for _, k := range order {
+		if len(ids[k]) < 2 {
+			continue
+		}
+		duplicates++
+		logger.Warnf("Duplicate songs %q of group ID: %d, song IDs: %v", k.title, k.groupID, ids[k])
+	}
+	if duplicates > 0 {
+		return fmt.Errorf("found %d sets of duplicate songs, merge or rename them before applying the unique index", duplicates)
+	}
+	return nil
+}
+
+// migrateSongRevisions записывает текущее состояние каждой существующей песни, включая песни в корзине,
+// первой версией её истории, чтобы к нему можно было вернуться после следующих изменений.
+func migrateSongRevisions(tx *gorm.DB, logger *logrus.Logger) error {
+	var songs []models.Song
+	if err := tx.Unscoped().Order("id").Find(&songs).Error; err != nil {
+		return err
+	}
+	for _, song := range songs {
+		snapshot := song
+		revision := models.SongRevision{
+			SongID:        song.ID,
+			Revision:      1,
+			Action:        models.RevisionBaseline,
+			ChangedFields: []string{},
+			Actor:         "migration",
+			CreatedAt:     time.Now(),
+			Snapshot:      &snapshot,
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+	}
+	if len(songs) > 0 {
+		logger.Infof("Recorded baseline revisions for %d songs", len(songs))
+	}
+	return nil
+}
@@ -18,9 +18,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/songs": {
+        "/albums": {
             "get": {
-                "description": "Возвращает список песен с возможностью фильтрации по группе, названию и дате выпуска, а также поддержкой пагинации.",
+                "description": "Возвращает список альбомов с возможностью фильтрации по названию и группе, а также поддержкой пагинации.",
                 "consumes": [
                     "application/json"
                 ],
@@ -28,26 +28,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "albums"
                 ],
-                "summary": "Получение всех песен",
+                "summary": "Получение всех альбомов",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Название группы",
-                        "name": "group",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Название песни",
-                        "name": "song",
+                        "description": "Название альбома",
+                        "name": "title",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Дата выпуска в формате DD.MM.YYYY",
-                        "name": "releaseDate",
+                        "description": "Название группы",
+                        "name": "group",
                         "in": "query"
                     },
                     {
@@ -60,16 +54,16 @@ const docTemplate = `{
                     {
                         "type": "integer",
                         "default": 5,
-                        "description": "Количество песен на странице",
+                        "description": "Количество альбомов на странице",
                         "name": "limit",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список песен",
+                        "description": "Список альбомов",
                         "schema": {
-                            "$ref": "#/definitions/models.ResponseAllSongs"
+                            "$ref": "#/definitions/models.ResponseAllAlbums"
                         }
                     },
                     "400": {
@@ -87,7 +81,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Добавляет новую песню в библиотеку и обогащает её данные из внешнего API.",
+                "description": "Добавляет новый альбом. Группа указывается по названию и создаётся, если её ещё нет. Формат даты releaseDate: DD.MM.YYYY",
                 "consumes": [
                     "application/json"
                 ],
@@ -95,25 +89,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "albums"
                 ],
-                "summary": "Создание новой песни",
+                "summary": "Создание альбома",
                 "parameters": [
                     {
-                        "description": "Данные песни",
+                        "description": "Данные альбома",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.SongInput"
+                            "$ref": "#/definitions/models.AlbumInput"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Созданная песня",
+                        "description": "Созданный альбом",
                         "schema": {
-                            "$ref": "#/definitions/models.Song"
+                            "$ref": "#/definitions/models.Album"
                         }
                     },
                     "400": {
@@ -122,8 +116,49 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Песня уже существует",
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/albums/{id}": {
+            "get": {
+                "description": "Возвращает альбом по его ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "albums"
+                ],
+                "summary": "Получение альбома",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID альбома",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Альбом",
+                        "schema": {
+                            "$ref": "#/definitions/models.Album"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID альбома",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Альбом не найден",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -135,22 +170,20 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/songs/{id}": {
+            },
             "delete": {
-                "description": "Удаляет песню из библиотеки по её ID.",
+                "description": "Удаляет альбом и его треклист. Сами песни при этом не удаляются.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "albums"
                 ],
-                "summary": "Удаление песни",
+                "summary": "Удаление альбома",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID песни",
+                        "description": "ID альбома",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -158,13 +191,19 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Песня успешно удалена",
+                        "description": "Альбом успешно удалён",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
+                    "400": {
+                        "description": "Некорректный ID альбома",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "404": {
-                        "description": "Песня не найдена",
+                        "description": "Альбом не найден",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -178,7 +217,7 @@ const docTemplate = `{
                 }
             },
             "patch": {
-                "description": "Обновляет информацию о песне по её ID. Можно передавать только те поля модели, которые требуется изменить; остальные останутся без изменений. Изменение ID песни не допускается.",
+                "description": "Обновляет информацию об альбоме. Передаются только изменяемые поля. Формат даты releaseDate: DD.MM.YYYY",
                 "consumes": [
                     "application/json"
                 ],
@@ -186,32 +225,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "albums"
                 ],
-                "summary": "Обновление песни",
+                "summary": "Обновление альбома",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID песни. Изменение ID не допускается.",
+                        "description": "ID альбома",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Обновлённые данные песни (частичное обновление допускается). Формат даты releaseDate: DD.MM.YYYY",
-                        "name": "song",
+                        "description": "Обновлённые данные альбома",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Song"
+                            "$ref": "#/definitions/models.AlbumInput"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Обновлённая песня",
+                        "description": "Обновлённый альбом",
                         "schema": {
-                            "$ref": "#/definitions/models.Song"
+                            "$ref": "#/definitions/models.Album"
                         }
                     },
                     "400": {
@@ -221,7 +260,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Песня не найдена",
+                        "description": "Альбом не найден",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -235,9 +274,56 @@ const docTemplate = `{
                 }
             }
         },
-        "/songs/{id}/verses": {
+        "/albums/{id}/tracks": {
             "get": {
-                "description": "Возвращает куплеты песни по указанному ID с поддержкой пагинации.",
+                "description": "Возвращает песни альбома, упорядоченные по номеру диска и номеру трека.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "albums"
+                ],
+                "summary": "Получение треклиста альбома",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID альбома",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Альбом и его треклист",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseAlbumTracks"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID альбома",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Альбом не найден",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/albums/{id}/tracks/{songId}": {
+            "put": {
+                "description": "Добавляет песню в альбом на указанную позицию или перемещает её, если песня уже есть в альбоме. Номер диска по умолчанию равен 1.",
                 "consumes": [
                     "application/json"
                 ],
@@ -245,47 +331,55 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "albums"
                 ],
-                "summary": "Получение куплетов песни",
+                "summary": "Добавление песни в альбом",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID песни",
+                        "description": "ID альбома",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "default": 1,
-                        "description": "Номер страницы",
-                        "name": "page",
-                        "in": "query"
+                        "description": "ID песни",
+                        "name": "songId",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Лимит на куплеты",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Позиция песни в альбоме",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AlbumTrackInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Информация о песне и ее куплеты, пустой список, если куплеты отсутствуют на запрашиваемой странице",
+                        "description": "Позиция песни в альбоме",
                         "schema": {
-                            "$ref": "#/definitions/models.ResponseSongVerses"
+                            "$ref": "#/definitions/models.AlbumTrack"
                         }
                     },
                     "400": {
-                        "description": "Неверный параметр запроса",
+                        "description": "Ошибка запроса",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Песня не найдена",
+                        "description": "Альбом или песня не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Позиция уже занята другой песней",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -297,92 +391,2442 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "models.ErrorResponse": {
-            "description": "Структура, используемая для возврата сообщений об ошибках.",
-            "type": "object",
-            "properties": {
-                "error": {
-                    "description": "Сообщение об ошибке",
-                    "type": "string"
-                }
-            }
-        },
-        "models.ResponseAllSongs": {
-            "description": "Структура ответа для API, возвращающего все песни",
-            "type": "object",
-            "properties": {
-                "limit": {
-                    "type": "integer"
-                },
-                "page": {
-                    "type": "integer"
-                },
-                "songs": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Song"
+            },
+            "delete": {
+                "description": "Убирает песню из треклиста альбома. Сама песня при этом не удаляется.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "albums"
+                ],
+                "summary": "Удаление песни из альбома",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID альбома",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "songId",
+                        "in": "path",
+                        "required": true
                     }
-                },
-                "total": {
-                    "type": "integer"
-                }
-            }
-        },
-        "models.ResponseSongVerses": {
-            "description": "Структура ответа для API, возвращающего куплеты песни",
-            "type": "object",
-            "properties": {
-                "group": {
-                    "type": "string"
-                },
-                "limit": {
-                    "type": "integer"
-                },
-                "page": {
-                    "type": "integer"
-                },
-                "releaseDate": {
-                    "type": "string"
-                },
-                "song": {
-                    "type": "string"
-                },
-                "total": {
-                    "type": "integer"
-                },
-                "verses": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Песня убрана из альбома",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня отсутствует в альбоме",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "models.Song": {
-            "description": "Модель, содержащая информацию о песне, включая её название, группу, дату выпуска, текст и ссылку на видео.",
-            "type": "object",
-            "properties": {
-                "group": {
-                    "type": "string"
-                },
-                "id": {
+        "/groups": {
+            "get": {
+                "description": "Возвращает список групп с возможностью фильтрации по названию (с учетом альтернативных названий) и поддержкой пагинации.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Получение всех групп",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название группы или одно из её альтернативных названий",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Количество групп на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список групп",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseAllGroups"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Добавляет новую группу. Название и альтернативные названия не должны совпадать с названиями других групп без учета регистра и лишних пробелов.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Создание группы",
+                "parameters": [
+                    {
+                        "description": "Данные группы",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Созданная группа",
+                        "schema": {
+                            "$ref": "#/definitions/models.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Группа уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}": {
+            "get": {
+                "description": "Возвращает группу по её ID вместе с альтернативными названиями.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Получение группы",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Группа",
+                        "schema": {
+                            "$ref": "#/definitions/models.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID группы",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Группа не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет группу по её ID. Группу, у которой есть песни или альбомы, удалить нельзя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Удаление группы",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Группа успешно удалена",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID группы",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Группа не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "У группы есть песни или альбомы",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Обновляет информацию о группе. Передаются только изменяемые поля; переданный список aliases полностью заменяет текущий. При переименовании группы название обновляется и у всех её песен и альбомов; изменение каждой песни получает новую версию в её истории.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Обновление группы",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Обновлённые данные группы",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновлённая группа",
+                        "schema": {
+                            "$ref": "#/definitions/models.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Группа не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Название уже используется другой группой",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "description": "Возвращает состояние фоновой задачи, например обогащения песни после создания: queued (ждёт первой или повторной попытки, runAt — время попытки), running, succeeded или failed (lastError содержит причину).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Получение фоновой задачи",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Задача",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID задачи",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Задача не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs": {
+            "get": {
+                "description": "Возвращает список песен с возможностью фильтрации по группе (с учетом альтернативных названий), названию, альбому, дате и периоду выпуска (диапазон дат, год, десятилетие), а также поддержкой сортировки и пагинации по смещению или по ключу (курсору).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Получение всех песен",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название группы или одно из её альтернативных названий, в том числе в другой транслитерации",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название песни, в том числе в другой транслитерации",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название альбома",
+                        "name": "album",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выпуска в формате DD.MM.YYYY",
+                        "name": "releaseDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выпуска не ранее указанной, формат DD.MM.YYYY",
+                        "name": "releasedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выпуска не позднее указанной, формат DD.MM.YYYY",
+                        "name": "releasedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год выпуска",
+                        "name": "year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Десятилетие выпуска, например 1990 или 1990s",
+                        "name": "decade",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сортировка: поля id, group, song, releaseDate через запятую; префикс - означает убывание, например -releaseDate,group,song. При равенстве значений песни упорядочиваются по id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "offset",
+                            "cursor"
+                        ],
+                        "type": "string",
+                        "default": "offset",
+                        "description": "Режим пагинации: offset (по номеру страницы) или cursor (по ключу)",
+                        "name": "pagination",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор nextCursor или prevCursor из предыдущего ответа; включает пагинацию по ключу. Сортировка должна совпадать с той, для которой выдан курсор",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы (только для пагинации по смещению)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Количество песен на странице, не более 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag из предыдущего ответа; если список не изменился, возвращается 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список песен; если по фильтрам group и song ничего не найдено, поле didYouMean содержит похожие названия",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseAllSongs"
+                        }
+                    },
+                    "304": {
+                        "description": "Список не изменился"
+                    },
+                    "400": {
+                        "description": "Ошибка запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Сохраняет новую песню со статусом обогащения pending и сразу возвращает 202. Дата выпуска, текст и ссылка запрашиваются во внешнем API в фоне с повторными попытками; за выполнением задачи можно следить по GET /jobs/{id} (заголовок Location), а по завершении у песни меняется enrichmentStatus на enriched или failed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Создание новой песни",
+                "parameters": [
+                    {
+                        "description": "Данные песни",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SongInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Автор изменения для истории версий",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Сохранённая песня и задача обогащения. Заголовок Location содержит адрес задачи, ETag — версию песни",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSongAccepted"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Песня уже существует",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/batch": {
+            "post": {
+                "description": "Добавляет песни из массива и обогащает их данные из внешнего API параллельно, не более BATCH_WORKERS запросов одновременно. Ошибка одной песни не отменяет остальные: для каждой песни возвращается статус created, duplicate (уже есть в библиотеке или повторяется в запросе), enrichment_failed (внешний API не вернул данные, песня не создана), invalid (некорректные данные) или error.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Пакетное создание песен",
+                "parameters": [
+                    {
+                        "description": "Песни, не более 500",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SongInput"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Автор изменения для истории версий",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результаты по каждой песне в порядке запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseBatch"
+                        }
+                    },
+                    "400": {
+                        "description": "Тело запроса не является массивом или песен слишком много",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/export": {
+            "get": {
+                "description": "Выгружает все песни, подходящие под те же фильтры, что и в списке песен, одним файлом без пагинации. Ответ передаётся по мере чтения песен из базы данных, поэтому размер библиотеки не ограничен памятью сервера. Столбцы CSV совпадают с полями импорта, так что файл можно загрузить обратно через /songs/import. С gzip=true файл сжимается.",
+                "produces": [
+                    "text/csv",
+                    "application/json",
+                    "application/x-ndjson",
+                    "application/gzip"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Выгрузка песен",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "json",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Формат файла",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Сжать файл gzip",
+                        "name": "gzip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название группы или одно из её альтернативных названий, в том числе в другой транслитерации",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название песни, в том числе в другой транслитерации",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название альбома",
+                        "name": "album",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выпуска в формате DD.MM.YYYY",
+                        "name": "releaseDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выпуска не ранее указанной, формат DD.MM.YYYY",
+                        "name": "releasedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выпуска не позднее указанной, формат DD.MM.YYYY",
+                        "name": "releasedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Год выпуска",
+                        "name": "year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Десятилетие выпуска, например 1990 или 1990s",
+                        "name": "decade",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сортировка, как в списке песен, например -releaseDate,group",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Файл с песнями; заголовок Content-Disposition содержит имя файла",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/fuzzy": {
+            "get": {
+                "description": "Ищет песни, название или группа которых похожи на запрос, даже если в запросе есть опечатки. Для каждой песни возвращается степень сходства от 0 до 1.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Поиск песен с опечатками",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название песни или группы, возможно с опечатками",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Количество результатов на странице, не более 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Найденные песни",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseFuzzySongs"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Поиск недоступен при хранении данных в SQLite",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/import": {
+            "post": {
+                "description": "Загружает песни из файла: CSV с заголовком, массива JSON или NDJSON (объект в каждой строке). Каждая запись содержит group и song и, необязательно, releaseDate (DD.MM.YYYY), text и link. Файл передаётся телом запроса или полем file формы multipart/form-data, не более 10 МБ.\nНовые песни создаются, а у существующих заменяются поля, заполненные в файле. Если в файле у новой песни заполнены не все поля, недостающие запрашиваются во внешнем API; enrich=false отключает эти запросы. С dryRun=true ничего не сохраняется: отчёт показывает, что было бы создано, изменено и пропущено.",
+                "consumes": [
+                    "text/csv",
+                    "application/json",
+                    "application/x-ndjson",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Импорт песен из файла",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "json",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Формат файла; по умолчанию определяется по расширению имени файла или типу содержимого",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сопоставление полей песни и столбцов файла, например group=Artist,song=Title,releaseDate=Released",
+                        "name": "map",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Пробный запуск без сохранения",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Запрашивать недостающие данные во внешнем API",
+                        "name": "enrich",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл импорта (для multipart/form-data)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Автор изменения для истории версий",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отчёт об импорте по каждой записи",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса или файл не удалось прочитать",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Файл слишком большой",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/search": {
+            "get": {
+                "description": "Ищет песни по названию, группе и тексту с учетом словоформ русского и английского языков. Поддерживается синтаксис веб-поиска: фраза в кавычках ищется целиком, \"or\" означает любое из слов, \"-\" исключает слово. Результаты упорядочены по релевантности и содержат куплет с подсвеченными совпадениями.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Полнотекстовый поиск песен",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Поисковый запрос, например \\",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Количество результатов на странице, не более 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Найденные песни",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSearchSongs"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "Поиск недоступен при хранении данных в SQLite",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/trash": {
+            "get": {
+                "description": "Возвращает удалённые песни, начиная с удалённых последними. Песни из корзины можно восстановить или удалить окончательно; по истечении срока хранения они удаляются автоматически.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Получение корзины",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Количество песен на странице, не более 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Песни в корзине",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseTrash"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/trash/{id}": {
+            "delete": {
+                "description": "Удаляет песню из корзины без возможности восстановления вместе с её позициями в треклистах альбомов. Удалить так можно только песню, которая уже находится в корзине.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Окончательное удаление песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Песня удалена окончательно",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID песни",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песни нет в корзине",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}": {
+            "get": {
+                "description": "Возвращает песню по её ID со всеми полями. Параметр fields позволяет запросить только нужные поля (sparse fieldset), например fields=id,song,group.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Получение песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Список полей через запятую: id, groupId, group, song, releaseDate, text, link, version, enrichmentStatus, enrichmentSources",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag песни из предыдущего ответа; если песня не изменилась, возвращается 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Песня (при указании fields — только запрошенные поля). Заголовок ETag содержит версию песни",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "304": {
+                        "description": "Песня не изменилась"
+                    },
+                    "400": {
+                        "description": "Некорректный ID песни или неизвестное поле",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет все изменяемые поля песни значениями из запроса. В отличие от PATCH, который меняет только переданные поля, PUT принимает песню целиком: обязательны song и group (или groupId), а не переданные releaseDate, text и link очищаются. Повторный запрос с тем же телом не меняет песню, поэтому PUT подходит для синхронизации с внешними системами.\nЗначения проверяются так же, как в PATCH: дата в формате DD.MM.YYYY не позднее сегодняшнего дня, ссылка — абсолютный URL http или https. Поле id, если передано, должно совпадать с ID в пути; version только для чтения.\nС параметром upsert=true отсутствующая песня создаётся с указанным ID без обогащения из внешнего API; при создании группа определяется по названию group, если оно передано.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Полная замена песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Песня целиком. Формат даты releaseDate: DD.MM.YYYY",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Создать песню, если песни с таким ID нет",
+                        "name": "upsert",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Автор изменения для истории версий",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag заменяемой песни; обязателен при REQUIRE_IF_MATCH=true",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Заменённая песня. Заголовок ETag содержит её новую версию",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "201": {
+                        "description": "Созданная песня (upsert=true)",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса или некорректные значения полей",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "У группы уже есть песня с таким названием или ID занят песней в корзине",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Песню изменили после получения ETag",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан обязательный заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Перемещает песню в корзину по её ID. Песня перестаёт возвращаться в списках и поиске, но её можно восстановить до окончательного удаления.\nЕсли передан If-Match, песня удаляется, только если она не менялась с получения этого ETag.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Удаление песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Автор изменения для истории версий",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag удаляемой песни; обязателен при REQUIRE_IF_MATCH=true",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Песня успешно удалена",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID песни",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Песню изменили после получения ETag",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан обязательный заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Частично обновляет песню по её ID. Тело запроса — JSON Merge Patch (RFC 7396, application/merge-patch+json или application/json): переданные поля заменяются, null очищает поле, отсутствующие поля не меняются. Очистить можно releaseDate, text и link; group, groupId и song очистить нельзя. Изменение ID песни не допускается. Группу можно сменить по названию (group) или по её ID (groupId).\nТакже принимается JSON Patch (RFC 6902, application/json-patch+json) с путями к полям верхнего уровня, например [{\"op\":\"remove\",\"path\":\"/link\"}].\nЧтобы не перезаписать чужие изменения, передайте в If-Match ETag песни из последнего ответа: если песню успели изменить, вернётся 412.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Обновление песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни. Изменение ID не допускается.",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля песни; null очищает поле. Формат даты releaseDate: DD.MM.YYYY",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Автор изменения для истории версий",
+                        "name": "X-Actor",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag песни, которую изменяет клиент; обязателен при REQUIRE_IF_MATCH=true",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновлённая песня, заново прочитанная из базы данных. Заголовок ETag содержит её новую версию",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса или некорректные значения полей",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "У группы уже есть песня с таким названием или не прошла операция test JSON Patch",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Песню изменили после получения ETag",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Неподдерживаемый тип содержимого",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Не передан обязательный заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/restore": {
+            "post": {
+                "description": "Возвращает удалённую песню из корзины в библиотеку. Если за это время у группы появилась песня с таким же названием, восстановление невозможно.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Восстановление песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Автор изменения для истории версий",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Восстановленная песня. Заголовок ETag содержит её новую версию",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID песни",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песни нет в корзине",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "У группы уже есть песня с таким названием",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions": {
+            "get": {
+                "description": "Возвращает версии песни от новых к старым: действие, изменённые поля, автора и время изменения. Автор берётся из заголовка X-Actor запроса, изменившего песню, или равен IP-адресу клиента. История доступна и для песен в корзине.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "История изменений песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Количество версий на странице, не более 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Версии песни",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSongRevisions"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/{rev}": {
+            "get": {
+                "description": "Возвращает версию песни с полным снимком и построчным сравнением текста с предыдущей версией. Для первой версии весь текст считается добавленным.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Версия песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер версии",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Версия песни",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSongRevision"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID песни или номер версии",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Версия не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/{rev}/revert": {
+            "post": {
+                "description": "Возвращает все поля песни (группу, название, дату выпуска, текст и ссылку) к снимку указанной версии. Откат записывается в историю как новая версия, поэтому его тоже можно отменить.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Откат песни к версии",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер версии",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Автор изменения для истории версий",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Песня после отката. Заголовок ETag содержит её новую версию",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID песни или номер версии",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня или версия не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "У группы уже есть песня с таким названием",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/verses": {
+            "get": {
+                "description": "Возвращает куплеты песни по указанному ID с поддержкой пагинации.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Получение куплетов песни",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Лимит на куплеты",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag песни из предыдущего ответа; если песня не изменилась, возвращается 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Информация о песне и ее куплеты, пустой список, если куплеты отсутствуют на запрашиваемой странице",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSongVerses"
+                        }
+                    },
+                    "304": {
+                        "description": "Песня не изменилась"
+                    },
+                    "400": {
+                        "description": "Неверный параметр запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/suggest": {
+            "get": {
+                "description": "Возвращает до limit различных названий групп или песен, начинающихся с prefix. Сначала идут названия, начинающиеся с prefix целиком, затем названия, в которых с prefix начинается одно из слов; внутри каждой категории — более популярные (с большим количеством песен в библиотеке).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suggest"
+                ],
+                "summary": "Автодополнение названий групп и песен",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало названия",
+                        "name": "prefix",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "group",
+                            "song"
+                        ],
+                        "type": "string",
+                        "default": "song",
+                        "description": "Что дополнять: group или song",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество вариантов, не более 20",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Варианты автодополнения",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuggest"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Запрос не уложился в отведённое время",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.Album": {
+            "description": "Модель альбома, содержащая название, группу, дату выпуска, ссылку на обложку и лейбл.",
+            "type": "object",
+            "properties": {
+                "coverLink": {
+                    "type": "string"
+                },
+                "group": {
+                    "description": "Каноническое название группы из таблицы groups",
+                    "type": "string"
+                },
+                "groupId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "type": "string",
+                    "example": "16.07.2006"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AlbumInput": {
+            "description": "Структура с данными альбома. При изменении передаются только изменяемые поля. Формат даты releaseDate: DD.MM.YYYY",
+            "type": "object",
+            "properties": {
+                "coverLink": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AlbumTrack": {
+            "description": "Позиция песни в альбоме: номер диска и номер трека.",
+            "type": "object",
+            "properties": {
+                "albumId": {
+                    "type": "integer"
+                },
+                "discNumber": {
+                    "type": "integer"
+                },
+                "songId": {
+                    "type": "integer"
+                },
+                "trackNumber": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AlbumTrackInput": {
+            "description": "Номер диска (по умолчанию 1) и номер трека песни в альбоме.",
+            "type": "object",
+            "required": [
+                "trackNumber"
+            ],
+            "properties": {
+                "discNumber": {
+                    "type": "integer"
+                },
+                "trackNumber": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "models.BatchItemResult": {
+            "description": "Результат для песни с номером index в массиве запроса: статус, созданная песня или причина ошибки",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Причина, если песня не создана",
+                    "type": "string"
+                },
+                "index": {
+                    "description": "Номер песни в массиве запроса, начиная с 0",
+                    "type": "integer"
+                },
+                "song": {
+                    "description": "Созданная песня, только для статуса created",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    ]
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "duplicate",
+                        "enrichment_failed",
+                        "invalid",
+                        "error"
+                    ],
+                    "example": "created"
+                }
+            }
+        },
+        "models.DiffLine": {
+            "description": "Строка текста и её судьба: без изменений, добавлена или удалена",
+            "type": "object",
+            "properties": {
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "equal",
+                        "insert",
+                        "delete"
+                    ],
+                    "example": "insert"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "description": "Структура, используемая для возврата сообщений об ошибках.",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Сообщение об ошибке",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Group": {
+            "description": "Модель группы, включающая каноническое название, альтернативные написания, страну, год основания и описание.",
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GroupAlias"
+                    }
+                },
+                "country": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "formedYear": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GroupAlias": {
+            "description": "Альтернативное название группы, по которому она также находится при поиске.",
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GroupInput": {
+            "description": "Структура с данными группы. При изменении передаются только изменяемые поля; переданный список aliases полностью заменяет текущий.",
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "country": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "formedYear": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportItemResult": {
+            "description": "Результат для записи файла: статус, ID песни, изменённые поля или причина ошибки",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Причина, если запись не импортирована",
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "Поля, которые получают значения из файла",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "releaseDate",
+                        "link"
+                    ]
+                },
+                "group": {
+                    "type": "string"
+                },
+                "line": {
+                    "description": "Номер записи: строка CSV или NDJSON, элемент массива JSON, начиная с 1",
+                    "type": "integer"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "songId": {
+                    "description": "ID созданной или изменённой песни; при пробном запуске — только для изменяемых песен",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "updated",
+                        "skipped",
+                        "invalid",
+                        "enrichment_failed",
+                        "error"
+                    ],
+                    "example": "created"
+                }
+            }
+        },
+        "models.ImportReport": {
+            "description": "Количество записей по статусам и результаты по каждой записи в порядке файла",
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "description": "Пробный запуск: изменения не сохранены",
+                    "type": "boolean"
+                },
+                "failed": {
+                    "description": "Записи со статусами invalid, enrichment_failed и error",
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportItemResult"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Job": {
+            "description": "Фоновая задача: статус, номер попытки, время следующей попытки и последняя ошибка",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Количество начатых попыток",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "maxAttempts": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "runAt": {
+                    "description": "Время, не раньше которого начнётся следующая попытка",
+                    "type": "string"
+                },
+                "songId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "queued",
+                        "running",
+                        "succeeded",
+                        "failed"
+                    ],
+                    "example": "queued"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "enrich_song"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResponseAlbumTracks": {
+            "description": "Структура ответа для API, возвращающего упорядоченный треклист альбома",
+            "type": "object",
+            "properties": {
+                "album": {
+                    "$ref": "#/definitions/models.Album"
+                },
+                "tracks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Track"
+                    }
+                }
+            }
+        },
+        "models.ResponseAllAlbums": {
+            "description": "Структура ответа для API, возвращающего список альбомов",
+            "type": "object",
+            "properties": {
+                "albums": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Album"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseAllGroups": {
+            "description": "Структура ответа для API, возвращающего список групп",
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Group"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseAllSongs": {
+            "description": "Структура ответа для API, возвращающего все песни",
+            "type": "object",
+            "properties": {
+                "didYouMean": {
+                    "description": "Похожие названия, если по фильтрам ничего не найдено",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SongSuggestion"
+                        }
+                    ]
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "nextCursor": {
+                    "description": "Курсор следующей страницы при пагинации по ключу",
+                    "type": "string"
+                },
+                "page": {
+                    "description": "Номер страницы; отсутствует при пагинации по ключу",
+                    "type": "integer"
+                },
+                "prevCursor": {
+                    "description": "Курсор предыдущей страницы при пагинации по ключу",
+                    "type": "string"
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseBatch": {
+            "description": "Итоги пакетного создания и результаты по каждой песне в порядке запроса",
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Количество созданных песен",
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchItemResult"
+                    }
+                },
+                "total": {
+                    "description": "Количество песен в запросе",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseFuzzySongs": {
+            "description": "Структура ответа для API поиска с опечатками, результаты упорядочены по степени сходства",
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SongSimilarityHit"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseSearchSongs": {
+            "description": "Структура ответа для API полнотекстового поиска, результаты упорядочены по релевантности",
+            "type": "object",
+            "properties": {
+                "limit": {
                     "type": "integer"
                 },
-                "link": {
+                "page": {
+                    "type": "integer"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SongSearchHit"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseSongAccepted": {
+            "description": "Сохранённая песня со статусом обогащения pending и задача, за выполнением которой можно следить по GET /jobs/{id}",
+            "type": "object",
+            "properties": {
+                "job": {
+                    "$ref": "#/definitions/models.Job"
+                },
+                "song": {
+                    "$ref": "#/definitions/models.Song"
+                }
+            }
+        },
+        "models.ResponseSongRevision": {
+            "description": "Версия песни со снимком и построчным сравнением текста с предыдущей версией",
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "baseline",
+                        "create",
+                        "update",
+                        "delete",
+                        "restore",
+                        "revert"
+                    ],
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "editor@example.com"
+                },
+                "changedFields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "text"
+                    ]
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "revision": {
+                    "description": "Номер версии, начиная с 1 для каждой песни",
+                    "type": "integer"
+                },
+                "snapshot": {
+                    "description": "Отсутствует в списке версий",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    ]
+                },
+                "songId": {
+                    "type": "integer"
+                },
+                "textDiff": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DiffLine"
+                    }
+                }
+            }
+        },
+        "models.ResponseSongRevisions": {
+            "description": "Структура ответа для API, возвращающего версии песни от новых к старым без снимков",
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SongRevision"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseSongVerses": {
+            "description": "Структура ответа для API, возвращающего куплеты песни",
+            "type": "object",
+            "properties": {
+                "group": {
                     "type": "string"
                 },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
                 "releaseDate": {
+                    "type": "string",
+                    "example": "16.07.2006"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "verses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ResponseSuggest": {
+            "description": "Структура ответа для API автодополнения названий групп и песен",
+            "type": "object",
+            "properties": {
+                "prefix": {
+                    "type": "string"
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Suggestion"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResponseTrash": {
+            "description": "Структура ответа для API, возвращающего удалённые песни, начиная с удалённых последними",
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TrashedSong"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Song": {
+            "description": "Модель, содержащая информацию о песне, включая её название, группу, дату выпуска, текст и ссылку на видео.",
+            "type": "object",
+            "properties": {
+                "enrichmentSources": {
+                    "description": "Провайдер, предоставивший каждое поле, значение которого получено из внешнего API: releaseDate, text, link.\nПоле, изменённое после обогащения, из списка удаляется",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "releaseDate": "dates",
+                        "text": "lyrics"
+                    }
+                },
+                "enrichmentStatus": {
+                    "description": "Статус получения данных песни из внешнего API, которое выполняется в фоне после создания",
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "enriched",
+                        "failed"
+                    ],
+                    "example": "enriched"
+                },
+                "group": {
+                    "description": "Каноническое название группы из таблицы groups",
+                    "type": "string"
+                },
+                "groupId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "link": {
                     "type": "string"
                 },
+                "releaseDate": {
+                    "type": "string",
+                    "example": "16.07.2006"
+                },
                 "song": {
                     "type": "string"
                 },
                 "text": {
                     "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
@@ -402,6 +2846,97 @@ const docTemplate = `{
                 }
             }
         },
+        "models.SongRevision": {
+            "description": "Версия песни: действие, изменённые поля, автор, время и полный снимок песни после изменения",
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "baseline",
+                        "create",
+                        "update",
+                        "delete",
+                        "restore",
+                        "revert"
+                    ],
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "editor@example.com"
+                },
+                "changedFields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "text"
+                    ]
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "revision": {
+                    "description": "Номер версии, начиная с 1 для каждой песни",
+                    "type": "integer"
+                },
+                "snapshot": {
+                    "description": "Отсутствует в списке версий",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    ]
+                },
+                "songId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SongSearchHit": {
+            "description": "Найденная песня, её релевантность и фрагмент куплета с подсвеченными совпадениями",
+            "type": "object",
+            "properties": {
+                "rank": {
+                    "description": "Релевантность: чем больше, тем точнее совпадение",
+                    "type": "number"
+                },
+                "snippet": {
+                    "description": "Куплет с совпадением, найденные слова выделены тегами \u003cmark\u003e\u003c/mark\u003e",
+                    "type": "string"
+                },
+                "song": {
+                    "$ref": "#/definitions/models.Song"
+                }
+            }
+        },
+        "models.SongSimilarityHit": {
+            "description": "Найденная песня и степень сходства её названия или группы с запросом",
+            "type": "object",
+            "properties": {
+                "score": {
+                    "description": "Степень сходства от 0 до 1",
+                    "type": "number"
+                },
+                "song": {
+                    "$ref": "#/definitions/models.Song"
+                }
+            }
+        },
+        "models.SongSuggestion": {
+            "description": "Названия группы и песни из библиотеки, наиболее похожие на переданные в фильтрах",
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                }
+            }
+        },
         "models.SuccessResponse": {
             "description": "Структура содержит сообщение о том, что операция выполнена успешно.",
             "type": "object",
@@ -411,6 +2946,112 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.Suggestion": {
+            "description": "Название группы или песни и его популярность — количество песен с этим названием в библиотеке",
+            "type": "object",
+            "properties": {
+                "popularity": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Track": {
+            "description": "Песня альбома вместе с номером диска и номером трека",
+            "type": "object",
+            "properties": {
+                "discNumber": {
+                    "type": "integer"
+                },
+                "song": {
+                    "$ref": "#/definitions/models.Song"
+                },
+                "trackNumber": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TrashedSong": {
+            "description": "Удалённая песня вместе со временем удаления",
+            "type": "object",
+            "properties": {
+                "deletedAt": {
+                    "type": "string",
+                    "example": "2024-05-01T12:00:00Z"
+                },
+                "enrichmentSources": {
+                    "description": "Провайдер, предоставивший каждое поле, значение которого получено из внешнего API: releaseDate, text, link.\nПоле, изменённое после обогащения, из списка удаляется",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "releaseDate": "dates",
+                        "text": "lyrics"
+                    }
+                },
+                "enrichmentStatus": {
+                    "description": "Статус получения данных песни из внешнего API, которое выполняется в фоне после создания",
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "enriched",
+                        "failed"
+                    ],
+                    "example": "enriched"
+                },
+                "group": {
+                    "description": "Каноническое название группы из таблицы groups",
+                    "type": "string"
+                },
+                "groupId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "type": "string",
+                    "example": "16.07.2006"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.ValidationErrorResponse": {
+            "description": "Общее сообщение об ошибке и описание ошибки для каждого некорректного поля.",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Сообщение об ошибке",
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "Ошибки по полям: имя поля и описание ошибки",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "releaseDate": "Invalid date format",
+                        "song": "Song title cannot be cleared"
+                    }
+                }
+            }
         }
     }
 }`
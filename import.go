@@ -0,0 +1,99 @@
+package main
+
+import (
+	"MusicLibrary/database"
+	"MusicLibrary/importer"
+	"MusicLibrary/repository"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+)
+
+// importUsage описывает аргументы команды import.
+const importUsage = `Usage: MusicLibrary import [flags] <file>
+
+Imports songs from a CSV file with a header row, a JSON array or NDJSON.
+Records need group and song; releaseDate (DD.MM.YYYY), text and link are optional.
+
+Flags:`
+
+// runImport выполняет команду import: загружает песни из файла и печатает отчёт по каждой записи.
+func runImport(log *logrus.Logger, args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, importUsage)
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", "", "file format: csv, json or ndjson (default: detected from the file extension)")
+	mapping := flags.String("map", "", "column mapping, e.g. group=Artist,song=Title")
+	dryRun := flags.Bool("dry-run", false, "report what would be created, updated and skipped without saving")
+	noEnrich := flags.Bool("no-enrich", false, "do not fetch missing song details from the external API")
+	workers := flags.Int("workers", 4, "number of records processed concurrently")
+	actor := flags.String("actor", repository.DefaultActor, "author of the changes recorded in song revisions")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *workers < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	columns, err := importer.ParseMapping(*mapping)
+	if err != nil {
+		log.Fatalf("Invalid column mapping: %v", err)
+	}
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = importer.DetectFormat(path, "")
+		if *format == "" {
+			log.Fatalf("Unknown format of file %s. Specify -format csv, json or ndjson", path)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Error opening import file: %v", err)
+	}
+	defer file.Close()
+	records, err := importer.Read(file, *format, columns)
+	if err != nil {
+		log.Fatalf("Error reading import file %s: %v", path, err)
+	}
+
+	db := database.Init(log)
+	songs := repository.NewGormSongRepository(db)
+	ctx := repository.WithActor(context.Background(), *actor)
+	report := importer.Run(ctx, log, songs, records, importer.Options{
+		DryRun:  *dryRun,
+		Enrich:  !*noEnrich,
+		Workers: *workers,
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LINE\tSTATUS\tGROUP\tSONG\tID\tDETAILS")
+	for _, result := range report.Results {
+		id := "-"
+		if result.SongID != 0 {
+			id = fmt.Sprint(result.SongID)
+		}
+		details := result.Error
+		if details == "" {
+			details = strings.Join(result.Fields, ", ")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", result.Line, result.Status, result.Group, result.Song, id, details)
+	}
+	w.Flush()
+
+	summary := fmt.Sprintf("%d records: %d created, %d updated, %d skipped, %d failed",
+		report.Total, report.Created, report.Updated, report.Skipped, report.Failed)
+	if report.DryRun {
+		summary += " (dry run, nothing saved)"
+	}
+	fmt.Println(summary)
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
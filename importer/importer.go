@@ -0,0 +1,255 @@
+/*
+Package importer загружает песни в библиотеку из файлов CSV, JSON и NDJSON.
+Импорт доступен через HTTP API и команду import; обе используют функции этого пакета.
+*/
+
+package importer
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Options задаёт режим импорта.
+type Options struct {
+	DryRun  bool // Только проверить файл и сообщить, что было бы создано, изменено и пропущено
+	Enrich  bool // Запрашивать во внешнем API данные, которых нет в файле
+	Workers int  // Количество записей, обрабатываемых одновременно
+}
+
+// detailFields — количество необязательных полей песни: releaseDate, text и link.
+const detailFields = 3
+
+// item — проверенная запись файла, готовая к импорту.
+type item struct {
+	song    models.Song // Песня из файла: группа, нормализованное название и заполненные в файле поля
+	details []string    // Необязательные поля, значения которых есть в файле
+}
+
+// Run импортирует записи в хранилище songs и возвращает отчёт в порядке записей файла.
+// Новая песня создаётся, а у существующей (та же группа и название) заменяются поля, значения которых
+// есть в файле; пустые значения в файле существующие данные не очищают. Если у новой песни в файле
+// заполнены не все поля и включён Enrich, недостающие запрашиваются во внешнем API.
+// При DryRun хранилище не изменяется и внешний API не вызывается.
+func Run(ctx context.Context, logger *logrus.Logger, songs repository.SongRepository, records []Record, opts Options) models.ImportReport {
+	report := models.ImportReport{DryRun: opts.DryRun, Total: len(records), Results: make([]models.ImportItemResult, len(records))}
+
+	// Записи проверяются по порядку, чтобы повтор песни в файле отмечался у более поздней записи
+	items := make(map[int]item, len(records))
+	seen := make(map[string]int, len(records))
+	for i, record := range records {
+		result := &report.Results[i]
+		result.Line = record.Line
+		parsed, err := parseRecord(record)
+		result.Group, result.Song = parsed.song.Group, parsed.song.Song
+		if err != nil {
+			result.Status, result.Error = models.ImportInvalid, err.Error()
+			continue
+		}
+
+		key := utils.NameKey(parsed.song.Group) + "\x00" + strings.ToLower(parsed.song.Song)
+		if first, ok := seen[key]; ok {
+			result.Status, result.Error = models.ImportSkipped, fmt.Sprintf("Same song as line %d", first)
+			continue
+		}
+		seen[key] = record.Line
+		items[i] = parsed
+	}
+
+	// Ограниченный пул обработчиков: внешний API и хранилище вызываются не более чем из Workers горутин
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(1, min(opts.Workers, len(items))); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				importItem(ctx, logger, songs, items[i], opts, &report.Results[i])
+			}
+		}()
+	}
+	for i := range records {
+		if _, ok := items[i]; ok {
+			queue <- i
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	for _, result := range report.Results {
+		switch result.Status {
+		case models.ImportCreated:
+			report.Created++
+		case models.ImportUpdated:
+			report.Updated++
+		case models.ImportSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+	}
+	return report
+}
+
+// parseRecord проверяет запись файла: группа и название обязательны, дата выпуска в формате DD.MM.YYYY
+// не позднее сегодняшнего дня, ссылка — абсолютный URL http или https.
+func parseRecord(record Record) (item, error) {
+	var parsed item
+	parsed.song.Group = utils.NormalizeName(record.Values["group"])
+	parsed.song.Song = utils.NormalizeName(record.Values["song"])
+	if record.Err != nil {
+		return parsed, record.Err
+	}
+	if parsed.song.Group == "" || parsed.song.Song == "" {
+		return parsed, errors.New("Fields group and song are required")
+	}
+
+	if value := strings.TrimSpace(record.Values["releaseDate"]); value != "" {
+		date, err := models.ParseDate(value)
+		if err != nil {
+			return parsed, err
+		}
+		if date.After(time.Now().Truncate(24 * time.Hour)) {
+			return parsed, errors.New("Release date cannot be in the future")
+		}
+		parsed.song.ReleaseDate = date
+		parsed.details = append(parsed.details, "releaseDate")
+	}
+	if text := record.Values["text"]; strings.TrimSpace(text) != "" {
+		parsed.song.Text = text
+		parsed.details = append(parsed.details, "text")
+	}
+	if link := strings.TrimSpace(record.Values["link"]); link != "" {
+		if !utils.IsHTTPURL(link) {
+			return parsed, errors.New("Link must be an absolute http or https URL")
+		}
+		parsed.song.Link = link
+		parsed.details = append(parsed.details, "link")
+	}
+	return parsed, nil
+}
+
+// importItem создаёт или дополняет песню записи и заполняет её результат.
+func importItem(ctx context.Context, logger *logrus.Logger, songs repository.SongRepository, record item, opts Options, result *models.ImportItemResult) {
+	if err := ctx.Err(); err != nil {
+		result.Status, result.Error = models.ImportError, "Import was cancelled"
+		return
+	}
+
+	existing, err := songs.FindByGroupAndTitle(ctx, record.song.Group, record.song.Song)
+	switch {
+	case err == nil:
+		updateItem(ctx, logger, songs, record, existing, opts, result)
+	case errors.Is(err, repository.ErrNotFound):
+		createItem(ctx, logger, songs, record, opts, result)
+	default:
+		logger.Errorf("Failed to find song %s by %s: %v", record.song.Song, record.song.Group, err)
+		result.Status, result.Error = models.ImportError, "Failed to find the song"
+	}
+}
+
+// updateItem заменяет у существующей песни поля, которые заполнены в файле и отличаются от текущих.
+func updateItem(ctx context.Context, logger *logrus.Logger, songs repository.SongRepository, record item, existing *models.Song, opts Options, result *models.ImportItemResult) {
+	result.SongID = existing.ID
+
+	var patch models.SongPatch
+	for _, field := range record.details {
+		switch {
+		case field == "releaseDate" && !record.song.ReleaseDate.Equal(existing.ReleaseDate.Time):
+			patch.ReleaseDate = &record.song.ReleaseDate
+		case field == "text" && record.song.Text != existing.Text:
+			patch.Text = &record.song.Text
+		case field == "link" && record.song.Link != existing.Link:
+			patch.Link = &record.song.Link
+		default:
+			continue
+		}
+		result.Fields = append(result.Fields, field)
+	}
+	if len(result.Fields) == 0 {
+		result.Status, result.Error = models.ImportSkipped, "Song already exists with the same details"
+		return
+	}
+	if opts.DryRun {
+		result.Status = models.ImportUpdated
+		return
+	}
+
+	// Версия защищает от изменения песни другим запросом между чтением и записью
+	if _, err := songs.Update(ctx, existing.ID, patch, existing.Version); err != nil {
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			result.Status, result.Error = models.ImportError, "Song was modified during the import"
+			return
+		}
+		logger.Errorf("Failed to update song ID: %d during import: %v", existing.ID, err)
+		result.Status, result.Error = models.ImportError, "Failed to update the song"
+		return
+	}
+	result.Status = models.ImportUpdated
+}
+
+// createItem создаёт новую песню, при необходимости дополняя её данными из внешнего API.
+func createItem(ctx context.Context, logger *logrus.Logger, songs repository.SongRepository, record item, opts Options, result *models.ImportItemResult) {
+	song := record.song
+	result.Fields = record.details
+	if opts.DryRun {
+		result.Status = models.ImportCreated
+		return
+	}
+
+	// Внешний API вызывается, только если в файле заполнены не все поля
+	if opts.Enrich && len(record.details) < detailFields {
+		if err := enrich(ctx, logger, &song); err != nil {
+			logger.Warnf("Failed to fetch song details for %s by %s: %v", song.Song, song.Group, err)
+			result.Status, result.Error = models.ImportEnrichmentFailed, "Failed to fetch song details"
+			return
+		}
+	}
+
+	if err := songs.Create(ctx, &song); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			result.Status, result.Error = models.ImportSkipped, "Song already exists in the library"
+			return
+		}
+		logger.Errorf("Failed to save the song %s by %s during import: %v", song.Song, song.Group, err)
+		result.Status, result.Error = models.ImportError, "Failed to save the song"
+		return
+	}
+	result.SongID, result.Status = song.ID, models.ImportCreated
+}
+
+// enrich заполняет пустые поля песни данными из внешнего API и запоминает их источники; значения
+// из файла не заменяются. Некорректная дата из внешнего API не сохраняется.
+func enrich(ctx context.Context, logger *logrus.Logger, song *models.Song) error {
+	details, err := utils.FetchSongDetails(ctx, song.Group, song.Song)
+	if err != nil {
+		return err
+	}
+	var filled []string // Поля, заполненные данными из внешнего API
+	if song.ReleaseDate.IsZero() {
+		releaseDate, err := models.ParseDate(details.ReleaseDate)
+		if err != nil && details.ReleaseDate != "" {
+			logger.Warnf("Invalid release date %q received for song %s by %s", details.ReleaseDate, song.Song, song.Group)
+		}
+		if song.ReleaseDate = releaseDate; !releaseDate.IsZero() {
+			filled = append(filled, "releaseDate")
+		}
+	}
+	if song.Text == "" && details.Text != "" {
+		song.Text, filled = details.Text, append(filled, "text")
+	}
+	if song.Link == "" && details.Link != "" {
+		song.Link, filled = details.Link, append(filled, "link")
+	}
+	song.EnrichmentSources = details.Sources.Only(filled...)
+	return nil
+}
@@ -0,0 +1,172 @@
+package importer
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/repository"
+	"MusicLibrary/utils"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newTestLogger возвращает логгер, который ничего не выводит.
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// useSongDetailsAPI направляет запросы к внешнему API на тестовый сервер, который не знает песню Unknown.
+func useSongDetailsAPI(t *testing.T) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("song") == "Unknown" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, `{"releaseDate":"01.12.2003","text":"api verse","link":"https://example.com/api"}`)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("EXTERNAL_API_URL", server.URL)
+	t.Setenv("EXTERNAL_API_MAX_RETRIES", "0")
+	t.Setenv("EXTERNAL_API_BREAKER_THRESHOLD", "0")
+	if err := utils.InitSongDetailsProviders(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// record возвращает запись файла со строкой line и значениями полей, заданными парами имя, значение.
+func record(line int, values ...string) Record {
+	r := Record{Line: line, Values: make(map[string]string)}
+	for i := 0; i+1 < len(values); i += 2 {
+		r.Values[values[i]] = values[i+1]
+	}
+	return r
+}
+
+// importTestRecords содержит записи файла для каждого статуса импорта. В хранилище уже есть
+// песня Muse — Hysteria с текстом verse.
+var importTestRecords = []Record{
+	record(1, "group", "Muse", "song", "Starlight", "releaseDate", "04.09.2006", "text", "chorus", "link", "https://example.com/starlight"),
+	record(2, "group", "muse", "song", "HYSTERIA", "text", "new verse"),
+	record(3, "group", "Muse", "song", "Hysteria", "text", "verse"),
+	record(4, "group", " Muse ", "song", "starlight"),
+	record(5, "group", "Muse"),
+	record(6, "group", "Muse", "song", "Uprising", "releaseDate", "2009-09-07"),
+	record(7, "group", "Muse", "song", "Uprising", "releaseDate", "01.01.2999"),
+	record(8, "group", "Muse", "song", "Uprising", "link", "/uprising"),
+	{Line: 9, Err: errors.New("Record must be a JSON object")},
+	record(10, "group", "Muse", "song", "Unknown"),
+	record(11, "group", "Blur", "song", "Song 2", "text", "woo-hoo"),
+}
+
+func TestRun(t *testing.T) {
+	useSongDetailsAPI(t)
+	songs := repository.NewMemorySongRepository()
+	ctx := context.Background()
+	if err := songs.Create(ctx, &models.Song{Group: "Muse", Song: "Hysteria", Text: "verse"}); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Run(ctx, newTestLogger(), songs, importTestRecords, Options{Enrich: true, Workers: 3})
+
+	want := []struct {
+		status string
+		fields string
+	}{
+		{status: models.ImportCreated, fields: "releaseDate,text,link"},
+		{status: models.ImportUpdated, fields: "text"},
+		{status: models.ImportSkipped},
+		{status: models.ImportSkipped},
+		{status: models.ImportInvalid},
+		{status: models.ImportInvalid},
+		{status: models.ImportInvalid},
+		{status: models.ImportInvalid},
+		{status: models.ImportInvalid},
+		{status: models.ImportEnrichmentFailed, fields: ""},
+		{status: models.ImportCreated, fields: "text"},
+	}
+	for i, result := range report.Results {
+		if result.Line != importTestRecords[i].Line || result.Status != want[i].status || strings.Join(result.Fields, ",") != want[i].fields {
+			t.Errorf("line %d: status %q, fields %v, error %q, want status %q and fields %s",
+				result.Line, result.Status, result.Fields, result.Error, want[i].status, want[i].fields)
+		}
+	}
+	if report.Results[3].Error != "Same song as line 1" {
+		t.Errorf("repeated song: error %q, want a reference to line 1", report.Results[3].Error)
+	}
+	if report.Total != 11 || report.Created != 2 || report.Updated != 1 || report.Skipped != 2 || report.Failed != 6 || report.DryRun {
+		t.Errorf("report totals %+v", report)
+	}
+
+	// Значения из файла не заменяются данными внешнего API, недостающие поля заполняются
+	blur, err := songs.FindByGroupAndTitle(ctx, "Blur", "Song 2")
+	if err != nil || blur.Text != "woo-hoo" || blur.Link != "https://example.com/api" || blur.ReleaseDate.String() != "01.12.2003" {
+		t.Errorf("enriched song %+v, error %v", blur, err)
+	}
+	hysteria, err := songs.FindByGroupAndTitle(ctx, "Muse", "Hysteria")
+	if err != nil || hysteria.Text != "new verse" || hysteria.Version != 2 {
+		t.Errorf("updated song %+v, error %v, want the text from the file", hysteria, err)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	// Внешний API при пробном запуске не вызывается
+	t.Setenv("EXTERNAL_API_URL", "http://127.0.0.1:1")
+	songs := repository.NewMemorySongRepository()
+	ctx := context.Background()
+	if err := songs.Create(ctx, &models.Song{Group: "Muse", Song: "Hysteria", Text: "verse"}); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Run(ctx, newTestLogger(), songs, importTestRecords, Options{DryRun: true, Enrich: true, Workers: 2})
+	if !report.DryRun || report.Created != 3 || report.Updated != 1 || report.Skipped != 2 || report.Failed != 5 {
+		t.Errorf("dry run report totals %+v", report)
+	}
+	if report.Results[1].SongID != 1 || report.Results[0].SongID != 0 {
+		t.Errorf("dry run song IDs %d and %d, want 1 for the updated song and none for the new one", report.Results[1].SongID, report.Results[0].SongID)
+	}
+
+	page, total, err := songs.List(ctx, repository.SongListQuery{Sort: []repository.SortField{{Name: "id"}}})
+	if err != nil || total != 1 || page[0].Text != "verse" || page[0].Version != 1 {
+		t.Errorf("songs after a dry run: %+v, total %d, error %v, want the library unchanged", page, total, err)
+	}
+}
+
+func TestRunWithoutEnrich(t *testing.T) {
+	t.Setenv("EXTERNAL_API_URL", "http://127.0.0.1:1")
+	songs := repository.NewMemorySongRepository()
+
+	records := []Record{record(1, "group", "Muse", "song", "Unknown")}
+	report := Run(context.Background(), newTestLogger(), songs, records, Options{Workers: 1})
+	if report.Created != 1 || report.Results[0].Status != models.ImportCreated {
+		t.Errorf("report %+v, want the song created without the external API", report)
+	}
+}
+
+func TestReadAndRunMapping(t *testing.T) {
+	songs := repository.NewMemorySongRepository()
+	mapping, err := ParseMapping("group=Artist,song=Title,releaseDate=Released")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := Read(strings.NewReader("Artist,Title,Released\nMuse,Hysteria,01.12.2003\n"), FormatCSV, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := Run(context.Background(), newTestLogger(), songs, records, Options{Workers: 1})
+	if report.Created != 1 {
+		t.Fatalf("report %+v, want one created song", report)
+	}
+	song, err := songs.Get(context.Background(), report.Results[0].SongID)
+	if err != nil || song.Group != "Muse" || song.Song != "Hysteria" || song.ReleaseDate.String() != "01.12.2003" {
+		t.Errorf("imported song %+v, error %v", song, err)
+	}
+}
@@ -0,0 +1,222 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Форматы файлов импорта.
+const (
+	FormatCSV    = "csv"    // Таблица с заголовком в первой строке
+	FormatJSON   = "json"   // Массив объектов
+	FormatNDJSON = "ndjson" // Объект JSON в каждой строке
+)
+
+// Fields — поля песни, которые можно импортировать. Поля group и song обязательны.
+var Fields = []string{"group", "song", "releaseDate", "text", "link"}
+
+// Record — запись файла импорта: значения полей песни по их именам.
+type Record struct {
+	Line   int               // Номер строки CSV или NDJSON, элемента массива JSON, начиная с 1
+	Values map[string]string // Значения полей из Fields; отсутствующие поля не заполняются
+	Err    error             // Ошибка разбора записи
+}
+
+// ParseMapping разбирает сопоставление полей песни и столбцов файла вида "group=Artist,song=Title".
+// Поля без сопоставления читаются из столбцов с тем же именем.
+func ParseMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return mapping, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || field == "" || column == "" {
+			return nil, fmt.Errorf("invalid column mapping %q, expected field=column", pair)
+		}
+		if !isField(field) {
+			return nil, fmt.Errorf("unknown field %q in column mapping, expected one of %s", field, strings.Join(Fields, ", "))
+		}
+		mapping[field] = column
+	}
+	return mapping, nil
+}
+
+// DetectFormat определяет формат файла по расширению имени или по типу содержимого.
+// Возвращает пустую строку, если формат определить не удалось.
+func DetectFormat(name, contentType string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	}
+	switch contentType {
+	case "text/csv":
+		return FormatCSV
+	case "application/json":
+		return FormatJSON
+	case "application/x-ndjson", "application/jsonl":
+		return FormatNDJSON
+	}
+	return ""
+}
+
+// Read читает записи файла в формате format. mapping сопоставляет поля песни со столбцами файла
+// (см. ParseMapping). Ошибка отдельной записи сохраняется в Record.Err, а ошибка всего файла,
+// например отсутствие обязательного столбца в заголовке CSV, возвращается сразу.
+func Read(r io.Reader, format string, mapping map[string]string) ([]Record, error) {
+	columns := make(map[string]string, len(Fields))
+	for _, field := range Fields {
+		columns[field] = field
+		if column, ok := mapping[field]; ok {
+			columns[field] = column
+		}
+	}
+
+	// Файлы, сохранённые в Excel и Блокноте Windows, начинаются с метки порядка байтов
+	r = skipByteOrderMark(r)
+
+	switch format {
+	case FormatCSV:
+		return readCSV(r, columns)
+	case FormatJSON:
+		var items []json.RawMessage
+		if err := json.NewDecoder(r).Decode(&items); err != nil {
+			return nil, errors.New("JSON file must contain an array of songs")
+		}
+		records := make([]Record, 0, len(items))
+		for i, item := range items {
+			records = append(records, jsonRecord(i+1, item, columns))
+		}
+		return records, nil
+	case FormatNDJSON:
+		var records []Record
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			records = append(records, jsonRecord(line, json.RawMessage(scanner.Bytes()), columns))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return records, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q, expected %s, %s or %s", format, FormatCSV, FormatJSON, FormatNDJSON)
+	}
+}
+
+// byteOrderMark — метка порядка байтов UTF-8.
+const byteOrderMark = "\ufeff"
+
+// skipByteOrderMark возвращает r без метки порядка байтов UTF-8 в начале, если она есть.
+func skipByteOrderMark(r io.Reader) io.Reader {
+	buffered := bufio.NewReader(r)
+	if prefix, err := buffered.Peek(len(byteOrderMark)); err == nil && string(prefix) == byteOrderMark {
+		buffered.Discard(len(byteOrderMark))
+	}
+	return buffered
+}
+
+// readCSV читает таблицу CSV: первая строка содержит названия столбцов.
+func readCSV(r io.Reader, columns map[string]string) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if err != io.EOF && !errors.As(err, &parseErr) {
+			return nil, err
+		}
+		return nil, errors.New("CSV file must start with a header row")
+	}
+
+	// Номер столбца для каждого поля песни; столбцы, не относящиеся к полям, пропускаются
+	index := make(map[string]int)
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		for field, column := range columns {
+			if strings.EqualFold(name, column) {
+				index[field] = i
+			}
+		}
+	}
+	for _, field := range []string{"group", "song"} {
+		if _, ok := index[field]; !ok {
+			return nil, fmt.Errorf("CSV header has no column %q for field %s", columns[field], field)
+		}
+	}
+
+	var records []Record
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			records = append(records, Record{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		record := Record{Line: line, Values: make(map[string]string)}
+		for field, i := range index {
+			if i < len(row) {
+				record.Values[field] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// jsonRecord разбирает объект JSON с полями песни. Значения должны быть строками; null равен пустой строке.
+func jsonRecord(line int, item json.RawMessage, columns map[string]string) Record {
+	record := Record{Line: line, Values: make(map[string]string)}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(item, &object); err != nil || object == nil {
+		record.Err = errors.New("Record must be a JSON object")
+		return record
+	}
+	for field, column := range columns {
+		raw, ok := object[column]
+		if !ok {
+			continue
+		}
+		var value *string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			record.Err = fmt.Errorf("Field %s must be a string", column)
+			return record
+		}
+		if value != nil {
+			record.Values[field] = *value
+		}
+	}
+	return record
+}
+
+// isField проверяет, что name — одно из импортируемых полей песни.
+func isField(name string) bool {
+	for _, field := range Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package importer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMapping(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{value: "", want: map[string]string{}},
+		{value: "group=Artist, song = Title", want: map[string]string{"group": "Artist", "song": "Title"}},
+		{value: "releaseDate=Released", want: map[string]string{"releaseDate": "Released"}},
+		{value: "group", wantErr: true},
+		{value: "group=", wantErr: true},
+		{value: "=Artist", wantErr: true},
+		{value: "album=Album", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMapping(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMapping(%q) error %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseMapping(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		want        string
+	}{
+		{name: "songs.CSV", want: FormatCSV},
+		{name: "songs.json", contentType: "text/csv", want: FormatJSON},
+		{name: "songs.ndjson", want: FormatNDJSON},
+		{name: "songs.jsonl", want: FormatNDJSON},
+		{contentType: "text/csv", want: FormatCSV},
+		{contentType: "application/json", want: FormatJSON},
+		{contentType: "application/x-ndjson", want: FormatNDJSON},
+		{name: "songs.txt", contentType: "text/plain", want: ""},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.name, tt.contentType); got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %q, want %q", tt.name, tt.contentType, got, tt.want)
+		}
+	}
+}
+
+// recordSummary описывает записи строками "line:group/song" или "line:error" для сравнения в тестах.
+func recordSummary(records []Record) []string {
+	summary := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.Err != nil {
+			summary = append(summary, fmt.Sprintf("%d:error", record.Line))
+			continue
+		}
+		summary = append(summary, fmt.Sprintf("%d:%s/%s", record.Line, record.Values["group"], record.Values["song"]))
+	}
+	return summary
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		mapping map[string]string
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "csv", format: FormatCSV, data: "group,song,text\nMuse,Hysteria,verse\nBlur,Song 2,\n", want: []string{"2:Muse/Hysteria", "3:Blur/Song 2"}},
+		{name: "csv header case and extra columns", format: FormatCSV, data: "Song,Rating,GROUP\nHysteria,5,Muse\n", want: []string{"2:Muse/Hysteria"}},
+		{name: "csv byte order mark", format: FormatCSV, data: "\ufeffgroup,song\nMuse,Hysteria\n", want: []string{"2:Muse/Hysteria"}},
+		{name: "csv mapping", format: FormatCSV, mapping: map[string]string{"group": "Artist", "song": "Title"}, data: "Artist,Title\nMuse,Hysteria\n", want: []string{"2:Muse/Hysteria"}},
+		{name: "csv unknown mapped column", format: FormatCSV, mapping: map[string]string{"group": "Artist"}, data: "group,song\nMuse,Hysteria\n", wantErr: true},
+		{name: "csv missing required column", format: FormatCSV, data: "group,text\nMuse,verse\n", wantErr: true},
+		{name: "csv empty", format: FormatCSV, data: "", wantErr: true},
+		{name: "csv short row", format: FormatCSV, data: "group,song\nMuse\n", want: []string{"2:Muse/"}},
+		{name: "csv malformed row", format: FormatCSV, data: "group,song\nMuse,\"Hysteria\nBlur,Song 2\n", want: []string{"2:error"}},
+		{name: "csv malformed row between valid rows", format: FormatCSV, data: "group,song\nMuse,Hysteria\nMuse,Star\"light\nBlur,Song 2\n", want: []string{"2:Muse/Hysteria", "3:error", "4:Blur/Song 2"}},
+		{name: "json", format: FormatJSON, data: `[{"group":"Muse","song":"Hysteria","text":null},{"group":"Blur","song":"Song 2"}]`, want: []string{"1:Muse/Hysteria", "2:Blur/Song 2"}},
+		{name: "json mapping", format: FormatJSON, mapping: map[string]string{"song": "title"}, data: `[{"group":"Muse","title":"Hysteria","song":"ignored"}]`, want: []string{"1:Muse/Hysteria"}},
+		{name: "json invalid records", format: FormatJSON, data: `["Muse",{"group":"Muse","song":1},null]`, want: []string{"1:error", "2:error", "3:error"}},
+		{name: "json byte order mark", format: FormatJSON, data: "\ufeff[{\"group\":\"Muse\",\"song\":\"Hysteria\"}]", want: []string{"1:Muse/Hysteria"}},
+		{name: "json not an array", format: FormatJSON, data: `{"group":"Muse","song":"Hysteria"}`, wantErr: true},
+		{name: "ndjson empty lines", format: FormatNDJSON, data: "{\"group\":\"Muse\",\"song\":\"Hysteria\"}\n\n  \n{\"group\":\"Blur\",\"song\":\"Song 2\"}\n", want: []string{"1:Muse/Hysteria", "4:Blur/Song 2"}},
+		{name: "ndjson byte order mark", format: FormatNDJSON, data: "\ufeff{\"group\":\"Muse\",\"song\":\"Hysteria\"}\n{\"group\":\"Blur\",\"song\":\"Song 2\"}\n", want: []string{"1:Muse/Hysteria", "2:Blur/Song 2"}},
+		{name: "ndjson crlf", format: FormatNDJSON, data: "{\"group\":\"Muse\",\"song\":\"Hysteria\"}\r\n{\"group\":\"Blur\",\"song\":\"Song 2\"}\r\n", want: []string{"1:Muse/Hysteria", "2:Blur/Song 2"}},
+		{name: "ndjson malformed line", format: FormatNDJSON, data: "{\"group\":\"Muse\",\"song\":\"Hysteria\"}\n{\"group\":\n", want: []string{"1:Muse/Hysteria", "2:error"}},
+		{name: "unknown format", format: "xml", data: "<songs/>", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := Read(strings.NewReader(tt.data), tt.format, tt.mapping)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if got := recordSummary(records); !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
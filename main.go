@@ -11,13 +11,25 @@
 package main
 
 import (
+	"MusicLibrary/background"
 	"MusicLibrary/database"
 	_ "MusicLibrary/docs"
 	"MusicLibrary/logger"
+	"MusicLibrary/repository"
 	"MusicLibrary/routes"
+	"MusicLibrary/utils"
+	"context"
+	"errors"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -31,11 +43,59 @@ func main() {
 		log.Fatalf("Error loading .env file: %v", err)
 	}
 
+	// Команда migrate управляет схемой базы данных без запуска сервера
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(log, os.Args[2:])
+		return
+	}
+
+	// Провайдеры данных песен настраиваются до команды import, которая тоже обогащает песни
+	if err := utils.InitSongDetailsProviders(); err != nil {
+		log.Fatalf("Invalid external API settings: %v", err)
+	}
+
+	// Команда import загружает песни из файла без запуска сервера
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		runImport(log, os.Args[2:])
+		return
+	}
+
 	// Инициализация базы данных с логгером
-	database.Init(log)
+	db := database.Init(log)
+
+	songs := repository.NewGormSongRepository(db)
+	jobs := repository.NewGormJobRepository(db)
+	groups := repository.NewGormGroupRepository(db)
+	albums := repository.NewGormAlbumRepository(db)
+
+	// Фоновые задачи и сервер останавливаются по SIGINT или SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var workers sync.WaitGroup
+
+	// Фоновая очистка корзины от песен, срок хранения которых истёк
+	retention, interval := trashSettings(log)
+	if retention > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			background.PurgeTrash(ctx, log, songs, retention, interval)
+		}()
+	}
+
+	// Фоновое обогащение созданных песен данными из внешнего API
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		background.EnrichSongs(ctx, log, songs, jobs, enrichmentSettings(log))
+	}()
 
-	// Настройка маршрутов с логгером
-	router := routes.SetupRouter(log)
+	// Настройка маршрутов с логгером, хранилищами и очередью задач в базе данных
+	router := routes.SetupRouter(log, songs, jobs, groups, albums, routes.Options{
+		EnrichMaxAttempts: enrichMaxAttempts(log),
+		BatchWorkers:      batchWorkers(log),
+		RequireIfMatch:    requireIfMatch(log),
+	})
 
 	// Регистрация Swagger
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -45,6 +105,122 @@ func main() {
 		port = "8080"
 	}
 
-	log.Infof("Starting server on port %s", port) // Используем логгер для записи информации
-	router.Run(":" + port)                        // Запуск сервера на указанном порту.
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		log.Infof("Starting server on port %s", port) // Используем логгер для записи информации
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Info("Shutting down server")
+
+	// Сервер завершает начатые запросы, затем дожидаемся фоновых задач: прерванная попытка обогащения
+	// возвращает задачу в очередь
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Errorf("Failed to shut down server gracefully: %v", err)
+	}
+	workers.Wait()
+	log.Info("Server stopped")
+}
+
+// shutdownTimeout — сколько сервер при остановке ждёт завершения начатых запросов.
+const shutdownTimeout = 10 * time.Second
+
+// trashSettings возвращает срок хранения песен в корзине из переменной окружения TRASH_RETENTION_DAYS
+// (по умолчанию 30 дней, 0 отключает автоматическую очистку) и период очистки из TRASH_PURGE_INTERVAL
+// (по умолчанию 1h).
+func trashSettings(log *logrus.Logger) (retention, interval time.Duration) {
+	days := 30
+	if value := os.Getenv("TRASH_RETENTION_DAYS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid TRASH_RETENTION_DAYS %q. Expected a non-negative number of days", value)
+		}
+		days = n
+	}
+
+	interval = time.Hour
+	if value := os.Getenv("TRASH_PURGE_INTERVAL"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			log.Fatalf("Invalid TRASH_PURGE_INTERVAL %q. Expected a positive duration such as 30m or 6h", value)
+		}
+		interval = d
+	}
+	return time.Duration(days) * 24 * time.Hour, interval
+}
+
+// enrichMaxAttempts возвращает количество попыток задачи обогащения новой песни из ENRICH_MAX_ATTEMPTS
+// (по умолчанию 5).
+func enrichMaxAttempts(log *logrus.Logger) int {
+	attempts := 5
+	if value := os.Getenv("ENRICH_MAX_ATTEMPTS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			log.Fatalf("Invalid ENRICH_MAX_ATTEMPTS %q. Expected a positive number", value)
+		}
+		attempts = n
+	}
+	return attempts
+}
+
+// batchWorkers возвращает количество песен пакетного создания и импорта, которые обогащаются из внешнего API
+// одновременно, из BATCH_WORKERS (по умолчанию 4).
+func batchWorkers(log *logrus.Logger) int {
+	workers := 4
+	if value := os.Getenv("BATCH_WORKERS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			log.Fatalf("Invalid BATCH_WORKERS %q. Expected a positive number", value)
+		}
+		workers = n
+	}
+	return workers
+}
+
+// requireIfMatch сообщает, обязателен ли заголовок If-Match для изменения и удаления песни
+// (переменная окружения REQUIRE_IF_MATCH, по умолчанию false).
+func requireIfMatch(log *logrus.Logger) bool {
+	value := os.Getenv("REQUIRE_IF_MATCH")
+	if value == "" {
+		return false
+	}
+	required, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Invalid REQUIRE_IF_MATCH %q. Expected true or false", value)
+	}
+	return required
+}
+
+// enrichmentSettings возвращает параметры фонового обогащения песен: количество обработчиков
+// из ENRICH_WORKERS (по умолчанию 2) и задержку перед повторной попыткой из ENRICH_RETRY_DELAY
+// (по умолчанию 10s, перед каждой следующей попыткой удваивается, но не превышает 1h).
+func enrichmentSettings(log *logrus.Logger) background.EnrichmentOptions {
+	opts := background.EnrichmentOptions{
+		Workers:       2,
+		PollInterval:  time.Second,
+		RetryDelay:    10 * time.Second,
+		MaxRetryDelay: time.Hour,
+		Lease:         5 * time.Minute,
+	}
+	if value := os.Getenv("ENRICH_WORKERS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			log.Fatalf("Invalid ENRICH_WORKERS %q. Expected a positive number", value)
+		}
+		opts.Workers = n
+	}
+	if value := os.Getenv("ENRICH_RETRY_DELAY"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			log.Fatalf("Invalid ENRICH_RETRY_DELAY %q. Expected a positive duration such as 10s or 1m", value)
+		}
+		opts.RetryDelay = d
+	}
+	return opts
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"MusicLibrary/database"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+)
+
+// migrateUsage описывает аргументы команды migrate.
+const migrateUsage = `Usage: MusicLibrary migrate <command>
+
+Commands:
+  up          apply all pending migrations
+  down [N]    roll back the last N applied migrations (default 1)
+  status      list migrations and whether they are applied`
+
+// runMigrate выполняет команду migrate up|down|status.
+func runMigrate(log *logrus.Logger, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	db := database.Connect(log)
+	switch args[0] {
+	case "up":
+		if err := database.MigrateUp(db, log); err != nil {
+			log.Fatalf("Error during database migration: %v", err)
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("Invalid number of migrations to roll back: %s", args[1])
+			}
+			steps = n
+		}
+		if err := database.MigrateDown(db, log, steps); err != nil {
+			log.Fatalf("Error during database rollback: %v", err)
+		}
+	case "status":
+		states, err := database.MigrationStatus(db)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, state := range states {
+			appliedAt := "pending"
+			if state.Applied {
+				appliedAt = state.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", state.Version, state.Name, appliedAt)
+		}
+		w.Flush()
+		if err != nil {
+			log.Fatalf("Error reading migration status: %v", err)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+// Album представляет музыкальный альбом в базе данных.
+// @Description Модель альбома, содержащая название, группу, дату выпуска, ссылку на обложку и лейбл.
+type Album struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Title       string `gorm:"column:title;not null" json:"title"`
+	GroupID     uint   `gorm:"column:groupId;not null;index" json:"groupId"`
+	Group       string `gorm:"column:group" json:"group"` // Каноническое название группы из таблицы groups
+	ReleaseDate Date   `gorm:"column:releaseDate" json:"releaseDate" swaggertype:"string" example:"16.07.2006"`
+	CoverLink   string `gorm:"column:coverLink" json:"coverLink"`
+	Label       string `gorm:"column:label" json:"label"`
+}
+
+// AlbumTrack представляет связь "многие ко многим" между альбомами и песнями и позицию песни в треклисте.
+// @Description Позиция песни в альбоме: номер диска и номер трека.
+type AlbumTrack struct {
+	AlbumID     uint `gorm:"column:albumId;primaryKey;uniqueIndex:idx_album_tracks_position,priority:1" json:"albumId"`
+	SongID      uint `gorm:"column:songId;primaryKey;index" json:"songId"`
+	DiscNumber  int  `gorm:"column:discNumber;not null;default:1;uniqueIndex:idx_album_tracks_position,priority:2" json:"discNumber"`
+	TrackNumber int  `gorm:"column:trackNumber;not null;uniqueIndex:idx_album_tracks_position,priority:3" json:"trackNumber"`
+}
+
+// AlbumInput представляет данные для создания или изменения альбома.
+// @Description Структура с данными альбома. При изменении передаются только изменяемые поля. Формат даты releaseDate: DD.MM.YYYY
+type AlbumInput struct {
+	Title       string `json:"title"`
+	Group       string `json:"group"`
+	ReleaseDate string `json:"releaseDate"`
+	CoverLink   string `json:"coverLink"`
+	Label       string `json:"label"`
+}
+
+// AlbumTrackInput представляет позицию песни в альбоме.
+// @Description Номер диска (по умолчанию 1) и номер трека песни в альбоме.
+type AlbumTrackInput struct {
+	DiscNumber  int `json:"discNumber"`
+	TrackNumber int `json:"trackNumber" binding:"required,min=1"`
+}
+
+// ResponseAllAlbums описывает структуру ответа для получения всех альбомов.
+// @Description Структура ответа для API, возвращающего список альбомов
+type ResponseAllAlbums struct {
+	Total  int64   `json:"total"`
+	Page   int     `json:"page"`
+	Limit  int     `json:"limit"`
+	Albums []Album `json:"albums"`
+}
+
+// Track описывает песню в треклисте альбома.
+// @Description Песня альбома вместе с номером диска и номером трека
+type Track struct {
+	DiscNumber  int  `json:"discNumber"`
+	TrackNumber int  `json:"trackNumber"`
+	Song        Song `json:"song"`
+}
+
+// ResponseAlbumTracks описывает структуру ответа для получения треклиста альбома.
+// @Description Структура ответа для API, возвращающего упорядоченный треклист альбома
+type ResponseAlbumTracks struct {
+	Album  Album   `json:"album"`
+	Tracks []Track `json:"tracks"`
+}
@@ -0,0 +1,27 @@
+package models
+
+// Статусы песен при пакетном создании.
+const (
+	BatchCreated          = "created"           // Песня создана
+	BatchDuplicate        = "duplicate"         // Песня уже есть в библиотеке или повторяется в запросе
+	BatchEnrichmentFailed = "enrichment_failed" // Не удалось получить данные песни из внешнего API
+	BatchInvalid          = "invalid"           // Некорректные данные песни
+	BatchError            = "error"             // Внутренняя ошибка при сохранении песни
+)
+
+// BatchItemResult описывает результат создания одной песни из пакета.
+// @Description Результат для песни с номером index в массиве запроса: статус, созданная песня или причина ошибки
+type BatchItemResult struct {
+	Index  int    `json:"index"` // Номер песни в массиве запроса, начиная с 0
+	Status string `json:"status" enums:"created,duplicate,enrichment_failed,invalid,error" example:"created"`
+	Song   *Song  `json:"song,omitempty"`  // Созданная песня, только для статуса created
+	Error  string `json:"error,omitempty"` // Причина, если песня не создана
+}
+
+// ResponseBatch описывает структуру ответа пакетного создания песен.
+// @Description Итоги пакетного создания и результаты по каждой песне в порядке запроса
+type ResponseBatch struct {
+	Total   int               `json:"total"`   // Количество песен в запросе
+	Created int               `json:"created"` // Количество созданных песен
+	Results []BatchItemResult `json:"results"`
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DateLayout задаёт формат даты, используемый в API: DD.MM.YYYY.
+const DateLayout = "02.01.2006"
+
+// dbDateLayout задаёт формат даты, в котором она передаётся в базу данных.
+const dbDateLayout = "2006-01-02"
+
+// ErrInvalidDate возвращается, если строка не соответствует формату DD.MM.YYYY.
+var ErrInvalidDate = errors.New("Invalid date format. Expected format: DD.MM.YYYY")
+
+// Date представляет календарную дату без времени.
+// В JSON дата передаётся строкой в формате DD.MM.YYYY, в базе данных хранится в столбце типа date.
+// Нулевое значение означает отсутствие даты: пустая строка в JSON и NULL в базе данных.
+type Date struct {
+	time.Time
+}
+
+// NewDate создаёт дату по году, месяцу и дню.
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate разбирает дату в формате DD.MM.YYYY.
+func ParseDate(value string) (Date, error) {
+	parsed, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return Date{}, ErrInvalidDate
+	}
+	return Date{parsed}, nil
+}
+
+// String возвращает дату в формате DD.MM.YYYY или пустую строку для нулевой даты.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(DateLayout)
+}
+
+// MarshalJSON сериализует дату в формате DD.MM.YYYY.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON разбирает дату в формате DD.MM.YYYY. Пустая строка и null дают нулевую дату.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value *string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return ErrInvalidDate
+	}
+	if value == nil || *value == "" {
+		*d = Date{}
+		return nil
+	}
+	parsed, err := ParseDate(*value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// GormDataType задаёт тип столбца для миграций GORM.
+func (Date) GormDataType() string {
+	return "date"
+}
+
+// Value реализует driver.Valuer: нулевая дата сохраняется как NULL.
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.Format(dbDateLayout), nil
+}
+
+// Scan реализует sql.Scanner для значений типа date, полученных из базы данных.
+func (d *Date) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = NewDate(v.Year(), v.Month(), v.Day())
+	case string:
+		return d.scanString(v)
+	case []byte:
+		return d.scanString(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into Date", value)
+	}
+	return nil
+}
+
+// scanString разбирает текстовое представление даты из базы данных (YYYY-MM-DD, возможно со временем).
+func (d *Date) scanString(value string) error {
+	if len(value) < len(dbDateLayout) {
+		return fmt.Errorf("cannot scan %q into Date", value)
+	}
+	parsed, err := time.Parse(dbDateLayout, value[:len(dbDateLayout)])
+	if err != nil {
+		return err
+	}
+	*d = Date{parsed}
+	return nil
+}
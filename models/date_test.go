@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDateJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    Date
+		wantErr bool
+	}{
+		{data: `"01.12.2003"`, want: NewDate(2003, time.December, 1)},
+		{data: `""`},
+		{data: `null`},
+		{data: `"2003-12-01"`, wantErr: true},
+		{data: `"31.02.2003"`, wantErr: true},
+		{data: `20031201`, wantErr: true},
+	}
+	for _, tt := range tests {
+		var date Date
+		err := json.Unmarshal([]byte(tt.data), &date)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("unmarshal %s = %s, want an error", tt.data, date)
+			}
+			continue
+		}
+		if err != nil || !date.Equal(tt.want.Time) {
+			t.Errorf("unmarshal %s = %s, %v, want %s", tt.data, date, err, tt.want)
+		}
+	}
+
+	data, err := json.Marshal(struct {
+		Set   Date `json:"set"`
+		Unset Date `json:"unset"`
+	}{Set: NewDate(2003, time.December, 1)})
+	if err != nil || string(data) != `{"set":"01.12.2003","unset":""}` {
+		t.Errorf("marshal = %s, %v", data, err)
+	}
+}
+
+func TestDateSQL(t *testing.T) {
+	want := NewDate(2003, time.December, 1)
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    Date
+		wantErr bool
+	}{
+		{name: "null", value: nil},
+		{name: "time", value: time.Date(2003, time.December, 1, 15, 4, 5, 0, time.FixedZone("MSK", 3*60*60)), want: want},
+		{name: "string", value: "2003-12-01", want: want},
+		{name: "string with time", value: "2003-12-01 00:00:00+00:00", want: want},
+		{name: "bytes", value: []byte("2003-12-01"), want: want},
+		{name: "short string", value: "2003", wantErr: true},
+		{name: "not a date", value: "01.12.2003", wantErr: true},
+		{name: "unsupported type", value: 20031201, wantErr: true},
+	}
+	for _, tt := range tests {
+		var date Date
+		err := date.Scan(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: Scan(%v) = %s, want an error", tt.name, tt.value, date)
+			}
+			continue
+		}
+		if err != nil || !date.Equal(tt.want.Time) {
+			t.Errorf("%s: Scan(%v) = %s, %v, want %s", tt.name, tt.value, date, err, tt.want)
+		}
+	}
+
+	if value, err := want.Value(); err != nil || value != "2003-12-01" {
+		t.Errorf("Value() = %v, %v, want 2003-12-01", value, err)
+	}
+	if value, err := (Date{}).Value(); err != nil || value != nil {
+		t.Errorf("zero date Value() = %v, %v, want NULL", value, err)
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+// Group представляет музыкальную группу (исполнителя) в базе данных.
+// @Description Модель группы, включающая каноническое название, альтернативные написания, страну, год основания и описание.
+type Group struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	Name        string       `gorm:"column:name;not null" json:"name"`
+	NameKey     string       `gorm:"column:nameKey;not null;uniqueIndex" json:"-"`
+	Country     string       `gorm:"column:country" json:"country"`
+	FormedYear  int          `gorm:"column:formedYear" json:"formedYear"`
+	Description string       `gorm:"column:description" json:"description"`
+	Aliases     []GroupAlias `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE" json:"aliases"`
+}
+
+// GroupAlias представляет альтернативное написание названия группы.
+// @Description Альтернативное название группы, по которому она также находится при поиске.
+type GroupAlias struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	GroupID  uint   `gorm:"column:groupId;not null;index" json:"-"`
+	Alias    string `gorm:"column:alias;not null" json:"alias"`
+	AliasKey string `gorm:"column:aliasKey;not null;uniqueIndex" json:"-"`
+}
+
+// GroupInput представляет данные для создания или изменения группы.
+// @Description Структура с данными группы. При изменении передаются только изменяемые поля; переданный список aliases полностью заменяет текущий.
+type GroupInput struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases"`
+	Country     string   `json:"country"`
+	FormedYear  int      `json:"formedYear"`
+	Description string   `json:"description"`
+}
+
+// ResponseAllGroups описывает структуру ответа для получения всех групп.
+// @Description Структура ответа для API, возвращающего список групп
+type ResponseAllGroups struct {
+	Total  int64   `json:"total"`
+	Page   int     `json:"page"`
+	Limit  int     `json:"limit"`
+	Groups []Group `json:"groups"`
+}
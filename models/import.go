@@ -0,0 +1,35 @@
+package models
+
+// Статусы записей при импорте песен из файла.
+const (
+	ImportCreated          = "created"           // Песня создана
+	ImportUpdated          = "updated"           // Существующая песня дополнена данными из файла
+	ImportSkipped          = "skipped"           // Песня уже есть с теми же данными или повторяется в файле
+	ImportInvalid          = "invalid"           // Запись не разобрана или содержит некорректные данные
+	ImportEnrichmentFailed = "enrichment_failed" // Не удалось получить данные песни из внешнего API
+	ImportError            = "error"             // Внутренняя ошибка при сохранении песни
+)
+
+// ImportItemResult описывает результат импорта одной записи файла.
+// @Description Результат для записи файла: статус, ID песни, изменённые поля или причина ошибки
+type ImportItemResult struct {
+	Line   int      `json:"line"` // Номер записи: строка CSV или NDJSON, элемент массива JSON, начиная с 1
+	Status string   `json:"status" enums:"created,updated,skipped,invalid,enrichment_failed,error" example:"created"`
+	Group  string   `json:"group,omitempty"`
+	Song   string   `json:"song,omitempty"`
+	SongID uint     `json:"songId,omitempty"`                            // ID созданной или изменённой песни; при пробном запуске — только для изменяемых песен
+	Fields []string `json:"fields,omitempty" example:"releaseDate,link"` // Поля, которые получают значения из файла
+	Error  string   `json:"error,omitempty"`                             // Причина, если запись не импортирована
+}
+
+// ImportReport описывает итоги импорта песен.
+// @Description Количество записей по статусам и результаты по каждой записи в порядке файла
+type ImportReport struct {
+	DryRun  bool               `json:"dryRun"` // Пробный запуск: изменения не сохранены
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Skipped int                `json:"skipped"`
+	Failed  int                `json:"failed"` // Записи со статусами invalid, enrichment_failed и error
+	Results []ImportItemResult `json:"results"`
+}
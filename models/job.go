@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Статусы обогащения песни данными из внешнего API.
+const (
+	EnrichmentPending  = "pending"  // Песня сохранена, данные из внешнего API ещё не получены
+	EnrichmentEnriched = "enriched" // Данные получены или песня создана без обращения к внешнему API
+	EnrichmentFailed   = "failed"   // Внешний API не вернул данные за все попытки
+)
+
+// JobEnrichSong — тип задачи, которая дополняет песню датой выпуска, текстом и ссылкой из внешнего API.
+const JobEnrichSong = "enrich_song"
+
+// Статусы фоновых задач.
+const (
+	JobQueued    = "queued"    // Задача ждёт первой или повторной попытки
+	JobRunning   = "running"   // Задачу выполняет обработчик
+	JobSucceeded = "succeeded" // Задача выполнена
+	JobFailed    = "failed"    // Все попытки исчерпаны или задачу нельзя выполнить
+)
+
+// Job представляет фоновую задачу в очереди, сохранённой в базе данных.
+// @Description Фоновая задача: статус, номер попытки, время следующей попытки и последняя ошибка
+type Job struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Type        string     `gorm:"column:type" json:"type" example:"enrich_song"`
+	SongID      uint       `gorm:"column:songId" json:"songId"`
+	Status      string     `gorm:"column:status" json:"status" enums:"queued,running,succeeded,failed" example:"queued"`
+	Attempts    int        `gorm:"column:attempts" json:"attempts"` // Количество начатых попыток
+	MaxAttempts int        `gorm:"column:maxAttempts" json:"maxAttempts" example:"5"`
+	LastError   string     `gorm:"column:lastError" json:"lastError,omitempty"`
+	RunAt       time.Time  `gorm:"column:runAt" json:"runAt"`   // Время, не раньше которого начнётся следующая попытка
+	LockedUntil *time.Time `gorm:"column:lockedUntil" json:"-"` // Срок, до которого задачу выполняет захвативший её обработчик
+	CreatedAt   time.Time  `gorm:"column:createdAt" json:"createdAt"`
+	UpdatedAt   time.Time  `gorm:"column:updatedAt" json:"updatedAt"`
+	FinishedAt  *time.Time `gorm:"column:finishedAt" json:"finishedAt,omitempty"`
+}
+
+// ResponseSongAccepted описывает ответ на создание песни, данные которой дополняются в фоне.
+// @Description Сохранённая песня со статусом обогащения pending и задача, за выполнением которой можно следить по GET /jobs/{id}
+type ResponseSongAccepted struct {
+	Song Song `json:"song"`
+	Job  Job  `json:"job"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Действия над песней, которые записываются в историю изменений.
+const (
+	RevisionBaseline = "baseline" // Состояние песни на момент включения истории изменений
+	RevisionCreate   = "create"
+	RevisionUpdate   = "update"
+	RevisionDelete   = "delete"
+	RevisionRestore  = "restore"
+	RevisionRevert   = "revert"
+)
+
+// SongRevision представляет запись истории изменений песни.
+// @Description Версия песни: действие, изменённые поля, автор, время и полный снимок песни после изменения
+type SongRevision struct {
+	ID            uint      `gorm:"primaryKey" json:"-"`
+	SongID        uint      `gorm:"column:songId" json:"songId"`
+	Revision      int       `gorm:"column:revision" json:"revision"` // Номер версии, начиная с 1 для каждой песни
+	Action        string    `gorm:"column:action" json:"action" enums:"baseline,create,update,delete,restore,revert" example:"update"`
+	ChangedFields []string  `gorm:"column:changedFields;serializer:json" json:"changedFields" example:"text"`
+	Actor         string    `gorm:"column:actor" json:"actor" example:"editor@example.com"`
+	CreatedAt     time.Time `gorm:"column:createdAt" json:"createdAt"`
+	Snapshot      *Song     `gorm:"column:snapshot;serializer:json" json:"snapshot,omitempty"` // Отсутствует в списке версий
+}
+
+// ResponseSongRevisions описывает структуру ответа для получения истории изменений песни.
+// @Description Структура ответа для API, возвращающего версии песни от новых к старым без снимков
+type ResponseSongRevisions struct {
+	Total     int64          `json:"total"`
+	Page      int            `json:"page"`
+	Limit     int            `json:"limit"`
+	Revisions []SongRevision `json:"revisions"`
+}
+
+// DiffLine описывает строку построчного сравнения текстов.
+// @Description Строка текста и её судьба: без изменений, добавлена или удалена
+type DiffLine struct {
+	Op   string `json:"op" enums:"equal,insert,delete" example:"insert"`
+	Text string `json:"text"`
+}
+
+// ResponseSongRevision описывает структуру ответа для получения версии песни.
+// @Description Версия песни со снимком и построчным сравнением текста с предыдущей версией
+type ResponseSongRevision struct {
+	SongRevision
+	TextDiff []DiffLine `json:"textDiff"`
+}
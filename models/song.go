@@ -5,6 +5,12 @@ Package models содержит структуры данных, использ
 */
 package models
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 // SongInput представляет данные, необходимые для создания новой песни.
 // @Description Структура, содержащая информацию о песне и группе для создания новой записи в библиотеке.
 type SongInput struct {
@@ -12,24 +18,76 @@ type SongInput struct {
 	Song  string `json:"song" binding:"required"`
 }
 
+// SongPatch описывает изменения песни при частичном обновлении. Поле со значением nil не изменяется.
+// Пустая строка в Text или Link и нулевая дата в ReleaseDate очищают соответствующее поле.
+type SongPatch struct {
+	GroupID     *uint   // Новая группа по ID
+	Group       *string // Новая группа по названию; группа создаётся при необходимости
+	Song        *string
+	ReleaseDate *Date
+	Text        *string
+	Link        *string
+	// Статус обогащения данными из внешнего API; меняется фоновой задачей
+	EnrichmentStatus *string
+	// Провайдеры полей, значения которых в этом изменении получены из внешнего API; дополняют текущие
+	EnrichmentSources SongSources
+}
+
 // Song представляет модель песни в базе данных.
 // @Description Модель, содержащая информацию о песне, включая её название, группу, дату выпуска, текст и ссылку на видео.
 type Song struct {
 	ID          uint   `gorm:"primaryKey" json:"id"`
-	Group       string `gorm:"column:group" json:"group"`
+	GroupID     uint   `gorm:"column:groupId;index" json:"groupId"`
+	Group       string `gorm:"column:group" json:"group"` // Каноническое название группы из таблицы groups
 	Song        string `gorm:"column:song" json:"song"`
-	ReleaseDate string `gorm:"column:releaseDate" json:"releaseDate"`
+	ReleaseDate Date   `gorm:"column:releaseDate" json:"releaseDate" swaggertype:"string" example:"16.07.2006"`
 	Text        string `gorm:"column:text" json:"text"`
 	Link        string `gorm:"column:link" json:"link"`
+	Version     uint   `gorm:"column:version;not null;default:1" json:"version" example:"1"`
+	GroupKey    string `gorm:"column:groupKey" json:"-"` // Поисковый ключ названия группы с учетом транслитерации
+	SongKey     string `gorm:"column:songKey" json:"-"`  // Поисковый ключ названия песни с учетом транслитерации
+	// Статус получения данных песни из внешнего API, которое выполняется в фоне после создания
+	EnrichmentStatus string `gorm:"column:enrichmentStatus" json:"enrichmentStatus" enums:"pending,enriched,failed" example:"enriched"`
+	// Провайдер, предоставивший каждое поле, значение которого получено из внешнего API: releaseDate, text, link.
+	// Поле, изменённое после обогащения, из списка удаляется
+	EnrichmentSources SongSources `gorm:"column:enrichmentSources" json:"enrichmentSources" swaggertype:"object,string" example:"text:lyrics,releaseDate:dates"`
+	// Время удаления песни в корзину. GORM исключает удалённые песни из запросов, пока не вызван Unscoped
+	DeletedAt gorm.DeletedAt `gorm:"column:deletedAt;index" json:"-" swaggerignore:"true"`
+}
+
+// TrashedSong описывает песню в корзине.
+// @Description Удалённая песня вместе со временем удаления
+type TrashedSong struct {
+	Song
+	DeletedAt time.Time `json:"deletedAt" example:"2024-05-01T12:00:00Z"`
+}
+
+// ResponseTrash описывает структуру ответа для получения содержимого корзины.
+// @Description Структура ответа для API, возвращающего удалённые песни, начиная с удалённых последними
+type ResponseTrash struct {
+	Total int64         `json:"total"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+	Songs []TrashedSong `json:"songs"`
 }
 
 // ResponseAllSongs описывает структуру ответа для получения всех песен.
 // @Description Структура ответа для API, возвращающего все песни
 type ResponseAllSongs struct {
-	Total int64  `json:"total"`
-	Page  int    `json:"page"`
-	Limit int    `json:"limit"`
-	Songs []Song `json:"songs"`
+	Total      int64           `json:"total"`
+	Page       int             `json:"page,omitempty"` // Номер страницы; отсутствует при пагинации по ключу
+	Limit      int             `json:"limit"`
+	NextCursor string          `json:"nextCursor,omitempty"` // Курсор следующей страницы при пагинации по ключу
+	PrevCursor string          `json:"prevCursor,omitempty"` // Курсор предыдущей страницы при пагинации по ключу
+	DidYouMean *SongSuggestion `json:"didYouMean,omitempty"` // Похожие названия, если по фильтрам ничего не найдено
+	Songs      []Song          `json:"songs"`
+}
+
+// SongSuggestion описывает исправленные значения фильтров group и song.
+// @Description Названия группы и песни из библиотеки, наиболее похожие на переданные в фильтрах
+type SongSuggestion struct {
+	Group string `json:"group,omitempty"`
+	Song  string `json:"song,omitempty"`
 }
 
 // ResponseSongVerses описывает структуру ответа для получения куплетов песни.
@@ -37,19 +95,71 @@ type ResponseAllSongs struct {
 type ResponseSongVerses struct {
 	Song        string   `json:"song"`
 	Group       string   `json:"group"`
-	ReleaseDate string   `json:"releaseDate"`
+	ReleaseDate Date     `json:"releaseDate" swaggertype:"string" example:"16.07.2006"`
 	Verses      []string `json:"verses"`
 	Page        int      `json:"page"`
 	Limit       int      `json:"limit"`
 	Total       int      `json:"total"`
 }
 
+// SongSearchHit описывает песню, найденную полнотекстовым поиском.
+// @Description Найденная песня, её релевантность и фрагмент куплета с подсвеченными совпадениями
+type SongSearchHit struct {
+	Song    Song    `json:"song"`
+	Rank    float64 `json:"rank"`    // Релевантность: чем больше, тем точнее совпадение
+	Snippet string  `json:"snippet"` // Куплет с совпадением, найденные слова выделены тегами <mark></mark>
+}
+
+// ResponseSearchSongs описывает структуру ответа для полнотекстового поиска песен.
+// @Description Структура ответа для API полнотекстового поиска, результаты упорядочены по релевантности
+type ResponseSearchSongs struct {
+	Query   string          `json:"query"`
+	Total   int64           `json:"total"`
+	Page    int             `json:"page"`
+	Limit   int             `json:"limit"`
+	Results []SongSearchHit `json:"results"`
+}
+
+// SongSimilarityHit описывает песню, найденную поиском с опечатками.
+// @Description Найденная песня и степень сходства её названия или группы с запросом
+type SongSimilarityHit struct {
+	Song  Song    `json:"song"`
+	Score float64 `json:"score"` // Степень сходства от 0 до 1
+}
+
+// ResponseFuzzySongs описывает структуру ответа для поиска песен с опечатками.
+// @Description Структура ответа для API поиска с опечатками, результаты упорядочены по степени сходства
+type ResponseFuzzySongs struct {
+	Query   string              `json:"query"`
+	Total   int64               `json:"total"`
+	Page    int                 `json:"page"`
+	Limit   int                 `json:"limit"`
+	Results []SongSimilarityHit `json:"results"`
+}
+
+// Suggestion описывает вариант автодополнения.
+// @Description Название группы или песни и его популярность — количество песен с этим названием в библиотеке
+type Suggestion struct {
+	Value      string `json:"value"`
+	Popularity int64  `json:"popularity"`
+}
+
+// ResponseSuggest описывает структуру ответа автодополнения.
+// @Description Структура ответа для API автодополнения названий групп и песен
+type ResponseSuggest struct {
+	Prefix      string       `json:"prefix"`
+	Type        string       `json:"type"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
 // SongDetail представляет данные, полученные из внешнего API.
 // @Description Модель, содержащая информацию о дате выпуска песни, тексте и ссылке на видео.
 type SongDetail struct {
 	ReleaseDate string `json:"releaseDate"` // Дата выпуска песни
 	Text        string `json:"text"`        // Текст песни
 	Link        string `json:"link"`        // Ссылка на видео с песней
+	// Имя провайдера, предоставившего каждое заполненное поле: releaseDate, text, link
+	Sources SongSources `json:"sources,omitempty"`
 }
 
 // SuccessResponse представляет ответ при успешном удалении песни.
@@ -63,3 +173,11 @@ type SuccessResponse struct {
 type ErrorResponse struct {
 	Error string `json:"error"` // Сообщение об ошибке
 }
+
+// ValidationErrorResponse представляет ошибку проверки полей запроса.
+// @Description Общее сообщение об ошибке и описание ошибки для каждого некорректного поля.
+type ValidationErrorResponse struct {
+	Error string `json:"error"` // Сообщение об ошибке
+	// Ошибки по полям: имя поля и описание ошибки
+	Fields map[string]string `json:"fields" example:"releaseDate:Invalid date format,song:Song title cannot be cleared"`
+}
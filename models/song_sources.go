@@ -0,0 +1,64 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// SongSources сопоставляет поля песни, полученные из внешнего API (releaseDate, text, link),
+// с именами провайдеров, которые их предоставили. В базе данных хранится JSON-объектом в текстовом столбце.
+type SongSources map[string]string
+
+// GormDataType задаёт тип столбца для миграций GORM.
+func (SongSources) GormDataType() string {
+	return "text"
+}
+
+// Value реализует driver.Valuer: пустой набор сохраняется как NULL.
+func (s SongSources) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(map[string]string(s))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan реализует sql.Scanner для JSON-объекта, полученного из базы данных.
+func (s *SongSources) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into SongSources", value)
+	}
+	var sources map[string]string
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return err
+	}
+	*s = sources
+	return nil
+}
+
+// Only возвращает источники только перечисленных полей или nil, если таких нет.
+func (s SongSources) Only(fields ...string) SongSources {
+	var result SongSources
+	for _, field := range fields {
+		if provider, ok := s[field]; ok {
+			if result == nil {
+				result = make(SongSources)
+			}
+			result[field] = provider
+		}
+	}
+	return result
+}
@@ -0,0 +1,22 @@
+package repository
+
+import "context"
+
+// DefaultActor записывается в историю изменений, если автор изменения неизвестен.
+const DefaultActor = "anonymous"
+
+// actorKey — ключ контекста, в котором передаётся автор изменения.
+type actorKey struct{}
+
+// WithActor возвращает контекст, изменения в котором записываются в историю от имени actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext возвращает автора изменения, сохранённого функцией WithActor, или DefaultActor.
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return DefaultActor
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"MusicLibrary/models"
+	"context"
+	"errors"
+)
+
+// ErrAlbumNotFound возвращается, если альбома с указанным ID нет.
+var ErrAlbumNotFound = errors.New("album not found")
+
+// ErrTrackNotFound возвращается, если песни нет в треклисте альбома.
+var ErrTrackNotFound = errors.New("track not found")
+
+// ErrTrackPositionTaken возвращается, если позицию в треклисте занимает другая песня.
+var ErrTrackPositionTaken = errors.New("track position is already taken")
+
+// AlbumFilter задаёт условия отбора альбомов. Пустые поля не участвуют в фильтрации.
+type AlbumFilter struct {
+	Title string // Подстрока названия альбома без учета регистра
+	Group string // Подстрока названия группы или одного из её альтернативных названий
+}
+
+// AlbumRepository описывает хранилище альбомов и их треклистов.
+type AlbumRepository interface {
+	// List возвращает страницу альбомов по возрастанию ID и общее количество альбомов, подходящих под фильтр.
+	List(ctx context.Context, filter AlbumFilter, offset, limit int) ([]models.Album, int64, error)
+	// Get возвращает альбом по ID или ErrAlbumNotFound.
+	Get(ctx context.Context, id uint) (*models.Album, error)
+	// Create сохраняет новый альбом группы group; группа создаётся, если её ещё нет.
+	Create(ctx context.Context, album *models.Album, group string) error
+	// Update сохраняет изменённый альбом. Если group не пуст, альбом переносится в эту группу,
+	// которая создаётся при отсутствии. Если альбома нет, возвращается ErrAlbumNotFound.
+	Update(ctx context.Context, album *models.Album, group string) error
+	// Delete удаляет альбом вместе с треклистом; сами песни остаются. Если альбома нет, возвращается ErrAlbumNotFound.
+	Delete(ctx context.Context, id uint) error
+	// Tracks возвращает треклист альбома по номеру диска и номеру трека без песен в корзине.
+	// Если альбома нет, возвращается ErrAlbumNotFound.
+	Tracks(ctx context.Context, id uint) ([]models.Track, error)
+	// SetTrack добавляет песню в альбом на позицию track или перемещает её, если песня уже есть в альбоме.
+	// Возвращает ErrAlbumNotFound, ErrNotFound для неизвестной песни или ErrTrackPositionTaken.
+	SetTrack(ctx context.Context, track *models.AlbumTrack) error
+	// DeleteTrack убирает песню из альбома. Если песни нет в треклисте, возвращается ErrTrackNotFound.
+	DeleteTrack(ctx context.Context, albumID, songID uint) error
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"MusicLibrary/database"
+	"MusicLibrary/models"
+	"MusicLibrary/utils"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormAlbumRepository хранит альбомы и треклисты в PostgreSQL или SQLite с помощью GORM.
+type GormAlbumRepository struct {
+	db *gorm.DB
+}
+
+// GormAlbumRepository должно реализовывать AlbumRepository.
+var _ AlbumRepository = (*GormAlbumRepository)(nil)
+
+// NewGormAlbumRepository создаёт хранилище альбомов поверх подключения к базе данных.
+func NewGormAlbumRepository(db *gorm.DB) *GormAlbumRepository {
+	return &GormAlbumRepository{db: db}
+}
+
+// List возвращает страницу альбомов, подходящих под фильтр.
+func (r *GormAlbumRepository) List(ctx context.Context, filter AlbumFilter, offset, limit int) ([]models.Album, int64, error) {
+	db := r.db.WithContext(ctx)
+
+	query := db.Model(&models.Album{})
+	if filter.Title != "" {
+		query = query.Where(database.ILike(db, "title"), "%"+utils.EscapeLike(utils.NormalizeName(filter.Title))+"%")
+	}
+	if filter.Group != "" {
+		pattern := "%" + utils.EscapeLike(utils.NormalizeName(filter.Group)) + "%"
+		aliases := db.Model(&models.GroupAlias{}).Select("\"groupId\"").Where(database.ILike(db, "alias"), pattern)
+		groups := db.Model(&models.Group{}).Select("id").Where(database.ILike(db, "name"), pattern).Or("id IN (?)", aliases)
+		query = query.Where("\"groupId\" IN (?)", groups)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var albums []models.Album
+	if err := query.Order("id").Offset(offset).Limit(limit).Find(&albums).Error; err != nil {
+		return nil, 0, err
+	}
+	return albums, total, nil
+}
+
+// Get возвращает альбом по ID.
+func (r *GormAlbumRepository) Get(ctx context.Context, id uint) (*models.Album, error) {
+	return findAlbum(r.db.WithContext(ctx), id)
+}
+
+// findAlbum читает альбом по ID в tx.
+func findAlbum(tx *gorm.DB, id uint) (*models.Album, error) {
+	var album models.Album
+	if err := tx.First(&album, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAlbumNotFound
+		}
+		return nil, err
+	}
+	return &album, nil
+}
+
+// Create сохраняет альбом и при необходимости создаёт группу в одной транзакции.
+func (r *GormAlbumRepository) Create(ctx context.Context, album *models.Album, group string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		found, err := database.FindOrCreateGroup(tx, group)
+		if err != nil {
+			return err
+		}
+		album.GroupID = found.ID
+		album.Group = found.Name
+		return tx.Create(album).Error
+	})
+}
+
+// Update сохраняет альбом и при необходимости создаёт новую группу в одной транзакции.
+func (r *GormAlbumRepository) Update(ctx context.Context, album *models.Album, group string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := findAlbum(tx, album.ID); err != nil {
+			return err
+		}
+		if group != "" {
+			found, err := database.FindOrCreateGroup(tx, group)
+			if err != nil {
+				return err
+			}
+			album.GroupID = found.ID
+			album.Group = found.Name
+		}
+		return tx.Save(album).Error
+	})
+}
+
+// Delete удаляет альбом. Треклист удаляется каскадно внешним ключом.
+func (r *GormAlbumRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.Album{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlbumNotFound
+	}
+	return nil
+}
+
+// Tracks возвращает упорядоченный треклист альбома.
+func (r *GormAlbumRepository) Tracks(ctx context.Context, id uint) ([]models.Track, error) {
+	db := r.db.WithContext(ctx)
+	if _, err := findAlbum(db, id); err != nil {
+		return nil, err
+	}
+
+	var albumTracks []models.AlbumTrack
+	if err := db.Where("\"albumId\" = ?", id).Order("\"discNumber\", \"trackNumber\"").Find(&albumTracks).Error; err != nil {
+		return nil, err
+	}
+
+	// Загружаем песни треклиста одним запросом и раскладываем их в порядке треков.
+	songIDs := make([]uint, 0, len(albumTracks))
+	for _, track := range albumTracks {
+		songIDs = append(songIDs, track.SongID)
+	}
+	var songs []models.Song
+	if len(songIDs) > 0 {
+		if err := db.Where("id IN ?", songIDs).Find(&songs).Error; err != nil {
+			return nil, err
+		}
+	}
+	songsByID := make(map[uint]models.Song, len(songs))
+	for _, song := range songs {
+		songsByID[song.ID] = song
+	}
+
+	// Песни в корзине не загружаются и в треклисте не показываются
+	tracks := make([]models.Track, 0, len(albumTracks))
+	for _, track := range albumTracks {
+		song, ok := songsByID[track.SongID]
+		if !ok {
+			continue
+		}
+		tracks = append(tracks, models.Track{DiscNumber: track.DiscNumber, TrackNumber: track.TrackNumber, Song: song})
+	}
+	return tracks, nil
+}
+
+// SetTrack сохраняет позицию песни в альбоме.
+func (r *GormAlbumRepository) SetTrack(ctx context.Context, track *models.AlbumTrack) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := findAlbum(tx, track.AlbumID); err != nil {
+			return err
+		}
+		if err := tx.First(&models.Song{}, track.SongID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		// Проверяем, что позиция не занята другой песней.
+		var occupied int64
+		err := tx.Model(&models.AlbumTrack{}).
+			Where("\"albumId\" = ? AND \"discNumber\" = ? AND \"trackNumber\" = ? AND \"songId\" <> ?",
+				track.AlbumID, track.DiscNumber, track.TrackNumber, track.SongID).
+			Count(&occupied).Error
+		if err != nil {
+			return err
+		}
+		if occupied > 0 {
+			return ErrTrackPositionTaken
+		}
+
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "albumId"}, {Name: "songId"}},
+			DoUpdates: clause.AssignmentColumns([]string{"discNumber", "trackNumber"}),
+		}).Create(track).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrTrackPositionTaken
+		}
+		return err
+	})
+}
+
+// DeleteTrack убирает песню из треклиста альбома.
+func (r *GormAlbumRepository) DeleteTrack(ctx context.Context, albumID, songID uint) error {
+	result := r.db.WithContext(ctx).Where("\"albumId\" = ? AND \"songId\" = ?", albumID, songID).Delete(&models.AlbumTrack{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTrackNotFound
+	}
+	return nil
+}
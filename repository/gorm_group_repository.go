@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"MusicLibrary/database"
+	"MusicLibrary/models"
+	"MusicLibrary/utils"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// GormGroupRepository хранит группы в PostgreSQL или SQLite с помощью GORM.
+type GormGroupRepository struct {
+	db *gorm.DB
+}
+
+// GormGroupRepository должно реализовывать GroupRepository.
+var _ GroupRepository = (*GormGroupRepository)(nil)
+
+// NewGormGroupRepository создаёт хранилище групп поверх подключения к базе данных.
+func NewGormGroupRepository(db *gorm.DB) *GormGroupRepository {
+	return &GormGroupRepository{db: db}
+}
+
+// List возвращает страницу групп, отфильтрованных по названию и альтернативным названиям.
+func (r *GormGroupRepository) List(ctx context.Context, name string, offset, limit int) ([]models.Group, int64, error) {
+	db := r.db.WithContext(ctx)
+
+	query := db.Model(&models.Group{})
+	if name != "" {
+		pattern := "%" + utils.EscapeLike(utils.NormalizeName(name)) + "%"
+		aliases := db.Model(&models.GroupAlias{}).Select("\"groupId\"").Where(database.ILike(db, "alias"), pattern)
+		query = query.Where(database.ILike(db, "name"), pattern).Or("id IN (?)", aliases)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var groups []models.Group
+	if err := query.Preload("Aliases").Order("name").Offset(offset).Limit(limit).Find(&groups).Error; err != nil {
+		return nil, 0, err
+	}
+	return groups, total, nil
+}
+
+// Get возвращает группу по ID вместе с альтернативными названиями.
+func (r *GormGroupRepository) Get(ctx context.Context, id uint) (*models.Group, error) {
+	var group models.Group
+	if err := r.db.WithContext(ctx).Preload("Aliases").First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// Create сохраняет новую группу и её альтернативные названия в одной транзакции.
+func (r *GormGroupRepository) Create(ctx context.Context, group *models.Group, aliases []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := database.FindGroupByName(tx, group.Name); err == nil {
+			return ErrGroupExists
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err := tx.Create(group).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrGroupExists
+			}
+			return err
+		}
+		return database.ReplaceGroupAliases(tx, group, aliases)
+	})
+}
+
+// Update сохраняет группу и синхронизирует её название в песнях и альбомах.
+func (r *GormGroupRepository) Update(ctx context.Context, group *models.Group, aliases []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current models.Group
+		if err := tx.First(&current, group.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrGroupNotFound
+			}
+			return err
+		}
+		if owner, err := database.FindGroupByName(tx, group.Name); err == nil && owner.ID != group.ID {
+			return ErrGroupExists
+		} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := tx.Omit("Aliases").Save(group).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrGroupExists
+			}
+			return err
+		}
+		if current.Name != group.Name {
+			if err := renameGroupSongs(tx, group.ID, group.Name); err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Album{}).Where("\"groupId\" = ?", group.ID).Update("group", group.Name).Error; err != nil {
+				return err
+			}
+		}
+		if aliases != nil {
+			return database.ReplaceGroupAliases(tx, group, aliases)
+		}
+		return tx.Where("\"groupId\" = ?", group.ID).Find(&group.Aliases).Error
+	})
+}
+
+// renameGroupSongs переносит новое название группы name в её песни. Название дублируется в песнях,
+// поэтому синхронизируется и у песен в корзине, чтобы после восстановления они показывали актуальное
+// название. Для каждой песни увеличивается версия и записывается версия в историю.
+func renameGroupSongs(tx *gorm.DB, groupID uint, name string) error {
+	var songs []models.Song
+	if err := tx.Unscoped().Where("\"groupId\" = ?", groupID).Order("id").Find(&songs).Error; err != nil {
+		return err
+	}
+	for _, song := range songs {
+		before := song
+		if err := bumpVersion(tx, &song, 0); err != nil {
+			return err
+		}
+		song.Group, song.GroupKey = name, utils.SearchKey(name)
+		err := tx.Unscoped().Model(&models.Song{}).Where("id = ?", song.ID).
+			Updates(map[string]interface{}{"group": song.Group, "groupKey": song.GroupKey}).Error
+		if err != nil {
+			return err
+		}
+		if err := recordRevision(tx, models.RevisionUpdate, before, song); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete удаляет группу без песен и альбомов.
+func (r *GormGroupRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var group models.Group
+		if err := tx.First(&group, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrGroupNotFound
+			}
+			return err
+		}
+
+		var songs, albums int64
+		// Песни в корзине ссылаются на группу, пока не удалены окончательно, поэтому тоже учитываются
+		if err := tx.Unscoped().Model(&models.Song{}).Where("\"groupId\" = ?", id).Count(&songs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Album{}).Where("\"groupId\" = ?", id).Count(&albums).Error; err != nil {
+			return err
+		}
+		if songs > 0 || albums > 0 {
+			return ErrGroupInUse
+		}
+		return tx.Select("Aliases").Delete(&group).Error
+	})
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"MusicLibrary/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GormJobRepository хранит очередь задач в таблице jobs.
+type GormJobRepository struct {
+	db    *gorm.DB
+	songs *GormSongRepository // Песни задач, попытки которых исчерпаны, отмечаются как не обогащённые
+}
+
+// GormJobRepository должно реализовывать JobRepository.
+var _ JobRepository = (*GormJobRepository)(nil)
+
+// NewGormJobRepository создаёт очередь задач поверх подключения к базе данных.
+func NewGormJobRepository(db *gorm.DB) *GormJobRepository {
+	return &GormJobRepository{db: db, songs: NewGormSongRepository(db)}
+}
+
+// Get возвращает задачу по ID.
+func (r *GormJobRepository) Get(ctx context.Context, id uint) (*models.Job, error) {
+	var job models.Job
+	if err := r.db.WithContext(ctx).First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Claim выбирает готовые к выполнению задачи и захватывает каждую условным UPDATE: строка меняется,
+// только если статус и номер попытки не изменились с момента выборки. Так задачу получает ровно один
+// обработчик без блокировок, специфичных для СУБД. Время хранится в UTC, чтобы в SQLite, где оно
+// сохраняется строкой, сравнение строк совпадало со сравнением моментов времени.
+func (r *GormJobRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.Job, error) {
+	db := r.db.WithContext(ctx)
+	now := time.Now().UTC()
+	if err := r.failExhausted(ctx, now); err != nil {
+		return nil, err
+	}
+
+	var candidates []models.Job
+	if err := db.Where("(status = ? AND \"runAt\" <= ?) OR (status = ? AND \"lockedUntil\" < ? AND attempts < \"maxAttempts\")",
+		models.JobQueued, now, models.JobRunning, now).
+		Order("\"runAt\"").Limit(limit).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	claimed := make([]models.Job, 0, len(candidates))
+	lockedUntil := now.Add(lease)
+	for _, job := range candidates {
+		result := db.Model(&models.Job{}).
+			Where("id = ? AND status = ? AND attempts = ?", job.ID, job.Status, job.Attempts).
+			Updates(map[string]interface{}{
+				"status":      models.JobRunning,
+				"attempts":    job.Attempts + 1,
+				"lockedUntil": lockedUntil,
+				"updatedAt":   now,
+			})
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		job.Status, job.Attempts, job.LockedUntil, job.UpdatedAt = models.JobRunning, job.Attempts+1, &lockedUntil, now
+		claimed = append(claimed, job)
+	}
+	return claimed, nil
+}
+
+// failExhausted завершает с ошибкой задачи, обработчик которых остановился во время последней попытки,
+// и отмечает их песни как не обогащённые. Задачу завершает тот же условный UPDATE, что и при захвате,
+// поэтому песню отмечает только один обработчик.
+func (r *GormJobRepository) failExhausted(ctx context.Context, now time.Time) error {
+	db := r.db.WithContext(ctx)
+	var exhausted []models.Job
+	if err := db.Where("status = ? AND \"lockedUntil\" < ? AND attempts >= \"maxAttempts\"", models.JobRunning, now).
+		Find(&exhausted).Error; err != nil {
+		return err
+	}
+
+	for _, job := range exhausted {
+		result := db.Model(&models.Job{}).
+			Where("id = ? AND status = ? AND attempts = ?", job.ID, models.JobRunning, job.Attempts).
+			Updates(map[string]interface{}{
+				"status":      models.JobFailed,
+				"lastError":   leaseExpiredError,
+				"lockedUntil": nil,
+				"updatedAt":   now,
+				"finishedAt":  now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		if err := markEnrichmentFailed(ctx, r.songs, job.SongID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save сохраняет результат попытки, если задача всё ещё захвачена этой попыткой.
+func (r *GormJobRepository) Save(ctx context.Context, job *models.Job) error {
+	job.UpdatedAt = time.Now().UTC()
+	job.RunAt = job.RunAt.UTC()
+	if job.Status != models.JobRunning {
+		job.LockedUntil = nil
+	}
+	result := r.db.WithContext(ctx).Model(&models.Job{}).
+		Where("id = ? AND status = ? AND attempts = ?", job.ID, models.JobRunning, job.Attempts).
+		Updates(map[string]interface{}{
+			"status":      job.Status,
+			"lastError":   job.LastError,
+			"runAt":       job.RunAt,
+			"lockedUntil": job.LockedUntil,
+			"updatedAt":   job.UpdatedAt,
+			"finishedAt":  job.FinishedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrJobLost
+	}
+	return nil
+}
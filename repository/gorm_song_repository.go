@@ -0,0 +1,574 @@
+package repository
+
+import (
+	"MusicLibrary/database"
+	"MusicLibrary/models"
+	"MusicLibrary/utils"
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// songSortColumns сопоставляет поля сортировки с выражениями SQL.
+// Песни без даты выпуска при сортировке считаются самыми ранними.
+var songSortColumns = map[string]string{
+	"id":          "id",
+	"group":       "\"group\"",
+	"song":        "song",
+	"releaseDate": "COALESCE(\"releaseDate\", '0001-01-01')",
+}
+
+// GormSongRepository хранит песни в PostgreSQL или SQLite с помощью GORM.
+type GormSongRepository struct {
+	db *gorm.DB
+}
+
+// GormSongRepository должно реализовывать SongRepository.
+var _ SongRepository = (*GormSongRepository)(nil)
+
+// NewGormSongRepository создаёт хранилище песен поверх подключения к базе данных.
+func NewGormSongRepository(db *gorm.DB) *GormSongRepository {
+	return &GormSongRepository{db: db}
+}
+
+// List возвращает страницу песен и общее количество песен, подходящих под фильтр.
+func (r *GormSongRepository) List(ctx context.Context, query SongListQuery) ([]models.Song, int64, error) {
+	db := r.db.WithContext(ctx)
+
+	// Количество считается до добавления сортировки, иначе PostgreSQL отвергнет ORDER BY в COUNT
+	var total int64
+	if err := applySongFilter(db, db.Model(&models.Song{}), query.Filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	songs := []models.Song{}
+	selection := applySongFilter(db, db.Model(&models.Song{}), query.Filter)
+	if query.After != nil {
+		selection = songsAfter(selection, query.Sort, query.After)
+	}
+	selection = orderSongs(selection, query.Sort)
+	if query.Offset > 0 {
+		selection = selection.Offset(query.Offset)
+	}
+	if query.Limit > 0 {
+		selection = selection.Limit(query.Limit)
+	}
+	if err := selection.Find(&songs).Error; err != nil {
+		return nil, 0, err
+	}
+	return songs, total, nil
+}
+
+// eachBatchSize — количество песен, которые Each читает из базы данных одним запросом.
+const eachBatchSize = 500
+
+// Each перебирает песни, подходящие под фильтр, порциями по eachBatchSize. Следующая порция выбирается
+// по ключу последней песни предыдущей, как при пагинации курсором, поэтому соединение с базой данных
+// не удерживается, пока fn обрабатывает песни.
+func (r *GormSongRepository) Each(ctx context.Context, filter SongFilter, sort []SortField, fn func(models.Song) error) error {
+	db := r.db.WithContext(ctx)
+
+	var after *SongKeyset
+	for {
+		selection := applySongFilter(db, db.Model(&models.Song{}), filter)
+		if after != nil {
+			selection = songsAfter(selection, sort, after)
+		}
+		selection = orderSongs(selection, sort)
+
+		var songs []models.Song
+		if err := selection.Limit(eachBatchSize).Find(&songs).Error; err != nil {
+			return err
+		}
+		for _, song := range songs {
+			if err := fn(song); err != nil {
+				return err
+			}
+		}
+		if len(songs) < eachBatchSize {
+			return nil
+		}
+
+		last := songs[len(songs)-1]
+		after = &SongKeyset{ID: last.ID}
+		for _, field := range sort {
+			if field.Name != "id" {
+				after.Values = append(after.Values, SongSortValue(field.Name, last))
+			}
+		}
+	}
+}
+
+// Get возвращает песню по ID.
+func (r *GormSongRepository) Get(ctx context.Context, id uint) (*models.Song, error) {
+	var song models.Song
+	if err := r.db.WithContext(ctx).First(&song, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &song, nil
+}
+
+// Create сохраняет новую песню вместе с группой, если она ещё не существует.
+func (r *GormSongRepository) Create(ctx context.Context, song *models.Song) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createSong(tx, song)
+	})
+}
+
+// CreateWithJob сохраняет новую песню и задачу для неё в одной транзакции.
+func (r *GormSongRepository) CreateWithJob(ctx context.Context, song *models.Song, job *models.Job) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := createSong(tx, song); err != nil {
+			return err
+		}
+		job.SongID = song.ID
+		return tx.Create(job).Error
+	})
+}
+
+// createSong сохраняет песню в транзакции tx и записывает её первую версию в историю.
+func createSong(tx *gorm.DB, song *models.Song) error {
+	var group *models.Group
+	if song.Group == "" && song.GroupID != 0 {
+		group = &models.Group{}
+		if err := tx.First(group, song.GroupID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrGroupNotFound
+			}
+			return err
+		}
+	} else {
+		var err error
+		if group, err = database.FindOrCreateGroup(tx, song.Group); err != nil {
+			return err
+		}
+	}
+	explicitID := song.ID != 0
+	song.GroupID = group.ID
+	song.Group = group.Name
+	song.GroupKey = utils.SearchKey(group.Name)
+	song.SongKey = utils.SearchKey(song.Song)
+	song.Version = 1
+	if song.EnrichmentStatus == "" {
+		song.EnrichmentStatus = models.EnrichmentEnriched
+	}
+	if err := tx.Create(song).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicate
+		}
+		return err
+	}
+	if explicitID {
+		if err := database.SyncIDSequence(tx, "songs"); err != nil {
+			return err
+		}
+	}
+	return recordRevision(tx, models.RevisionCreate, models.Song{}, *song)
+}
+
+// Update применяет к песне изменения patch и возвращает её, заново прочитанную из базы данных.
+func (r *GormSongRepository) Update(ctx context.Context, id uint, patch models.SongPatch, expected uint) (*models.Song, error) {
+	var song models.Song
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&song, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		before := song
+		if expected != 0 && song.Version != expected {
+			return ErrVersionMismatch
+		}
+
+		// Изменения собираются в map, чтобы пустые значения тоже записывались: так поля очищаются
+		updates := make(map[string]interface{})
+
+		// Привязка песни к группе: по названию (с созданием группы при необходимости) или по ID
+		if patch.Group != nil {
+			group, err := database.FindOrCreateGroup(tx, *patch.Group)
+			if err != nil {
+				return err
+			}
+			updates["groupId"] = group.ID
+			updates["group"] = group.Name
+			updates["groupKey"] = utils.SearchKey(group.Name)
+		} else if patch.GroupID != nil {
+			var group models.Group
+			if err := tx.First(&group, *patch.GroupID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrGroupNotFound
+				}
+				return err
+			}
+			updates["groupId"] = group.ID
+			updates["group"] = group.Name
+			updates["groupKey"] = utils.SearchKey(group.Name)
+		}
+
+		// Поисковый ключ пересчитывается при изменении названия
+		if patch.Song != nil {
+			updates["song"] = *patch.Song
+			updates["songKey"] = utils.SearchKey(*patch.Song)
+		}
+		if patch.ReleaseDate != nil {
+			updates["releaseDate"] = *patch.ReleaseDate
+		}
+		if patch.Text != nil {
+			updates["text"] = *patch.Text
+		}
+		if patch.Link != nil {
+			updates["link"] = *patch.Link
+		}
+		if patch.EnrichmentStatus != nil {
+			updates["enrichmentStatus"] = *patch.EnrichmentStatus
+		}
+
+		if len(updates) > 0 {
+			if err := tx.Model(&models.Song{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+				if errors.Is(err, gorm.ErrDuplicatedKey) {
+					return ErrDuplicate
+				}
+				return err
+			}
+		}
+
+		// Песня читается в пустую структуру: GORM не обнуляет поля, для которых в базе NULL
+		song = models.Song{}
+		if err := tx.First(&song, id).Error; err != nil {
+			return err
+		}
+		if sources := songSources(before, song, patch.EnrichmentSources); !maps.Equal(sources, song.EnrichmentSources) {
+			if err := tx.Model(&models.Song{}).Where("id = ?", id).Update("enrichmentSources", sources).Error; err != nil {
+				return err
+			}
+			song.EnrichmentSources = sources
+		}
+
+		// Запрос без фактических изменений не меняет версию и не попадает в историю, поэтому повторная
+		// отправка тех же данных ничего не меняет. Версия сверяется с прочитанной в начале транзакции:
+		// если песню успели изменить, изменения откатываются.
+		// Смена только статуса обогащения меняет версию, чтобы устарел ETag, но в историю не попадает
+		changed := len(changedSongFields(before, song)) > 0
+		if !changed && song.EnrichmentStatus == before.EnrichmentStatus {
+			return nil
+		}
+		song.Version = before.Version
+		if err := bumpVersion(tx, &song, 0); err != nil {
+			return err
+		}
+		if !changed {
+			return nil
+		}
+		return recordRevision(tx, models.RevisionUpdate, before, song)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &song, nil
+}
+
+// Delete перемещает песню в корзину, заполняя столбец deletedAt.
+func (r *GormSongRepository) Delete(ctx context.Context, id uint, expected uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var song models.Song
+		if err := tx.First(&song, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if err := bumpVersion(tx, &song, expected); err != nil {
+			return err
+		}
+		if err := tx.Delete(&song).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, models.RevisionDelete, song, song)
+	})
+}
+
+// ListDeleted возвращает страницу песен в корзине.
+func (r *GormSongRepository) ListDeleted(ctx context.Context, offset, limit int) ([]models.Song, int64, error) {
+	query := r.db.WithContext(ctx).Unscoped().Model(&models.Song{}).Where("\"deletedAt\" IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var songs []models.Song
+	if err := query.Order("\"deletedAt\" DESC, id DESC").Offset(offset).Limit(limit).Find(&songs).Error; err != nil {
+		return nil, 0, err
+	}
+	return songs, total, nil
+}
+
+// Restore возвращает песню из корзины, очищая столбец deletedAt.
+func (r *GormSongRepository) Restore(ctx context.Context, id uint) (*models.Song, error) {
+	var song models.Song
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("\"deletedAt\" IS NOT NULL").First(&song, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if err := bumpVersion(tx, &song, 0); err != nil {
+			return err
+		}
+		// Пока песня была в корзине, у группы могла появиться песня с тем же названием
+		if err := tx.Unscoped().Model(&song).Update("deletedAt", nil).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrDuplicate
+			}
+			return err
+		}
+		if err := tx.First(&song, id).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, models.RevisionRestore, song, song)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &song, nil
+}
+
+// Purge окончательно удаляет песню из корзины. Позиции в треклистах и история версий удаляются каскадно внешними ключами.
+func (r *GormSongRepository) Purge(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Unscoped().Where("\"deletedAt\" IS NOT NULL").Delete(&models.Song{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PurgeDeletedBefore окончательно удаляет песни, перемещённые в корзину раньше before.
+func (r *GormSongRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().Where("\"deletedAt\" < ?", before).Delete(&models.Song{})
+	return result.RowsAffected, result.Error
+}
+
+// ExistsByGroupAndTitle проверяет, есть ли у группы песня с таким названием.
+// Группа ищется по нормализованному названию и альтернативным названиям.
+func (r *GormSongRepository) ExistsByGroupAndTitle(ctx context.Context, group, title string) (bool, error) {
+	_, err := r.FindByGroupAndTitle(ctx, group, title)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// FindByGroupAndTitle возвращает песню группы с таким названием без учета регистра.
+func (r *GormSongRepository) FindByGroupAndTitle(ctx context.Context, group, title string) (*models.Song, error) {
+	db := r.db.WithContext(ctx)
+
+	found, err := database.FindGroupByName(db, group)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Find вместо First: отсутствие песни — обычный результат проверки, а не ошибка в журнале запросов
+	var song models.Song
+	result := db.Where("\"groupId\" = ? AND "+database.Lower(db, "song")+" = "+database.Lower(db, "?"), found.ID, utils.NormalizeName(title)).
+		Limit(1).Find(&song)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrNotFound
+	}
+	return &song, nil
+}
+
+// Suggest подбирает похожие названия группы и песни с помощью триграмм pg_trgm.
+// В SQLite триграммы недоступны, поэтому сходство названий вычисляется в Go.
+func (r *GormSongRepository) Suggest(ctx context.Context, filter SongFilter) (*models.SongSuggestion, error) {
+	db := r.db.WithContext(ctx)
+	if database.IsSQLite(db) {
+		return r.suggestInGo(db, filter)
+	}
+	var suggestion models.SongSuggestion
+
+	if filter.Group != "" {
+		var names []string
+		if err := db.Raw(`SELECT name FROM (SELECT name FROM groups UNION SELECT alias FROM group_aliases) AS names
+WHERE name % ? ORDER BY similarity(name, ?) DESC, name LIMIT 1`, filter.Group, filter.Group).Scan(&names).Error; err != nil {
+			return nil, err
+		}
+		if len(names) > 0 && utils.NameKey(names[0]) != utils.NameKey(filter.Group) {
+			suggestion.Group = names[0]
+		}
+	}
+
+	if filter.Song != "" {
+		var titles []string
+		if err := db.Model(&models.Song{}).
+			Where("song % ?", filter.Song).
+			Order(clause.OrderBy{Expression: clause.Expr{SQL: "similarity(song, ?) DESC, song", Vars: []interface{}{filter.Song}}}).
+			Limit(1).
+			Pluck("song", &titles).Error; err != nil {
+			return nil, err
+		}
+		if len(titles) > 0 && utils.NameKey(titles[0]) != utils.NameKey(filter.Song) {
+			suggestion.Song = titles[0]
+		}
+	}
+
+	if suggestion.Group == "" && suggestion.Song == "" {
+		return nil, nil
+	}
+	return &suggestion, nil
+}
+
+// suggestInGo подбирает похожие названия, сравнивая триграммы всех названий групп и песен в Go.
+func (r *GormSongRepository) suggestInGo(db *gorm.DB, filter SongFilter) (*models.SongSuggestion, error) {
+	var suggestion models.SongSuggestion
+
+	if filter.Group != "" {
+		var names []string
+		if err := db.Raw(`SELECT name FROM groups UNION SELECT alias FROM group_aliases`).Scan(&names).Error; err != nil {
+			return nil, err
+		}
+		suggestion.Group = mostSimilar(filter.Group, names)
+	}
+
+	if filter.Song != "" {
+		var titles []string
+		if err := db.Model(&models.Song{}).Distinct("song").Pluck("song", &titles).Error; err != nil {
+			return nil, err
+		}
+		suggestion.Song = mostSimilar(filter.Song, titles)
+	}
+
+	if suggestion.Group == "" && suggestion.Song == "" {
+		return nil, nil
+	}
+	return &suggestion, nil
+}
+
+// applySongFilter добавляет условия фильтра к запросу по таблице songs.
+// Подзапросы строятся от db, чтобы не разделять состояние с основным запросом.
+// Сравнение без учета регистра зависит от СУБД и строится функцией database.ILike.
+func applySongFilter(db, query *gorm.DB, f SongFilter) *gorm.DB {
+	// Символы % и _ в значениях фильтров сравниваются буквально
+	contains := func(value string) string { return "%" + utils.EscapeLike(value) + "%" }
+	keyLike := " LIKE ?" + database.LikeEscape(db)
+
+	if f.Group != "" {
+		// Группа ищется по каноническому и альтернативным названиям, а также по поисковому ключу,
+		// поэтому "Kino" находит "Кино", а "Mumiy Troll" — "Мумий Тролль"
+		pattern := contains(f.Group)
+		aliases := db.Model(&models.GroupAlias{}).Select("\"groupId\"").Where(database.ILike(db, "alias"), pattern)
+		groups := db.Model(&models.Group{}).Select("id").Where(database.ILike(db, "name"), pattern).Or("id IN (?)", aliases)
+		condition := db.Where("\"groupId\" IN (?)", groups)
+		if key := utils.SearchKey(f.Group); key != "" {
+			condition = condition.Or("\"groupKey\""+keyLike, contains(key))
+		}
+		query = query.Where(condition)
+	}
+	if f.Song != "" {
+		condition := db.Where(database.ILike(db, "song"), contains(f.Song))
+		if key := utils.SearchKey(f.Song); key != "" {
+			condition = condition.Or("\"songKey\""+keyLike, contains(key))
+		}
+		query = query.Where(condition)
+	}
+	if f.Album != "" {
+		// Песни, входящие хотя бы в один альбом с подходящим названием
+		albums := db.Model(&models.Album{}).Select("id").Where(database.ILike(db, "title"), contains(f.Album))
+		tracks := db.Model(&models.AlbumTrack{}).Select("\"songId\"").Where("\"albumId\" IN (?)", albums)
+		query = query.Where("id IN (?)", tracks)
+	}
+	if !f.ReleaseDate.IsZero() {
+		query = query.Where("\"releaseDate\" = ?", f.ReleaseDate)
+	}
+	if !f.ReleasedFrom.IsZero() {
+		query = query.Where("\"releaseDate\" >= ?", f.ReleasedFrom)
+	}
+	if !f.ReleasedBefore.IsZero() {
+		query = query.Where("\"releaseDate\" < ?", f.ReleasedBefore)
+	}
+	return query
+}
+
+// songsAfter добавляет к запросу условие, отбирающее песни, следующие за позицией keyset в порядке sort:
+// (f1 > v1) OR (f1 = v1 AND f2 > v2) OR ..., где для полей с сортировкой по убыванию используется "<".
+func songsAfter(query *gorm.DB, sort []SortField, keyset *SongKeyset) *gorm.DB {
+	values := make([]interface{}, len(sort))
+	valueIndex := 0
+	for i, field := range sort {
+		if field.Name == "id" {
+			values[i] = keyset.ID
+		} else {
+			values[i] = keyset.Values[valueIndex]
+			valueIndex++
+		}
+	}
+
+	var conditions []string
+	var args []interface{}
+	for i, field := range sort {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, songSortColumns[sort[j].Name]+" = ?")
+			args = append(args, values[j])
+		}
+		operator := " > ?"
+		if field.Desc {
+			operator = " < ?"
+		}
+		parts = append(parts, songSortColumns[field.Name]+operator)
+		args = append(args, values[i])
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+	return query.Where(strings.Join(conditions, " OR "), args...)
+}
+
+// orderSongs добавляет к запросу сортировку песен по полям sort.
+func orderSongs(query *gorm.DB, sort []SortField) *gorm.DB {
+	for _, field := range sort {
+		if field.Desc {
+			query = query.Order(songSortColumns[field.Name] + " DESC")
+		} else {
+			query = query.Order(songSortColumns[field.Name] + " ASC")
+		}
+	}
+	return query
+}
+
+// bumpVersion увеличивает версию песни в транзакции её изменения. Условие на текущую версию
+// в запросе UPDATE не даёт двум одновременным изменениям пройти проверку с одной и той же версией:
+// второе не найдёт строку и получит ErrVersionMismatch. Если expected не равен нулю,
+// версия песни должна ему соответствовать.
+func bumpVersion(tx *gorm.DB, song *models.Song, expected uint) error {
+	if expected != 0 && song.Version != expected {
+		return ErrVersionMismatch
+	}
+	result := tx.Unscoped().Model(&models.Song{}).
+		Where("id = ? AND version = ?", song.ID, song.Version).
+		UpdateColumn("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionMismatch
+	}
+	song.Version++
+	return nil
+}
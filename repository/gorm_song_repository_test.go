@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"MusicLibrary/database"
+	"MusicLibrary/models"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// openSQLite создаёт базу данных SQLite во временном каталоге теста и выполняет миграцию схемы.
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	t.Setenv("DB_DRIVER", database.DriverSQLite)
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "test.db"))
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	db := database.Init(logger)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestGormListAfterKeyset(t *testing.T) {
+	testListAfterKeyset(t, NewGormSongRepository(openSQLite(t)))
+}
+
+func TestGormListFilter(t *testing.T) {
+	testListFilter(t, NewGormSongRepository(openSQLite(t)))
+}
+
+func TestGormListFilterLikeCharacters(t *testing.T) {
+	testListFilterLikeCharacters(t, NewGormSongRepository(openSQLite(t)))
+}
+
+func TestGormDuplicate(t *testing.T) {
+	testDuplicate(t, NewGormSongRepository(openSQLite(t)))
+}
+
+func TestGormTrash(t *testing.T) {
+	testTrash(t, NewGormSongRepository(openSQLite(t)))
+}
+
+func TestGormRevisions(t *testing.T) {
+	testRevisions(t, NewGormSongRepository(openSQLite(t)))
+}
+
+func TestGormVersion(t *testing.T) {
+	testVersion(t, NewGormSongRepository(openSQLite(t)))
+}
+
+func TestGormUpdatePatch(t *testing.T) {
+	testUpdatePatch(t, NewGormSongRepository(openSQLite(t)))
+}
+
+func TestGormCreateWithID(t *testing.T) {
+	testCreateWithID(t, NewGormSongRepository(openSQLite(t)))
+}
+
+func TestGormFindByGroupAndTitle(t *testing.T) {
+	testFindByGroupAndTitle(t, NewGormSongRepository(openSQLite(t)))
+}
+
+func TestGormEach(t *testing.T) {
+	testEach(t, NewGormSongRepository(openSQLite(t)))
+}
+
+func TestGormEachBatches(t *testing.T) {
+	db := openSQLite(t)
+	songs := NewGormSongRepository(db)
+	ctx := context.Background()
+	if err := songs.Create(ctx, &models.Song{Group: "Muse", Song: "0000"}); err != nil {
+		t.Fatal(err)
+	}
+	// Песни добавляются в обход репозитория, чтобы не создавать сотни ревизий
+	batch := make([]models.Song, 0, 2*eachBatchSize)
+	for i := 1; i < 2*eachBatchSize+10; i++ {
+		batch = append(batch, models.Song{GroupID: 1, Song: fmt.Sprintf("%04d", i), Version: 1})
+	}
+	if err := db.Omit("Group").CreateInBatches(batch, 100).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	prev := ""
+	err := songs.Each(ctx, SongFilter{}, []SortField{{Name: "song", Desc: true}, {Name: "id"}}, func(song models.Song) error {
+		if prev != "" && song.Song >= prev {
+			return fmt.Errorf("song %q after %q", song.Song, prev)
+		}
+		prev = song.Song
+		count++
+		return nil
+	})
+	if err != nil || count != 2*eachBatchSize+10 {
+		t.Errorf("visited %d songs, error %v, want %d", count, err, 2*eachBatchSize+10)
+	}
+}
+
+func TestGormGroupRenameRecordsRevisions(t *testing.T) {
+	db := openSQLite(t)
+	testGroupRenameRecordsRevisions(t, NewGormSongRepository(db), NewGormGroupRepository(db))
+}
+
+func TestGormRejectedChangeKeepsGroups(t *testing.T) {
+	db := openSQLite(t)
+	testRejectedChangeKeepsGroups(t, NewGormSongRepository(db), NewGormGroupRepository(db))
+}
+
+func TestGormUpdateEnrichmentSources(t *testing.T) {
+	testUpdateEnrichmentSources(t, NewGormSongRepository(openSQLite(t)))
+}
+
+// newGormJobQueue создаёт очередь задач в базе данных SQLite.
+func newGormJobQueue(t *testing.T) (SongRepository, JobRepository) {
+	db := openSQLite(t)
+	return NewGormSongRepository(db), NewGormJobRepository(db)
+}
+
+func TestGormJobClaim(t *testing.T) {
+	testJobClaim(t, newGormJobQueue)
+}
+
+func TestGormJobLeaseExpiry(t *testing.T) {
+	testJobLeaseExpiry(t, newGormJobQueue)
+}
+
+func TestGormJobExhausted(t *testing.T) {
+	testJobExhausted(t, newGormJobQueue)
+}
+
+func TestGormPurgeRemovesAlbumTracks(t *testing.T) {
+	db := openSQLite(t)
+	songs := NewGormSongRepository(db)
+	ctx := context.Background()
+	if err := songs.Create(ctx, &models.Song{Group: "Muse", Song: "Hysteria"}); err != nil {
+		t.Fatal(err)
+	}
+	album := models.Album{Title: "Absolution", GroupID: 1, Group: "Muse"}
+	if err := db.Create(&album).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.AlbumTrack{AlbumID: album.ID, SongID: 1, DiscNumber: 1, TrackNumber: 8}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := songs.Delete(ctx, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	var tracks int64
+	if err := db.Model(&models.AlbumTrack{}).Count(&tracks).Error; err != nil || tracks != 1 {
+		t.Fatalf("%d album tracks after moving the song to trash, error %v, want 1", tracks, err)
+	}
+	if err := songs.Purge(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&models.AlbumTrack{}).Count(&tracks).Error; err != nil || tracks != 0 {
+		t.Errorf("%d album tracks after purging the song, error %v, want 0", tracks, err)
+	}
+}
+
+func TestGormListAlbumFilter(t *testing.T) {
+	db := openSQLite(t)
+	songs := NewGormSongRepository(db)
+	ctx := context.Background()
+	for _, title := range []string{"Hysteria", "Uprising"} {
+		if err := songs.Create(ctx, &models.Song{Group: "Muse", Song: title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	album := models.Album{Title: "Absolution", GroupID: 1, Group: "Muse"}
+	if err := db.Create(&album).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.AlbumTrack{AlbumID: album.ID, SongID: 1, DiscNumber: 1, TrackNumber: 8}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for filter, want := range map[string]string{"absolution": "Hysteria", "ABSOL": "Hysteria", "Resistance": ""} {
+		page, _, err := songs.List(ctx, SongListQuery{Filter: SongFilter{Album: filter}, Sort: []SortField{{Name: "id"}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := listTitles(page); got != want {
+			t.Errorf("album %q: songs %q, want %q", filter, got, want)
+		}
+	}
+}
+
+func TestGormSuggestSQLite(t *testing.T) {
+	songs := NewGormSongRepository(openSQLite(t))
+	ctx := context.Background()
+	if err := songs.Create(ctx, &models.Song{Group: "Muse", Song: "Hysteria"}); err != nil {
+		t.Fatal(err)
+	}
+
+	suggestion, err := songs.Suggest(ctx, SongFilter{Group: "Musee", Song: "Hysterya"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestion == nil || *suggestion != (models.SongSuggestion{Group: "Muse", Song: "Hysteria"}) {
+		t.Errorf("suggestion %+v, want Muse and Hysteria", suggestion)
+	}
+}
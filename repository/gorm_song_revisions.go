@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"MusicLibrary/database"
+	"MusicLibrary/models"
+	"MusicLibrary/utils"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recordRevision записывает в историю версию песни after, полученную действием action из before.
+// Вызывается в транзакции изменения, поэтому изменение и его версия сохраняются вместе.
+// Автор изменения берётся из контекста транзакции.
+func recordRevision(tx *gorm.DB, action string, before, after models.Song) error {
+	var last int
+	if err := tx.Model(&models.SongRevision{}).
+		Select("COALESCE(MAX(revision), 0)").
+		Where("\"songId\" = ?", after.ID).
+		Scan(&last).Error; err != nil {
+		return err
+	}
+
+	snapshot := after
+	return tx.Create(&models.SongRevision{
+		SongID:        after.ID,
+		Revision:      last + 1,
+		Action:        action,
+		ChangedFields: changedSongFields(before, after),
+		Actor:         ActorFromContext(tx.Statement.Context),
+		CreatedAt:     time.Now(),
+		Snapshot:      &snapshot,
+	}).Error
+}
+
+// ListRevisions возвращает страницу версий песни без снимков.
+func (r *GormSongRepository) ListRevisions(ctx context.Context, songID uint, offset, limit int) ([]models.SongRevision, int64, error) {
+	db := r.db.WithContext(ctx)
+	if err := db.Unscoped().Select("id").First(&models.Song{}, songID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, ErrNotFound
+		}
+		return nil, 0, err
+	}
+
+	query := db.Model(&models.SongRevision{}).Where("\"songId\" = ?", songID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	revisions := []models.SongRevision{}
+	if err := query.Omit("snapshot").Order("revision DESC").Offset(offset).Limit(limit).Find(&revisions).Error; err != nil {
+		return nil, 0, err
+	}
+	return revisions, total, nil
+}
+
+// GetRevision возвращает версию песни со снимком.
+func (r *GormSongRepository) GetRevision(ctx context.Context, songID uint, revision int) (*models.SongRevision, error) {
+	var found models.SongRevision
+	if err := r.db.WithContext(ctx).Where("\"songId\" = ? AND revision = ?", songID, revision).First(&found).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return &found, nil
+}
+
+// Revert возвращает поля песни к снимку версии. В отличие от Update пустые значения снимка
+// тоже применяются, поэтому песня полностью совпадает с сохранённой версией, включая источники полей.
+func (r *GormSongRepository) Revert(ctx context.Context, songID uint, revision int) (*models.Song, error) {
+	var song models.Song
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&song, songID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		before := song
+		if err := bumpVersion(tx, &song, 0); err != nil {
+			return err
+		}
+
+		var target models.SongRevision
+		if err := tx.Where("\"songId\" = ? AND revision = ?", songID, revision).First(&target).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRevisionNotFound
+			}
+			return err
+		}
+		snapshot := target.Snapshot
+		if snapshot == nil {
+			return ErrRevisionNotFound
+		}
+
+		// Группа из снимка могла быть переименована, тогда песня получает её текущее название.
+		// Если группы больше нет, она создаётся заново по названию из снимка.
+		var group models.Group
+		err := tx.First(&group, snapshot.GroupID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			created, createErr := database.FindOrCreateGroup(tx, snapshot.Group)
+			if createErr != nil {
+				return createErr
+			}
+			group, err = *created, nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&song).Updates(map[string]interface{}{
+			"groupId":           group.ID,
+			"group":             group.Name,
+			"groupKey":          utils.SearchKey(group.Name),
+			"song":              snapshot.Song,
+			"songKey":           utils.SearchKey(snapshot.Song),
+			"releaseDate":       snapshot.ReleaseDate,
+			"text":              snapshot.Text,
+			"link":              snapshot.Link,
+			"enrichmentSources": snapshot.EnrichmentSources,
+		}).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrDuplicate
+			}
+			return err
+		}
+		// Песня читается в пустую структуру: GORM не обнуляет поля, для которых в базе NULL
+		song = models.Song{}
+		if err := tx.First(&song, songID).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, models.RevisionRevert, before, song)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &song, nil
+}
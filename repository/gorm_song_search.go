@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"MusicLibrary/database"
+	"MusicLibrary/models"
+	"MusicLibrary/utils"
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// searchSongsQuery выбирает песни, подходящие под поисковый запрос, вместе с релевантностью и
+// куплетом, лучше всего соответствующим запросу. Текст делится на куплеты по пустой строке, как в GetSongVerses.
+const searchSongsQuery = `SELECT songs.*,
+	ts_rank_cd(songs."searchVector", q.query) AS rank,
+	COALESCE((
+		SELECT ts_headline('russian', verse, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')
+		FROM regexp_split_to_table(songs.text, E'\n\n') AS verse
+		WHERE to_tsvector('russian', verse) @@ q.query
+		ORDER BY ts_rank_cd(to_tsvector('russian', verse), q.query) DESC
+		LIMIT 1
+	), '') AS snippet
+FROM songs, websearch_to_tsquery('russian', ?) AS q(query)
+WHERE songs."searchVector" @@ q.query AND songs."deletedAt" IS NULL
+ORDER BY rank DESC, songs.id
+LIMIT ? OFFSET ?`
+
+// fuzzySongsQuery выбирает песни, название или группа которых похожи на запрос с точностью до опечаток.
+// Оператор <% использует GIN-индексы по триграммам, сходство считается по наиболее похожему фрагменту строки.
+const fuzzySongsQuery = `SELECT songs.*,
+	GREATEST(word_similarity(?, "group"), word_similarity(?, song)) AS score
+FROM songs
+WHERE (? <% "group" OR ? <% song) AND "deletedAt" IS NULL
+ORDER BY score DESC, songs.id
+LIMIT ? OFFSET ?`
+
+// autocompleteColumns сопоставляет поля автодополнения со столбцами таблицы songs.
+var autocompleteColumns = map[string]string{
+	"group": "\"group\"",
+	"song":  "song",
+}
+
+// Search выполняет полнотекстовый поиск по столбцу searchVector. Полнотекстовый поиск есть только в PostgreSQL.
+func (r *GormSongRepository) Search(ctx context.Context, query string, offset, limit int) ([]models.SongSearchHit, int64, error) {
+	db := r.db.WithContext(ctx)
+	if database.IsSQLite(db) {
+		return nil, 0, ErrNotSupported
+	}
+
+	var total int64
+	if err := db.Model(&models.Song{}).
+		Where("\"searchVector\" @@ websearch_to_tsquery('russian', ?)", query).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		models.Song
+		Rank    float64
+		Snippet string
+	}
+	if err := db.Raw(searchSongsQuery, query, limit, offset).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]models.SongSearchHit, 0, len(rows))
+	for _, row := range rows {
+		hits = append(hits, models.SongSearchHit{Song: row.Song, Rank: row.Rank, Snippet: row.Snippet})
+	}
+	return hits, total, nil
+}
+
+// FuzzySearch ищет похожие названия песен и групп с помощью триграмм pg_trgm, которые есть только в PostgreSQL.
+func (r *GormSongRepository) FuzzySearch(ctx context.Context, query string, offset, limit int) ([]models.SongSimilarityHit, int64, error) {
+	db := r.db.WithContext(ctx)
+	if database.IsSQLite(db) {
+		return nil, 0, ErrNotSupported
+	}
+
+	var total int64
+	if err := db.Model(&models.Song{}).
+		Where("? <% \"group\" OR ? <% song", query, query).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		models.Song
+		Score float64
+	}
+	if err := db.Raw(fuzzySongsQuery, query, query, query, query, limit, offset).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]models.SongSimilarityHit, 0, len(rows))
+	for _, row := range rows {
+		hits = append(hits, models.SongSimilarityHit{Song: row.Song, Score: row.Score})
+	}
+	return hits, total, nil
+}
+
+// Autocomplete подбирает названия одним запросом с группировкой по названию. Начало всего названия
+// ищется по индексу lower(...) text_pattern_ops, начало слова внутри названия — по триграммному индексу.
+func (r *GormSongRepository) Autocomplete(ctx context.Context, field, prefix string, limit int) ([]models.Suggestion, error) {
+	column, ok := autocompleteColumns[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown autocomplete field %q", field)
+	}
+	db := r.db.WithContext(ctx)
+
+	escaped := utils.EscapeLike(strings.ToLower(prefix))
+	startsWith := database.Lower(db, column) + " LIKE ?" + database.LikeEscape(db)
+	suggestions := []models.Suggestion{}
+	err := db.Model(&models.Song{}).
+		Select(fmt.Sprintf("%s AS value, COUNT(*) AS popularity", column)).
+		Where(startsWith, escaped+"%").
+		Or(database.ILike(db, column), "% "+escaped+"%").
+		// Group(column) экранировал бы имя столбца в кавычках ещё раз
+		Clauses(clause.GroupBy{Columns: []clause.Column{{Name: column, Raw: true}}}).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  fmt.Sprintf("MIN(CASE WHEN %s THEN 0 ELSE 1 END), popularity DESC, value", startsWith),
+			Vars: []interface{}{escaped + "%"},
+		}}).
+		Limit(limit).
+		Scan(&suggestions).Error
+	if err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"MusicLibrary/database"
+	"MusicLibrary/models"
+	"context"
+	"errors"
+)
+
+// ErrGroupExists возвращается, если название группы уже принадлежит другой группе.
+var ErrGroupExists = errors.New("group already exists")
+
+// ErrGroupInUse возвращается при удалении группы, у которой есть песни (в том числе в корзине) или альбомы.
+var ErrGroupInUse = errors.New("group has songs or albums")
+
+// ErrAliasTaken возвращается, если альтернативное название уже принадлежит другой группе.
+var ErrAliasTaken = database.ErrAliasTaken
+
+// GroupRepository описывает хранилище групп. Названия групп сравниваются без учета регистра
+// и лишних пробелов (см. utils.NameKey), в том числе с альтернативными названиями других групп.
+type GroupRepository interface {
+	// List возвращает страницу групп по алфавиту вместе с альтернативными названиями и общее количество
+	// групп, название или одно из альтернативных названий которых содержит name. Пустой name не фильтрует.
+	List(ctx context.Context, name string, offset, limit int) ([]models.Group, int64, error)
+	// Get возвращает группу по ID вместе с альтернативными названиями или ErrGroupNotFound.
+	Get(ctx context.Context, id uint) (*models.Group, error)
+	// Create сохраняет новую группу с альтернативными названиями aliases.
+	// Если название занято, возвращается ErrGroupExists, если занято альтернативное название — ErrAliasTaken.
+	Create(ctx context.Context, group *models.Group, aliases []string) error
+	// Update сохраняет изменённую группу. Новое название переносится в песни и альбомы группы;
+	// у каждой песни при этом увеличивается версия, а изменение записывается в её историю.
+	// Если aliases не nil, список альтернативных названий заменяется целиком. Ошибки те же, что у Create,
+	// а также ErrGroupNotFound. После сохранения group содержит актуальные альтернативные названия.
+	Update(ctx context.Context, group *models.Group, aliases []string) error
+	// Delete удаляет группу вместе с альтернативными названиями. Если у группы есть песни или альбомы,
+	// возвращается ErrGroupInUse, если группы нет — ErrGroupNotFound.
+	Delete(ctx context.Context, id uint) error
+}
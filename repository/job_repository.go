@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"MusicLibrary/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrJobNotFound возвращается, если запрошенной задачи нет в очереди.
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrJobLost возвращается при сохранении результата задачи, которую после истечения срока захвата
+// забрал другой обработчик. Результат такой попытки не сохраняется.
+var ErrJobLost = errors.New("job was claimed by another worker")
+
+// JobRepository описывает очередь фоновых задач. Задачи ставятся в очередь вместе с данными,
+// к которым относятся (см. SongRepository.CreateWithJob), а обработчики забирают их методом Claim.
+type JobRepository interface {
+	// Get возвращает задачу по ID или ErrJobNotFound.
+	Get(ctx context.Context, id uint) (*models.Job, error)
+	// Claim захватывает до limit задач, время попытки которых наступило, а также задачи, чей срок захвата
+	// истёк (например, обработчик остановился, не завершив попытку). Захваченные задачи получают статус
+	// running, номер следующей попытки и срок захвата now+lease. Одну задачу не захватят два обработчика,
+	// в том числе в разных экземплярах приложения. Задача с истёкшим сроком захвата, у которой исчерпаны
+	// все MaxAttempts попыток, повторно не захватывается: она завершается с ошибкой, а её песне ставится
+	// статус обогащения failed.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]models.Job, error)
+	// Save сохраняет статус, время следующей попытки и ошибку захваченной задачи.
+	// Если задачу уже захватил другой обработчик, возвращается ErrJobLost.
+	Save(ctx context.Context, job *models.Job) error
+}
+
+// leaseExpiredError — ошибка задачи, обработчик которой остановился во время последней попытки.
+const leaseExpiredError = "Lease expired during the last attempt"
+
+// markEnrichmentFailed отмечает песню songID как не обогащённую, когда попытки её задачи исчерпаны.
+// Песня, удалённая в корзину, пропускается.
+func markEnrichmentFailed(ctx context.Context, songs SongRepository, songID uint) error {
+	status := models.EnrichmentFailed
+	if _, err := songs.Update(ctx, songID, models.SongPatch{EnrichmentStatus: &status}, 0); err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to mark song ID: %d as not enriched: %w", songID, err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/utils"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MemoryAlbumRepository хранит альбомы и треклисты в памяти процесса. Треклисты ссылаются на песни,
+// поэтому хранилище альбомов работает поверх хранилища песен. Предназначено для тестов.
+type MemoryAlbumRepository struct {
+	songs *MemorySongRepository
+}
+
+// MemoryAlbumRepository должно реализовывать AlbumRepository.
+var _ AlbumRepository = (*MemoryAlbumRepository)(nil)
+
+// NewMemoryAlbumRepository создаёт хранилище альбомов песен из songs.
+func NewMemoryAlbumRepository(songs *MemorySongRepository) *MemoryAlbumRepository {
+	return &MemoryAlbumRepository{songs: songs}
+}
+
+// List возвращает страницу альбомов, подходящих под фильтр.
+func (r *MemoryAlbumRepository) List(ctx context.Context, filter AlbumFilter, offset, limit int) ([]models.Album, int64, error) {
+	r.songs.mu.RLock()
+	defer r.songs.mu.RUnlock()
+
+	title := strings.ToLower(utils.NormalizeName(filter.Title))
+	group := strings.ToLower(utils.NormalizeName(filter.Group))
+	var matched []models.Album
+	for _, album := range r.songs.albums {
+		if !strings.Contains(strings.ToLower(album.Title), title) {
+			continue
+		}
+		if group != "" && !groupMatches(r.songs.groups[album.GroupID], group) {
+			continue
+		}
+		matched = append(matched, album)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+
+	albums := []models.Album{}
+	for i := offset; i < len(matched) && len(albums) < limit; i++ {
+		albums = append(albums, matched[i])
+	}
+	return albums, int64(len(matched)), nil
+}
+
+// Get возвращает альбом по ID.
+func (r *MemoryAlbumRepository) Get(ctx context.Context, id uint) (*models.Album, error) {
+	r.songs.mu.RLock()
+	defer r.songs.mu.RUnlock()
+
+	album, ok := r.songs.albums[id]
+	if !ok {
+		return nil, ErrAlbumNotFound
+	}
+	return &album, nil
+}
+
+// Create сохраняет альбом, создавая группу при отсутствии.
+func (r *MemoryAlbumRepository) Create(ctx context.Context, album *models.Album, group string) error {
+	r.songs.mu.Lock()
+	defer r.songs.mu.Unlock()
+
+	album.GroupID, album.Group = r.songs.findOrCreateGroup(group)
+	album.ID = r.songs.nextAlbumID
+	r.songs.nextAlbumID++
+	r.songs.albums[album.ID] = *album
+	return nil
+}
+
+// Update сохраняет альбом, при необходимости перенося его в другую группу.
+func (r *MemoryAlbumRepository) Update(ctx context.Context, album *models.Album, group string) error {
+	r.songs.mu.Lock()
+	defer r.songs.mu.Unlock()
+
+	if _, ok := r.songs.albums[album.ID]; !ok {
+		return ErrAlbumNotFound
+	}
+	if group != "" {
+		album.GroupID, album.Group = r.songs.findOrCreateGroup(group)
+	}
+	r.songs.albums[album.ID] = *album
+	return nil
+}
+
+// Delete удаляет альбом вместе с треклистом.
+func (r *MemoryAlbumRepository) Delete(ctx context.Context, id uint) error {
+	r.songs.mu.Lock()
+	defer r.songs.mu.Unlock()
+
+	if _, ok := r.songs.albums[id]; !ok {
+		return ErrAlbumNotFound
+	}
+	delete(r.songs.albums, id)
+	delete(r.songs.tracks, id)
+	return nil
+}
+
+// Tracks возвращает упорядоченный треклист альбома без песен в корзине.
+func (r *MemoryAlbumRepository) Tracks(ctx context.Context, id uint) ([]models.Track, error) {
+	r.songs.mu.RLock()
+	defer r.songs.mu.RUnlock()
+
+	if _, ok := r.songs.albums[id]; !ok {
+		return nil, ErrAlbumNotFound
+	}
+	tracks := []models.Track{}
+	for _, track := range r.songs.tracks[id] {
+		if song, ok := r.songs.songs[track.SongID]; ok {
+			tracks = append(tracks, models.Track{DiscNumber: track.DiscNumber, TrackNumber: track.TrackNumber, Song: song})
+		}
+	}
+	sort.Slice(tracks, func(i, j int) bool {
+		if tracks[i].DiscNumber != tracks[j].DiscNumber {
+			return tracks[i].DiscNumber < tracks[j].DiscNumber
+		}
+		return tracks[i].TrackNumber < tracks[j].TrackNumber
+	})
+	return tracks, nil
+}
+
+// SetTrack сохраняет позицию песни в альбоме.
+func (r *MemoryAlbumRepository) SetTrack(ctx context.Context, track *models.AlbumTrack) error {
+	r.songs.mu.Lock()
+	defer r.songs.mu.Unlock()
+
+	if _, ok := r.songs.albums[track.AlbumID]; !ok {
+		return ErrAlbumNotFound
+	}
+	if _, ok := r.songs.songs[track.SongID]; !ok {
+		return ErrNotFound
+	}
+
+	tracks := r.songs.tracks[track.AlbumID]
+	for _, existing := range tracks {
+		if existing.SongID != track.SongID && existing.DiscNumber == track.DiscNumber && existing.TrackNumber == track.TrackNumber {
+			return ErrTrackPositionTaken
+		}
+	}
+	tracks = slices.DeleteFunc(tracks, func(existing models.AlbumTrack) bool { return existing.SongID == track.SongID })
+	r.songs.tracks[track.AlbumID] = append(tracks, *track)
+	return nil
+}
+
+// DeleteTrack убирает песню из треклиста альбома.
+func (r *MemoryAlbumRepository) DeleteTrack(ctx context.Context, albumID, songID uint) error {
+	r.songs.mu.Lock()
+	defer r.songs.mu.Unlock()
+
+	tracks := r.songs.tracks[albumID]
+	remaining := slices.DeleteFunc(slices.Clone(tracks), func(track models.AlbumTrack) bool { return track.SongID == songID })
+	if len(remaining) == len(tracks) {
+		return ErrTrackNotFound
+	}
+	r.songs.tracks[albumID] = remaining
+	return nil
+}
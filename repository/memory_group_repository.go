@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/utils"
+	"context"
+	"sort"
+	"strings"
+)
+
+// MemoryGroupRepository хранит группы в памяти процесса. Группы создаются и при добавлении песен,
+// поэтому хранилище групп работает поверх хранилища песен. Предназначено для тестов.
+type MemoryGroupRepository struct {
+	songs *MemorySongRepository
+}
+
+// MemoryGroupRepository должно реализовывать GroupRepository.
+var _ GroupRepository = (*MemoryGroupRepository)(nil)
+
+// NewMemoryGroupRepository создаёт хранилище групп песен из songs.
+func NewMemoryGroupRepository(songs *MemorySongRepository) *MemoryGroupRepository {
+	return &MemoryGroupRepository{songs: songs}
+}
+
+// List возвращает страницу групп, отфильтрованных по названию и альтернативным названиям.
+func (r *MemoryGroupRepository) List(ctx context.Context, name string, offset, limit int) ([]models.Group, int64, error) {
+	r.songs.mu.RLock()
+	defer r.songs.mu.RUnlock()
+
+	name = strings.ToLower(utils.NormalizeName(name))
+	var matched []models.Group
+	for _, group := range r.songs.groups {
+		if groupMatches(group, name) {
+			matched = append(matched, group)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].Name != matched[j].Name {
+			return matched[i].Name < matched[j].Name
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	groups := []models.Group{}
+	for i := offset; i < len(matched) && len(groups) < limit; i++ {
+		groups = append(groups, matched[i])
+	}
+	return groups, int64(len(matched)), nil
+}
+
+// groupMatches проверяет, содержит ли название группы или одно из альтернативных названий подстроку name
+// в нижнем регистре.
+func groupMatches(group models.Group, name string) bool {
+	if strings.Contains(strings.ToLower(group.Name), name) {
+		return true
+	}
+	for _, alias := range group.Aliases {
+		if strings.Contains(strings.ToLower(alias.Alias), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get возвращает группу по ID.
+func (r *MemoryGroupRepository) Get(ctx context.Context, id uint) (*models.Group, error) {
+	r.songs.mu.RLock()
+	defer r.songs.mu.RUnlock()
+
+	group, ok := r.songs.groups[id]
+	if !ok {
+		return nil, ErrGroupNotFound
+	}
+	return &group, nil
+}
+
+// Create сохраняет новую группу с альтернативными названиями.
+func (r *MemoryGroupRepository) Create(ctx context.Context, group *models.Group, aliases []string) error {
+	r.songs.mu.Lock()
+	defer r.songs.mu.Unlock()
+
+	if _, ok := r.songs.findGroupByName(group.Name); ok {
+		return ErrGroupExists
+	}
+	created := *group
+	created.ID = r.songs.nextGroupID
+	if err := r.replaceAliases(&created, aliases); err != nil {
+		return err
+	}
+	r.songs.nextGroupID++
+	r.songs.groups[created.ID] = created
+	*group = created
+	return nil
+}
+
+// Update сохраняет группу и переносит её название в песни и альбомы группы.
+func (r *MemoryGroupRepository) Update(ctx context.Context, group *models.Group, aliases []string) error {
+	r.songs.mu.Lock()
+	defer r.songs.mu.Unlock()
+
+	current, ok := r.songs.groups[group.ID]
+	if !ok {
+		return ErrGroupNotFound
+	}
+	if owner, ok := r.songs.findGroupByName(group.Name); ok && owner.ID != group.ID {
+		return ErrGroupExists
+	}
+	updated := *group
+	updated.Aliases = current.Aliases
+	if aliases != nil {
+		if err := r.replaceAliases(&updated, aliases); err != nil {
+			return err
+		}
+	}
+	r.songs.groups[updated.ID] = updated
+
+	if current.Name != updated.Name {
+		// Название синхронизируется и у песен в корзине, а каждая песня получает новую версию, как в GormGroupRepository
+		for _, songs := range []map[uint]models.Song{r.songs.songs, r.songs.trash} {
+			for id, song := range songs {
+				if song.GroupID != updated.ID {
+					continue
+				}
+				before := song
+				song.Group, song.GroupKey = updated.Name, utils.SearchKey(updated.Name)
+				song.Version++
+				songs[id] = song
+				r.songs.recordRevision(ctx, models.RevisionUpdate, before, song)
+			}
+		}
+		for id, album := range r.songs.albums {
+			if album.GroupID == updated.ID {
+				album.Group = updated.Name
+				r.songs.albums[id] = album
+			}
+		}
+	}
+	*group = updated
+	return nil
+}
+
+// replaceAliases заменяет альтернативные названия группы по правилам database.ReplaceGroupAliases.
+// Вызывается при удерживаемой блокировке на запись.
+func (r *MemoryGroupRepository) replaceAliases(group *models.Group, aliases []string) error {
+	group.Aliases = []models.GroupAlias{}
+	seen := map[string]bool{group.NameKey: true}
+	for _, alias := range aliases {
+		key := utils.NameKey(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if owner, ok := r.songs.findGroupByName(alias); ok && owner.ID != group.ID {
+			return ErrAliasTaken
+		}
+		group.Aliases = append(group.Aliases, models.GroupAlias{GroupID: group.ID, Alias: utils.NormalizeName(alias), AliasKey: key})
+	}
+	return nil
+}
+
+// Delete удаляет группу без песен и альбомов.
+func (r *MemoryGroupRepository) Delete(ctx context.Context, id uint) error {
+	r.songs.mu.Lock()
+	defer r.songs.mu.Unlock()
+
+	if _, ok := r.songs.groups[id]; !ok {
+		return ErrGroupNotFound
+	}
+	for _, songs := range []map[uint]models.Song{r.songs.songs, r.songs.trash} {
+		for _, song := range songs {
+			if song.GroupID == id {
+				return ErrGroupInUse
+			}
+		}
+	}
+	for _, album := range r.songs.albums {
+		if album.GroupID == id {
+			return ErrGroupInUse
+		}
+	}
+	delete(r.songs.groups, id)
+	return nil
+}
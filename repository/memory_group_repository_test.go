@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"MusicLibrary/models"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryGroupRenameRecordsRevisions(t *testing.T) {
+	songs := NewMemorySongRepository()
+	testGroupRenameRecordsRevisions(t, songs, NewMemoryGroupRepository(songs))
+}
+
+// testGroupRenameRecordsRevisions проверяет, что переименование группы в groups записывает ревизию
+// каждой её песни в пустом хранилище songs, в том числе песни в корзине.
+func testGroupRenameRecordsRevisions(t *testing.T, songs SongRepository, groups GroupRepository) {
+	ctx := context.Background()
+	for _, title := range []string{"Hysteria", "Uprising"} {
+		if err := songs.Create(ctx, &models.Song{Group: "Muse", Song: title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := songs.Delete(ctx, 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	group, err := groups.Get(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Изменение без смены названия не затрагивает песни
+	group.Country = "UK"
+	if err := groups.Update(ctx, group, nil); err != nil {
+		t.Fatal(err)
+	}
+	if song, _ := songs.Get(ctx, 1); song.Version != 1 {
+		t.Fatalf("song version %d after updating the group country, want 1", song.Version)
+	}
+
+	group.Name = "MUSE"
+	if err := groups.Update(ctx, group, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		id          uint
+		wantVersion uint
+		wantHistory int
+	}{
+		{id: 1, wantVersion: 2, wantHistory: 2},
+		{id: 2, wantVersion: 3, wantHistory: 3}, // Песня в корзине
+	} {
+		revisions, total, err := songs.ListRevisions(ctx, tt.id, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != int64(tt.wantHistory) {
+			t.Fatalf("song %d: %d revisions, want %d", tt.id, total, tt.wantHistory)
+		}
+		latest := revisions[0]
+		if latest.Action != models.RevisionUpdate || !reflect.DeepEqual(latest.ChangedFields, []string{"group"}) {
+			t.Errorf("song %d: latest revision %s of %v, want update of [group]", tt.id, latest.Action, latest.ChangedFields)
+		}
+		revision, err := songs.GetRevision(ctx, tt.id, latest.Revision)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revision.Snapshot.Group != "MUSE" || revision.Snapshot.Version != tt.wantVersion {
+			t.Errorf("song %d: snapshot group %q version %d, want MUSE version %d", tt.id, revision.Snapshot.Group, revision.Snapshot.Version, tt.wantVersion)
+		}
+	}
+}
+
+func TestMemoryRejectedChangeKeepsGroups(t *testing.T) {
+	songs := NewMemorySongRepository()
+	testRejectedChangeKeepsGroups(t, songs, NewMemoryGroupRepository(songs))
+}
+
+// testRejectedChangeKeepsGroups проверяет, что отклонённые создание и изменение песни в пустом хранилище
+// songs не оставляют в groups новую группу, указанную в запросе.
+func testRejectedChangeKeepsGroups(t *testing.T, songs SongRepository, groups GroupRepository) {
+	ctx := context.Background()
+	if err := songs.Create(ctx, &models.Song{Group: "Muse", Song: "Hysteria"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rejected := []struct {
+		name   string
+		change func() error
+		want   error
+	}{
+		{name: "create with a taken ID", change: func() error {
+			return songs.Create(ctx, &models.Song{ID: 1, Group: "Blur", Song: "Song 2"})
+		}, want: ErrDuplicate},
+		{name: "update an outdated version", change: func() error {
+			_, err := songs.Update(ctx, 1, models.SongPatch{Group: ptr("Blur")}, 2)
+			return err
+		}, want: ErrVersionMismatch},
+		{name: "update a missing song", change: func() error {
+			_, err := songs.Update(ctx, 42, models.SongPatch{Group: ptr("Blur")}, 0)
+			return err
+		}, want: ErrNotFound},
+		{name: "update to a missing group ID", change: func() error {
+			_, err := songs.Update(ctx, 1, models.SongPatch{GroupID: ptr(uint(42))}, 0)
+			return err
+		}, want: ErrGroupNotFound},
+	}
+	for _, tt := range rejected {
+		if err := tt.change(); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	list, total, err := groups.List(ctx, "", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(list) != 1 || list[0].Name != "Muse" {
+		t.Errorf("groups %+v (total %d), want only Muse", list, total)
+	}
+}
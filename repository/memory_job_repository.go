@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"MusicLibrary/models"
+	"context"
+	"sort"
+	"time"
+)
+
+// MemoryJobRepository — очередь задач в памяти процесса. Задачи ставит в очередь MemorySongRepository.CreateWithJob,
+// поэтому очередь создаётся поверх хранилища песен. Предназначено для тестов.
+type MemoryJobRepository struct {
+	songs *MemorySongRepository
+}
+
+// MemoryJobRepository должно реализовывать JobRepository.
+var _ JobRepository = (*MemoryJobRepository)(nil)
+
+// NewMemoryJobRepository создаёт очередь задач, поставленных в хранилище песен songs.
+func NewMemoryJobRepository(songs *MemorySongRepository) *MemoryJobRepository {
+	return &MemoryJobRepository{songs: songs}
+}
+
+// Get возвращает задачу по ID.
+func (r *MemoryJobRepository) Get(ctx context.Context, id uint) (*models.Job, error) {
+	r.songs.mu.RLock()
+	defer r.songs.mu.RUnlock()
+
+	job, ok := r.songs.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return &job, nil
+}
+
+// Claim захватывает до limit готовых к выполнению задач в порядке времени попытки.
+func (r *MemoryJobRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.Job, error) {
+	claimed, exhausted := r.claim(limit, lease)
+
+	// Песни обновляются после снятия блокировки: MemorySongRepository.Update берёт её сам
+	for _, job := range exhausted {
+		if err := markEnrichmentFailed(ctx, r.songs, job.SongID); err != nil {
+			return claimed, err
+		}
+	}
+	return claimed, nil
+}
+
+// claim захватывает готовые задачи и завершает с ошибкой задачи, обработчик которых остановился
+// во время последней попытки. Возвращает захваченные и завершённые задачи.
+func (r *MemoryJobRepository) claim(limit int, lease time.Duration) (claimed, exhausted []models.Job) {
+	r.songs.mu.Lock()
+	defer r.songs.mu.Unlock()
+
+	now := time.Now()
+	for _, job := range r.songs.jobs {
+		queued := job.Status == models.JobQueued && !job.RunAt.After(now)
+		expired := job.Status == models.JobRunning && job.LockedUntil != nil && job.LockedUntil.Before(now)
+		switch {
+		case expired && job.Attempts >= job.MaxAttempts:
+			job.Status, job.LastError, job.LockedUntil = models.JobFailed, leaseExpiredError, nil
+			job.UpdatedAt, job.FinishedAt = now, &now
+			r.songs.jobs[job.ID] = job
+			exhausted = append(exhausted, job)
+		case queued || expired:
+			claimed = append(claimed, job)
+		}
+	}
+	sort.Slice(claimed, func(i, j int) bool { return claimed[i].RunAt.Before(claimed[j].RunAt) })
+	if len(claimed) > limit {
+		claimed = claimed[:limit]
+	}
+
+	lockedUntil := now.Add(lease)
+	for i := range claimed {
+		claimed[i].Status, claimed[i].LockedUntil, claimed[i].UpdatedAt = models.JobRunning, &lockedUntil, now
+		claimed[i].Attempts++
+		r.songs.jobs[claimed[i].ID] = claimed[i]
+	}
+	return claimed, exhausted
+}
+
+// Save сохраняет результат попытки, если задача всё ещё захвачена этой попыткой.
+func (r *MemoryJobRepository) Save(ctx context.Context, job *models.Job) error {
+	r.songs.mu.Lock()
+	defer r.songs.mu.Unlock()
+
+	current, ok := r.songs.jobs[job.ID]
+	if !ok || current.Status != models.JobRunning || current.Attempts != job.Attempts {
+		return ErrJobLost
+	}
+	job.UpdatedAt = time.Now()
+	if job.Status != models.JobRunning {
+		job.LockedUntil = nil
+	}
+	r.songs.jobs[job.ID] = *job
+	return nil
+}
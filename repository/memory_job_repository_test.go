@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"MusicLibrary/models"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newJobQueue создаёт пустые хранилище песен и очередь задач поверх него.
+type newJobQueue func(t *testing.T) (SongRepository, JobRepository)
+
+// newMemoryJobQueue создаёт очередь задач в памяти.
+func newMemoryJobQueue(t *testing.T) (SongRepository, JobRepository) {
+	songs := NewMemorySongRepository()
+	return songs, NewMemoryJobRepository(songs)
+}
+
+// queueJobs ставит в очередь по задаче обогащения для новой песни на каждое время попытки из runAt.
+func queueJobs(t *testing.T, songs SongRepository, runAt ...time.Time) {
+	t.Helper()
+	for i, at := range runAt {
+		song := models.Song{Group: "Muse", Song: fmt.Sprintf("Song %d", i+1), EnrichmentStatus: models.EnrichmentPending}
+		job := models.Job{Type: models.JobEnrichSong, Status: models.JobQueued, MaxAttempts: 3, RunAt: at}
+		if err := songs.CreateWithJob(context.Background(), &song, &job); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// jobIDs возвращает ID задач в порядке их следования.
+func jobIDs(jobs []models.Job) []uint {
+	ids := make([]uint, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	return ids
+}
+
+func TestMemoryJobClaim(t *testing.T) {
+	testJobClaim(t, newMemoryJobQueue)
+}
+
+// testJobClaim проверяет порядок и исключительность захвата задач в очереди, создаваемой newQueue.
+func testJobClaim(t *testing.T, newQueue newJobQueue) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		runAt []time.Time
+		limit int
+		want  string
+	}{
+		{name: "in order of run time", runAt: []time.Time{now.Add(-time.Second), now.Add(-time.Minute), now.Add(-time.Hour)}, limit: 5, want: "[3 2 1]"},
+		{name: "limit", runAt: []time.Time{now.Add(-time.Hour), now.Add(-time.Minute), now.Add(-time.Second)}, limit: 2, want: "[1 2]"},
+		{name: "not yet due", runAt: []time.Time{now.Add(time.Hour), now.Add(-time.Minute)}, limit: 5, want: "[2]"},
+		{name: "empty queue", limit: 5, want: "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			songs, jobs := newQueue(t)
+			queueJobs(t, songs, tt.runAt...)
+
+			claimed, err := jobs.Claim(context.Background(), tt.limit, time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(jobIDs(claimed)); got != tt.want {
+				t.Fatalf("claimed jobs %s, want %s", got, tt.want)
+			}
+			for _, job := range claimed {
+				if job.Status != models.JobRunning || job.Attempts != 1 || job.LockedUntil == nil {
+					t.Errorf("claimed job %+v should be running its first attempt under a lease", job)
+				}
+			}
+
+			// Захваченные задачи не достаются другому обработчику, пока не истёк срок захвата
+			again, err := jobs.Claim(context.Background(), tt.limit, time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, job := range again {
+				for _, first := range claimed {
+					if job.ID == first.ID {
+						t.Errorf("job %d was claimed twice", job.ID)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryJobLeaseExpiry(t *testing.T) {
+	testJobLeaseExpiry(t, newMemoryJobQueue)
+}
+
+// testJobLeaseExpiry проверяет повторный захват задачи после истечения срока и отказ сохранить результат
+// потерянной попытки в очереди, создаваемой newQueue.
+func testJobLeaseExpiry(t *testing.T, newQueue newJobQueue) {
+	songs, jobs := newQueue(t)
+	queueJobs(t, songs, time.Now().Add(-time.Second))
+	ctx := context.Background()
+
+	// Обработчик остановился, не завершив попытку: срок захвата уже истёк
+	stalled, err := jobs.Claim(ctx, 1, -time.Second)
+	if err != nil || len(stalled) != 1 {
+		t.Fatalf("claimed %v, error %v, want one job", jobIDs(stalled), err)
+	}
+	reclaimed, err := jobs.Claim(ctx, 1, time.Minute)
+	if err != nil || len(reclaimed) != 1 {
+		t.Fatalf("reclaimed %v, error %v, want the stalled job", jobIDs(reclaimed), err)
+	}
+	if reclaimed[0].Attempts != 2 {
+		t.Errorf("reclaimed job attempts %d, want 2", reclaimed[0].Attempts)
+	}
+
+	// Результат остановившейся попытки отбрасывается, результат новой сохраняется
+	stalled[0].Status = models.JobSucceeded
+	if err := jobs.Save(ctx, &stalled[0]); !errors.Is(err, ErrJobLost) {
+		t.Errorf("saving the stalled attempt: error %v, want %v", err, ErrJobLost)
+	}
+	reclaimed[0].Status = models.JobSucceeded
+	if err := jobs.Save(ctx, &reclaimed[0]); err != nil {
+		t.Fatal(err)
+	}
+	job, err := jobs.Get(ctx, reclaimed[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != models.JobSucceeded || job.LockedUntil != nil {
+		t.Errorf("saved job %+v, want a succeeded job without a lease", job)
+	}
+
+	// Завершённую задачу сохранить повторно нельзя
+	if err := jobs.Save(ctx, job); !errors.Is(err, ErrJobLost) {
+		t.Errorf("saving a finished job: error %v, want %v", err, ErrJobLost)
+	}
+	if _, err := jobs.Get(ctx, 42); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("missing job: error %v, want %v", err, ErrJobNotFound)
+	}
+}
+
+func TestMemoryJobExhausted(t *testing.T) {
+	testJobExhausted(t, newMemoryJobQueue)
+}
+
+// testJobExhausted проверяет, что задача, обработчик которой остановился во время последней попытки,
+// не захватывается снова, а завершается с ошибкой вместе с обогащением её песни, в очереди, создаваемой newQueue.
+func testJobExhausted(t *testing.T, newQueue newJobQueue) {
+	songs, jobs := newQueue(t)
+	ctx := context.Background()
+	queueJobs(t, songs, time.Now().Add(-time.Hour), time.Now().Add(-time.Second))
+
+	// Все три попытки первой задачи остановились, не завершившись
+	for attempt := 1; attempt <= 3; attempt++ {
+		claimed, err := jobs.Claim(ctx, 1, -time.Second)
+		if err != nil || len(claimed) != 1 || claimed[0].ID != 1 || claimed[0].Attempts != attempt {
+			t.Fatalf("claim %d: %+v, error %v, want attempt %d of job 1", attempt, claimed, err, attempt)
+		}
+	}
+
+	claimed, err := jobs.Claim(ctx, 5, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(jobIDs(claimed)); got != "[2]" {
+		t.Fatalf("claimed jobs %s, want only the job with attempts left", got)
+	}
+	job, err := jobs.Get(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != models.JobFailed || job.Attempts != 3 || job.LockedUntil != nil || job.FinishedAt == nil || job.LastError == "" {
+		t.Errorf("exhausted job %+v, want a failed job after 3 attempts", job)
+	}
+
+	for _, tt := range []struct {
+		id          uint
+		wantStatus  string
+		wantVersion uint
+	}{
+		{id: 1, wantStatus: models.EnrichmentFailed, wantVersion: 2},
+		{id: 2, wantStatus: models.EnrichmentPending, wantVersion: 1},
+	} {
+		song, err := songs.Get(ctx, tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if song.EnrichmentStatus != tt.wantStatus || song.Version != tt.wantVersion {
+			t.Errorf("song %d: enrichment status %q, version %d, want %q, version %d", tt.id, song.EnrichmentStatus, song.Version, tt.wantStatus, tt.wantVersion)
+		}
+	}
+
+	// Завершённая задача не захватывается и не отмечает песню повторно
+	if claimed, err := jobs.Claim(ctx, 5, time.Minute); err != nil || len(claimed) != 0 {
+		t.Errorf("claimed %v, error %v, want no jobs", jobIDs(claimed), err)
+	}
+	if song, err := songs.Get(ctx, 1); err != nil || song.Version != 2 {
+		t.Errorf("song 1 after another claim: %+v, error %v, want version 2", song, err)
+	}
+}
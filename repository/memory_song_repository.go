@@ -0,0 +1,719 @@
+package repository
+
+import (
+	"MusicLibrary/models"
+	"MusicLibrary/utils"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// suggestSimilarityThreshold — минимальное сходство названий для подсказки,
+// совпадает с порогом pg_trgm.similarity_threshold по умолчанию.
+const suggestSimilarityThreshold = 0.3
+
+// MemorySongRepository хранит песни в памяти процесса. Предназначено для тестов.
+// Группы и альбомы хранятся здесь же (см. NewMemoryGroupRepository и NewMemoryAlbumRepository),
+// но фильтр песен по альбому не поддерживается и не находит ни одной песни.
+type MemorySongRepository struct {
+	mu          sync.RWMutex
+	songs       map[uint]models.Song
+	trash       map[uint]models.Song           // Удалённые песни по ID
+	groups      map[uint]models.Group          // Группы с альтернативными названиями по ID
+	albums      map[uint]models.Album          // Альбомы по ID, см. NewMemoryAlbumRepository
+	tracks      map[uint][]models.AlbumTrack   // Треклисты по ID альбома
+	revisions   map[uint][]models.SongRevision // История версий по ID песни, от старых к новым
+	jobs        map[uint]models.Job            // Очередь задач, см. NewMemoryJobRepository
+	nextSongID  uint
+	nextGroupID uint
+	nextAlbumID uint
+	nextJobID   uint
+}
+
+// MemorySongRepository должно реализовывать SongRepository.
+var _ SongRepository = (*MemorySongRepository)(nil)
+
+// NewMemorySongRepository создаёт пустое хранилище песен в памяти.
+func NewMemorySongRepository() *MemorySongRepository {
+	return &MemorySongRepository{
+		songs:       make(map[uint]models.Song),
+		trash:       make(map[uint]models.Song),
+		revisions:   make(map[uint][]models.SongRevision),
+		groups:      make(map[uint]models.Group),
+		albums:      make(map[uint]models.Album),
+		tracks:      make(map[uint][]models.AlbumTrack),
+		jobs:        make(map[uint]models.Job),
+		nextSongID:  1,
+		nextGroupID: 1,
+		nextAlbumID: 1,
+		nextJobID:   1,
+	}
+}
+
+// List возвращает страницу песен и общее количество песен, подходящих под фильтр.
+func (r *MemorySongRepository) List(ctx context.Context, query SongListQuery) ([]models.Song, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []models.Song
+	for _, song := range r.songs {
+		if matchesSongFilter(song, query.Filter) {
+			matched = append(matched, song)
+		}
+	}
+	total := int64(len(matched))
+
+	sort.Slice(matched, func(i, j int) bool {
+		return compareSongs(matched[i], matched[j], query.Sort) < 0
+	})
+
+	if query.After != nil {
+		start := len(matched)
+		for i, song := range matched {
+			if compareSongToKeyset(song, query.After, query.Sort) > 0 {
+				start = i
+				break
+			}
+		}
+		matched = matched[start:]
+	}
+
+	songs := []models.Song{}
+	for i, song := range matched {
+		if i < query.Offset {
+			continue
+		}
+		if query.Limit > 0 && len(songs) == query.Limit {
+			break
+		}
+		songs = append(songs, song)
+	}
+	return songs, total, nil
+}
+
+// Each вызывает fn для каждой песни, подходящей под фильтр. Песни копируются под блокировкой,
+// а fn вызывается уже без неё, чтобы fn могла обращаться к хранилищу.
+func (r *MemorySongRepository) Each(ctx context.Context, filter SongFilter, sort []SortField, fn func(models.Song) error) error {
+	songs, _, err := r.List(ctx, SongListQuery{Filter: filter, Sort: sort})
+	if err != nil {
+		return err
+	}
+	for _, song := range songs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(song); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get возвращает песню по ID.
+func (r *MemorySongRepository) Get(ctx context.Context, id uint) (*models.Song, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	song, ok := r.songs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &song, nil
+}
+
+// Create сохраняет новую песню, создавая группу при отсутствии.
+func (r *MemorySongRepository) Create(ctx context.Context, song *models.Song) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if song.ID != 0 {
+		_, exists := r.songs[song.ID]
+		_, trashed := r.trash[song.ID]
+		if exists || trashed {
+			return ErrDuplicate
+		}
+	}
+
+	var group models.Group
+	if song.Group == "" && song.GroupID != 0 {
+		found, ok := r.groups[song.GroupID]
+		if !ok {
+			return ErrGroupNotFound
+		}
+		group = found
+	} else {
+		group = r.resolveGroup(song.Group)
+	}
+	if r.hasSong(group.ID, song.Song, 0) {
+		return ErrDuplicate
+	}
+	r.saveGroup(&group)
+	song.GroupID, song.Group = group.ID, group.Name
+	song.GroupKey = utils.SearchKey(song.Group)
+	song.SongKey = utils.SearchKey(song.Song)
+	if song.ID == 0 {
+		song.ID = r.nextSongID
+	}
+	if song.ID >= r.nextSongID {
+		r.nextSongID = song.ID + 1
+	}
+	song.Version = 1
+	if song.EnrichmentStatus == "" {
+		song.EnrichmentStatus = models.EnrichmentEnriched
+	}
+	r.songs[song.ID] = *song
+	r.recordRevision(ctx, models.RevisionCreate, models.Song{}, *song)
+	return nil
+}
+
+// CreateWithJob сохраняет новую песню и ставит задачу для неё в очередь этого хранилища.
+func (r *MemorySongRepository) CreateWithJob(ctx context.Context, song *models.Song, job *models.Job) error {
+	if err := r.Create(ctx, song); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	job.ID, job.SongID, job.CreatedAt, job.UpdatedAt = r.nextJobID, song.ID, now, now
+	r.nextJobID++
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+// Update применяет к песне изменения patch и возвращает её обновлённую версию.
+func (r *MemorySongRepository) Update(ctx context.Context, id uint, patch models.SongPatch, expected uint) (*models.Song, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	song, ok := r.songs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if expected != 0 && song.Version != expected {
+		return nil, ErrVersionMismatch
+	}
+
+	// Новая группа сохраняется только после всех проверок, чтобы отклонённое изменение её не оставило
+	group := models.Group{ID: song.GroupID}
+	if patch.Group != nil {
+		group = r.resolveGroup(*patch.Group)
+		song.GroupID, song.Group = group.ID, group.Name
+		song.GroupKey = utils.SearchKey(song.Group)
+	} else if patch.GroupID != nil {
+		found, ok := r.groups[*patch.GroupID]
+		if !ok {
+			return nil, ErrGroupNotFound
+		}
+		group = found
+		song.GroupID, song.Group = group.ID, group.Name
+		song.GroupKey = utils.SearchKey(group.Name)
+	}
+	if patch.Song != nil {
+		song.Song = *patch.Song
+		song.SongKey = utils.SearchKey(*patch.Song)
+	}
+	if patch.ReleaseDate != nil {
+		song.ReleaseDate = *patch.ReleaseDate
+	}
+	if patch.Text != nil {
+		song.Text = *patch.Text
+	}
+	if patch.Link != nil {
+		song.Link = *patch.Link
+	}
+	if patch.EnrichmentStatus != nil {
+		song.EnrichmentStatus = *patch.EnrichmentStatus
+	}
+
+	if r.hasSong(song.GroupID, song.Song, id) {
+		return nil, ErrDuplicate
+	}
+	r.saveGroup(&group)
+	song.GroupID = group.ID
+	song.EnrichmentSources = songSources(r.songs[id], song, patch.EnrichmentSources)
+	if len(changedSongFields(r.songs[id], song)) > 0 {
+		song.Version++
+		r.recordRevision(ctx, models.RevisionUpdate, r.songs[id], song)
+	} else if song.EnrichmentStatus != r.songs[id].EnrichmentStatus {
+		song.Version++
+	}
+	r.songs[id] = song
+	return &song, nil
+}
+
+// Delete перемещает песню в корзину.
+func (r *MemorySongRepository) Delete(ctx context.Context, id uint, expected uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	song, ok := r.songs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if expected != 0 && song.Version != expected {
+		return ErrVersionMismatch
+	}
+	song.Version++
+	song.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.trash[id] = song
+	delete(r.songs, id)
+	r.recordRevision(ctx, models.RevisionDelete, song, song)
+	return nil
+}
+
+// ListDeleted возвращает страницу песен в корзине.
+func (r *MemorySongRepository) ListDeleted(ctx context.Context, offset, limit int) ([]models.Song, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	trashed := make([]models.Song, 0, len(r.trash))
+	for _, song := range r.trash {
+		trashed = append(trashed, song)
+	}
+	sort.Slice(trashed, func(i, j int) bool {
+		if !trashed[i].DeletedAt.Time.Equal(trashed[j].DeletedAt.Time) {
+			return trashed[i].DeletedAt.Time.After(trashed[j].DeletedAt.Time)
+		}
+		return trashed[i].ID > trashed[j].ID
+	})
+
+	total := int64(len(trashed))
+	if offset > len(trashed) {
+		offset = len(trashed)
+	}
+	trashed = trashed[offset:]
+	if limit > 0 && len(trashed) > limit {
+		trashed = trashed[:limit]
+	}
+	return trashed, total, nil
+}
+
+// Restore возвращает песню из корзины.
+func (r *MemorySongRepository) Restore(ctx context.Context, id uint) (*models.Song, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	song, ok := r.trash[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if r.hasSong(song.GroupID, song.Song, id) {
+		return nil, ErrDuplicate
+	}
+	song.DeletedAt = gorm.DeletedAt{}
+	song.Version++
+	r.songs[id] = song
+	delete(r.trash, id)
+	r.recordRevision(ctx, models.RevisionRestore, song, song)
+	return &song, nil
+}
+
+// Purge окончательно удаляет песню из корзины.
+func (r *MemorySongRepository) Purge(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.trash[id]; !ok {
+		return ErrNotFound
+	}
+	r.purge(id)
+	return nil
+}
+
+// PurgeDeletedBefore окончательно удаляет песни, перемещённые в корзину раньше before.
+func (r *MemorySongRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, song := range r.trash {
+		if song.DeletedAt.Time.Before(before) {
+			r.purge(id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// purge окончательно удаляет песню из корзины вместе с историей версий, задачами и позициями
+// в треклистах, как каскадные внешние ключи в базе данных.
+func (r *MemorySongRepository) purge(songID uint) {
+	delete(r.trash, songID)
+	delete(r.revisions, songID)
+	for id, job := range r.jobs {
+		if job.SongID == songID {
+			delete(r.jobs, id)
+		}
+	}
+	for albumID, tracks := range r.tracks {
+		r.tracks[albumID] = slices.DeleteFunc(tracks, func(track models.AlbumTrack) bool { return track.SongID == songID })
+	}
+}
+
+// recordRevision добавляет в историю версию песни after, полученную действием action из before.
+func (r *MemorySongRepository) recordRevision(ctx context.Context, action string, before, after models.Song) {
+	snapshot := after
+	snapshot.DeletedAt = gorm.DeletedAt{}
+	r.revisions[after.ID] = append(r.revisions[after.ID], models.SongRevision{
+		SongID:        after.ID,
+		Revision:      len(r.revisions[after.ID]) + 1,
+		Action:        action,
+		ChangedFields: changedSongFields(before, after),
+		Actor:         ActorFromContext(ctx),
+		CreatedAt:     time.Now(),
+		Snapshot:      &snapshot,
+	})
+}
+
+// ListRevisions возвращает страницу версий песни без снимков.
+func (r *MemorySongRepository) ListRevisions(ctx context.Context, songID uint, offset, limit int) ([]models.SongRevision, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, active := r.songs[songID]
+	_, trashed := r.trash[songID]
+	if !active && !trashed {
+		return nil, 0, ErrNotFound
+	}
+
+	history := r.revisions[songID]
+	revisions := []models.SongRevision{}
+	for i := len(history) - 1 - offset; i >= 0 && (limit <= 0 || len(revisions) < limit); i-- {
+		revision := history[i]
+		revision.Snapshot = nil
+		revisions = append(revisions, revision)
+	}
+	return revisions, int64(len(history)), nil
+}
+
+// GetRevision возвращает версию песни со снимком.
+func (r *MemorySongRepository) GetRevision(ctx context.Context, songID uint, revision int) (*models.SongRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	history := r.revisions[songID]
+	if revision < 1 || revision > len(history) {
+		return nil, ErrRevisionNotFound
+	}
+	found := history[revision-1]
+	return &found, nil
+}
+
+// Revert возвращает поля песни к снимку версии.
+func (r *MemorySongRepository) Revert(ctx context.Context, songID uint, revision int) (*models.Song, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, ok := r.songs[songID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	history := r.revisions[songID]
+	if revision < 1 || revision > len(history) {
+		return nil, ErrRevisionNotFound
+	}
+	snapshot := *history[revision-1].Snapshot
+
+	song := before
+	song.Version++
+	group, ok := r.groups[snapshot.GroupID]
+	if !ok {
+		group = r.resolveGroup(snapshot.Group)
+	}
+	song.GroupID, song.Group = group.ID, group.Name
+	song.GroupKey = utils.SearchKey(song.Group)
+	song.Song, song.SongKey = snapshot.Song, utils.SearchKey(snapshot.Song)
+	song.ReleaseDate, song.Text, song.Link = snapshot.ReleaseDate, snapshot.Text, snapshot.Link
+	song.EnrichmentSources = snapshot.EnrichmentSources
+
+	if r.hasSong(song.GroupID, song.Song, songID) {
+		return nil, ErrDuplicate
+	}
+	r.saveGroup(&group)
+	song.GroupID = group.ID
+	r.songs[songID] = song
+	r.recordRevision(ctx, models.RevisionRevert, before, song)
+	return &song, nil
+}
+
+// hasSong проверяет, есть ли у группы другая песня с таким же названием без учета регистра,
+// как уникальный индекс в базе данных. Песня с ID except не учитывается. У ещё не сохранённой
+// группы (groupID 0) песен нет.
+func (r *MemorySongRepository) hasSong(groupID uint, title string, except uint) bool {
+	if groupID == 0 {
+		return false
+	}
+	for id, song := range r.songs {
+		if id != except && song.GroupID == groupID && strings.EqualFold(song.Song, title) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExistsByGroupAndTitle проверяет, есть ли у группы песня с таким названием.
+func (r *MemorySongRepository) ExistsByGroupAndTitle(ctx context.Context, group, title string) (bool, error) {
+	_, err := r.FindByGroupAndTitle(ctx, group, title)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// FindByGroupAndTitle возвращает песню группы с таким названием без учета регистра.
+func (r *MemorySongRepository) FindByGroupAndTitle(ctx context.Context, group, title string) (*models.Song, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	groupKey, titleKey := utils.NameKey(group), utils.NameKey(title)
+	for _, song := range r.songs {
+		if utils.NameKey(song.Group) == groupKey && utils.NameKey(song.Song) == titleKey {
+			return &song, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Suggest подбирает похожие названия группы и песни по сходству триграмм.
+func (r *MemorySongRepository) Suggest(ctx context.Context, filter SongFilter) (*models.SongSuggestion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var groups, titles []string
+	for _, group := range r.groups {
+		groups = append(groups, group.Name)
+	}
+	for _, song := range r.songs {
+		titles = append(titles, song.Song)
+	}
+
+	var suggestion models.SongSuggestion
+	if filter.Group != "" {
+		suggestion.Group = mostSimilar(filter.Group, groups)
+	}
+	if filter.Song != "" {
+		suggestion.Song = mostSimilar(filter.Song, titles)
+	}
+	if suggestion.Group == "" && suggestion.Song == "" {
+		return nil, nil
+	}
+	return &suggestion, nil
+}
+
+// Search не поддерживается: для полнотекстового поиска нужен PostgreSQL.
+func (r *MemorySongRepository) Search(ctx context.Context, query string, offset, limit int) ([]models.SongSearchHit, int64, error) {
+	return nil, 0, ErrNotSupported
+}
+
+// FuzzySearch не поддерживается: для поиска с опечатками нужен PostgreSQL.
+func (r *MemorySongRepository) FuzzySearch(ctx context.Context, query string, offset, limit int) ([]models.SongSimilarityHit, int64, error) {
+	return nil, 0, ErrNotSupported
+}
+
+// Autocomplete подбирает названия групп или песен так же, как GormSongRepository.
+func (r *MemorySongRepository) Autocomplete(ctx context.Context, field, prefix string, limit int) ([]models.Suggestion, error) {
+	if field != "group" && field != "song" {
+		return nil, fmt.Errorf("unknown autocomplete field %q", field)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Для каждого названия запоминаются количество песен и лучшее совпадение: 0 — начало названия, 1 — начало слова
+	prefix = strings.ToLower(prefix)
+	popularity := make(map[string]int64)
+	rank := make(map[string]int)
+	for _, song := range r.songs {
+		value := song.Song
+		if field == "group" {
+			value = song.Group
+		}
+		lower := strings.ToLower(value)
+		match := -1
+		if strings.HasPrefix(lower, prefix) {
+			match = 0
+		} else if strings.Contains(lower, " "+prefix) {
+			match = 1
+		}
+		if match < 0 {
+			continue
+		}
+		if current, ok := rank[value]; !ok || match < current {
+			rank[value] = match
+		}
+		popularity[value]++
+	}
+
+	suggestions := make([]models.Suggestion, 0, len(popularity))
+	for value, count := range popularity {
+		suggestions = append(suggestions, models.Suggestion{Value: value, Popularity: count})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if rank[a.Value] != rank[b.Value] {
+			return rank[a.Value] < rank[b.Value]
+		}
+		if a.Popularity != b.Popularity {
+			return a.Popularity > b.Popularity
+		}
+		return a.Value < b.Value
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// findOrCreateGroup возвращает ID и каноническое название группы, создавая её при отсутствии.
+// Вызывается при удерживаемой блокировке на запись.
+func (r *MemorySongRepository) findOrCreateGroup(name string) (uint, string) {
+	group := r.resolveGroup(name)
+	r.saveGroup(&group)
+	return group.ID, group.Name
+}
+
+// resolveGroup находит группу по названию, не создавая её. Если группы нет, возвращается новая группа
+// без ID, которую сохраняет saveGroup. Вызывается при удерживаемой блокировке на запись.
+func (r *MemorySongRepository) resolveGroup(name string) models.Group {
+	if group, ok := r.findGroupByName(name); ok {
+		return group
+	}
+	return models.Group{Name: utils.NormalizeName(name), NameKey: utils.NameKey(name), Aliases: []models.GroupAlias{}}
+}
+
+// saveGroup сохраняет новую группу, полученную от resolveGroup, и присваивает ей ID.
+// Уже сохранённая группа не меняется. Вызывается при удерживаемой блокировке на запись.
+func (r *MemorySongRepository) saveGroup(group *models.Group) {
+	if group.ID != 0 {
+		return
+	}
+	group.ID = r.nextGroupID
+	r.nextGroupID++
+	r.groups[group.ID] = *group
+}
+
+// findGroupByName ищет группу по названию или альтернативному названию, как database.FindGroupByName.
+func (r *MemorySongRepository) findGroupByName(name string) (models.Group, bool) {
+	key := utils.NameKey(name)
+	for _, group := range r.groups {
+		if group.NameKey == key {
+			return group, true
+		}
+		for _, alias := range group.Aliases {
+			if alias.AliasKey == key {
+				return group, true
+			}
+		}
+	}
+	return models.Group{}, false
+}
+
+// mostSimilar возвращает наиболее похожее на value название из списка, если оно отличается от value
+// и его сходство не ниже порога. При равном сходстве выбирается название, идущее раньше по алфавиту.
+func mostSimilar(value string, names []string) string {
+	best, bestScore := "", 0.0
+	for _, name := range names {
+		score := utils.TrigramSimilarity(value, name)
+		if score < suggestSimilarityThreshold {
+			continue
+		}
+		if score > bestScore || (score == bestScore && name < best) {
+			best, bestScore = name, score
+		}
+	}
+	if best == "" || utils.NameKey(best) == utils.NameKey(value) {
+		return ""
+	}
+	return best
+}
+
+// matchesSongFilter проверяет песню на соответствие фильтру так же, как это делает GormSongRepository.
+func matchesSongFilter(song models.Song, f SongFilter) bool {
+	if f.Group != "" && !matchesName(song.Group, song.GroupKey, f.Group) {
+		return false
+	}
+	if f.Song != "" && !matchesName(song.Song, song.SongKey, f.Song) {
+		return false
+	}
+	if f.Album != "" {
+		return false
+	}
+	if !f.ReleaseDate.IsZero() && !song.ReleaseDate.Equal(f.ReleaseDate.Time) {
+		return false
+	}
+	if !f.ReleasedFrom.IsZero() && (song.ReleaseDate.IsZero() || song.ReleaseDate.Before(f.ReleasedFrom.Time)) {
+		return false
+	}
+	if !f.ReleasedBefore.IsZero() && (song.ReleaseDate.IsZero() || !song.ReleaseDate.Before(f.ReleasedBefore.Time)) {
+		return false
+	}
+	return true
+}
+
+// matchesName проверяет, содержит ли название подстроку value без учета регистра
+// или поисковый ключ названия — ключ value.
+func matchesName(name, nameKey, value string) bool {
+	if strings.Contains(strings.ToLower(name), strings.ToLower(value)) {
+		return true
+	}
+	key := utils.SearchKey(value)
+	return key != "" && strings.Contains(nameKey, key)
+}
+
+// compareSongs сравнивает песни в порядке sort и возвращает -1, 0 или 1.
+func compareSongs(a, b models.Song, sort []SortField) int {
+	for _, field := range sort {
+		var result int
+		if field.Name == "id" {
+			result = compareIDs(a.ID, b.ID)
+		} else {
+			result = strings.Compare(SongSortValue(field.Name, a), SongSortValue(field.Name, b))
+		}
+		if field.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// compareSongToKeyset сравнивает песню с позицией keyset в порядке sort и возвращает -1, 0 или 1.
+func compareSongToKeyset(song models.Song, keyset *SongKeyset, sort []SortField) int {
+	valueIndex := 0
+	for _, field := range sort {
+		var result int
+		if field.Name == "id" {
+			result = compareIDs(song.ID, keyset.ID)
+		} else {
+			result = strings.Compare(SongSortValue(field.Name, song), keyset.Values[valueIndex])
+			valueIndex++
+		}
+		if field.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// compareIDs сравнивает идентификаторы и возвращает -1, 0 или 1.
+func compareIDs(a, b uint) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,655 @@
+package repository
+
+import (
+	"MusicLibrary/models"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listTitles возвращает названия песен страницы через запятую.
+func listTitles(songs []models.Song) string {
+	titles := make([]string, 0, len(songs))
+	for _, song := range songs {
+		titles = append(titles, song.Song)
+	}
+	return strings.Join(titles, ",")
+}
+
+func TestMemoryListAfterKeyset(t *testing.T) {
+	testListAfterKeyset(t, NewMemorySongRepository())
+}
+
+// testListAfterKeyset проверяет выборку страниц по ключу в пустом хранилище songs.
+func testListAfterKeyset(t *testing.T, songs SongRepository) {
+	date := func(year int) models.Date { return models.Date{Time: time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)} }
+	for _, song := range []models.Song{
+		{Group: "Muse", Song: "A", ReleaseDate: date(2003)},
+		{Group: "Muse", Song: "B"},
+		{Group: "Blur", Song: "C", ReleaseDate: date(1997)},
+		{Group: "Blur", Song: "D", ReleaseDate: date(2003)},
+	} {
+		if err := songs.Create(context.Background(), &song); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	desc := []SortField{{Name: "releaseDate", Desc: true}, {Name: "id"}}
+	byGroup := []SortField{{Name: "group"}, {Name: "song", Desc: true}, {Name: "id"}}
+	tests := []struct {
+		name  string
+		sort  []SortField
+		after *SongKeyset
+		want  string
+	}{
+		{name: "first page", sort: desc, want: "A,D,C,B"},
+		{name: "after equal date", sort: desc, after: &SongKeyset{Values: []string{"2003-01-01"}, ID: 1}, want: "D,C,B"},
+		{name: "songs without date are the earliest", sort: desc, after: &SongKeyset{Values: []string{"1997-01-01"}, ID: 3}, want: "B"},
+		{name: "after the last song", sort: desc, after: &SongKeyset{Values: []string{"0001-01-01"}, ID: 2}, want: ""},
+		{name: "mixed directions", sort: byGroup, after: &SongKeyset{Values: []string{"Blur", "D"}, ID: 4}, want: "C,B,A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, total, err := songs.List(context.Background(), SongListQuery{Sort: tt.sort, After: tt.after, Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := listTitles(page); got != tt.want || total != 4 {
+				t.Errorf("page %q, total %d, want %q, total 4", got, total, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryListFilter(t *testing.T) {
+	testListFilter(t, NewMemorySongRepository())
+}
+
+// testListFilter проверяет фильтрацию песен по названиям и датам выпуска в пустом хранилище songs.
+func testListFilter(t *testing.T, songs SongRepository) {
+	for _, song := range []models.Song{
+		{Group: "Кино", Song: "Группа крови", ReleaseDate: models.NewDate(1988, time.January, 4)},
+		{Group: "Мумий Тролль", Song: "Утекай", ReleaseDate: models.NewDate(1997, time.May, 1)},
+		{Group: "Muse", Song: "Hysteria", ReleaseDate: models.NewDate(2003, time.December, 1)},
+		{Group: "Muse", Song: "Uprising"},
+	} {
+		if err := songs.Create(context.Background(), &song); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter SongFilter
+		want   string
+	}{
+		{name: "no filter", want: "Группа крови,Утекай,Hysteria,Uprising"},
+		{name: "group substring", filter: SongFilter{Group: "mu"}, want: "Утекай,Hysteria,Uprising"},
+		{name: "group transliteration", filter: SongFilter{Group: "Kino"}, want: "Группа крови"},
+		{name: "another transliteration scheme", filter: SongFilter{Group: "Mumij Troll"}, want: "Утекай"},
+		{name: "song transliteration", filter: SongFilter{Song: "gruppa krovi"}, want: "Группа крови"},
+		{name: "cyrillic in another case", filter: SongFilter{Song: "ГРУППА"}, want: "Группа крови"},
+		{name: "release date", filter: SongFilter{ReleaseDate: models.NewDate(2003, time.December, 1)}, want: "Hysteria"},
+		{name: "release period", filter: SongFilter{ReleasedFrom: models.NewDate(1990, time.January, 1), ReleasedBefore: models.NewDate(2000, time.January, 1)}, want: "Утекай"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, total, err := songs.List(context.Background(), SongListQuery{Filter: tt.filter, Sort: []SortField{{Name: "id"}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := listTitles(page); got != tt.want || total != int64(len(page)) {
+				t.Errorf("songs %q, total %d, want %q", got, total, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryListFilterLikeCharacters(t *testing.T) {
+	testListFilterLikeCharacters(t, NewMemorySongRepository())
+}
+
+// testListFilterLikeCharacters проверяет, что символы шаблона LIKE в фильтрах сравниваются буквально,
+// в пустом хранилище songs.
+func testListFilterLikeCharacters(t *testing.T, songs SongRepository) {
+	for _, song := range []models.Song{
+		{Group: "Crystal Waters", Song: "100% Pure Love"},
+		{Group: "Blur", Song: "Song_2"},
+		{Group: "Blur", Song: "Song 2"},
+		{Group: "50%_Band", Song: `Back\Slash`},
+	} {
+		if err := songs.Create(context.Background(), &song); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter SongFilter
+		want   string
+	}{
+		{name: "percent", filter: SongFilter{Song: "%"}, want: "100% Pure Love"},
+		{name: "underscore", filter: SongFilter{Song: "_"}, want: "Song_2"},
+		{name: "backslash", filter: SongFilter{Song: `\`}, want: `Back\Slash`},
+		{name: "percent in group", filter: SongFilter{Group: "%_"}, want: `Back\Slash`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, _, err := songs.List(context.Background(), SongListQuery{Filter: tt.filter, Sort: []SortField{{Name: "id"}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := listTitles(page); got != tt.want {
+				t.Errorf("songs %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryDuplicate(t *testing.T) {
+	testDuplicate(t, NewMemorySongRepository())
+}
+
+// testDuplicate проверяет, что хранилище songs не допускает у группы двух песен с одним названием без учета регистра.
+func testDuplicate(t *testing.T, songs SongRepository) {
+	ctx := context.Background()
+	for _, song := range []models.Song{{Group: "Muse", Song: "Hysteria"}, {Group: "Muse", Song: "Starlight"}, {Group: "Кино", Song: "Кукушка"}} {
+		if err := songs.Create(ctx, &song); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, song := range []models.Song{{Group: "muse", Song: "HYSTERIA"}, {Group: "Кино", Song: "КУКУШКА"}} {
+		if err := songs.Create(ctx, &song); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("create %s by %s: error %v, want ErrDuplicate", song.Song, song.Group, err)
+		}
+	}
+	if _, err := songs.Update(ctx, 2, models.SongPatch{Song: ptr("hysteria")}, 0); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("rename to a taken title: error %v, want ErrDuplicate", err)
+	}
+	if _, err := songs.Update(ctx, 1, models.SongPatch{Song: ptr("HYSTERIA")}, 0); err != nil {
+		t.Errorf("change the case of the own title: %v", err)
+	}
+	if err := songs.Create(ctx, &models.Song{Group: "Blur", Song: "Hysteria"}); err != nil {
+		t.Errorf("same title of another group: %v", err)
+	}
+}
+
+func TestMemoryTrash(t *testing.T) {
+	testTrash(t, NewMemorySongRepository())
+}
+
+// testTrash проверяет перемещение песен в корзину, восстановление и окончательное удаление в пустом хранилище songs.
+func testTrash(t *testing.T, songs SongRepository) {
+	ctx := context.Background()
+	for _, title := range []string{"Hysteria", "Starlight"} {
+		if err := songs.Create(ctx, &models.Song{Group: "Muse", Song: title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Песня в корзине не видна и не мешает создать песню с тем же названием
+	if err := songs.Delete(ctx, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := songs.Delete(ctx, 1, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("delete a song in trash: error %v, want ErrNotFound", err)
+	}
+	if _, err := songs.Get(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get a song in trash: error %v, want ErrNotFound", err)
+	}
+	if page, total, err := songs.List(ctx, SongListQuery{Sort: []SortField{{Name: "id"}}}); err != nil || total != 1 || listTitles(page) != "Starlight" {
+		t.Errorf("list: %q, total %d, error %v, want only Starlight", listTitles(page), total, err)
+	}
+	trashed, total, err := songs.ListDeleted(ctx, 0, 10)
+	if err != nil || total != 1 || len(trashed) != 1 || trashed[0].ID != 1 || !trashed[0].DeletedAt.Valid {
+		t.Fatalf("trash: %+v, total %d, error %v, want song 1 with the deletion time", trashed, total, err)
+	}
+	recreated := models.Song{Group: "Muse", Song: "HYSTERIA"}
+	if err := songs.Create(ctx, &recreated); err != nil {
+		t.Fatalf("create a song with the title of a song in trash: %v", err)
+	}
+
+	// Восстановление невозможно, пока у группы есть песня с тем же названием
+	if _, err := songs.Restore(ctx, 1); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("restore over a song with the same title: error %v, want ErrDuplicate", err)
+	}
+	if _, err := songs.Restore(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("restore a song not in trash: error %v, want ErrNotFound", err)
+	}
+	if err := songs.Delete(ctx, recreated.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := songs.Restore(ctx, 1)
+	if err != nil || restored.Song != "Hysteria" || restored.DeletedAt.Valid {
+		t.Fatalf("restore: %+v, error %v", restored, err)
+	}
+	if _, err := songs.Get(ctx, 1); err != nil {
+		t.Errorf("get a restored song: %v", err)
+	}
+
+	// Окончательно удалить можно только песню из корзины
+	if err := songs.Purge(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("purge a song not in trash: error %v, want ErrNotFound", err)
+	}
+	if err := songs.Purge(ctx, recreated.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := songs.Restore(ctx, recreated.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("restore a purged song: error %v, want ErrNotFound", err)
+	}
+
+	// Очистка по сроку хранения удаляет только песни, попавшие в корзину раньше границы
+	if err := songs.Delete(ctx, 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if purged, err := songs.PurgeDeletedBefore(ctx, time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+		t.Errorf("purge songs deleted an hour ago: %d, error %v, want 0", purged, err)
+	}
+	if purged, err := songs.PurgeDeletedBefore(ctx, time.Now().Add(time.Hour)); err != nil || purged != 1 {
+		t.Errorf("purge songs deleted before an hour from now: %d, error %v, want 1", purged, err)
+	}
+	if _, total, err := songs.ListDeleted(ctx, 0, 10); err != nil || total != 0 {
+		t.Errorf("trash after purge: total %d, error %v, want 0", total, err)
+	}
+}
+
+func TestMemoryVersion(t *testing.T) {
+	testVersion(t, NewMemorySongRepository())
+}
+
+// testVersion проверяет увеличение версии песни при изменениях и отказ в изменении устаревшей версии
+// в пустом хранилище songs.
+func testVersion(t *testing.T, songs SongRepository) {
+	ctx := context.Background()
+	song := models.Song{Group: "Muse", Song: "Hysteria"}
+	if err := songs.Create(ctx, &song); err != nil {
+		t.Fatal(err)
+	}
+	if song.Version != 1 {
+		t.Fatalf("version of a created song %d, want 1", song.Version)
+	}
+
+	updated, err := songs.Update(ctx, song.ID, models.SongPatch{Text: ptr("verse")}, 1)
+	if err != nil || updated.Version != 2 {
+		t.Fatalf("update version 1: %+v, error %v, want version 2", updated, err)
+	}
+	if _, err := songs.Update(ctx, song.ID, models.SongPatch{Text: ptr("chorus")}, 1); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("update outdated version: error %v, want ErrVersionMismatch", err)
+	}
+	if updated, err := songs.Update(ctx, song.ID, models.SongPatch{Text: ptr("chorus")}, 0); err != nil || updated.Version != 3 {
+		t.Errorf("update without expected version: %+v, error %v, want version 3", updated, err)
+	}
+	if updated, err := songs.Update(ctx, song.ID, models.SongPatch{Text: ptr("chorus")}, 3); err != nil || updated.Version != 3 {
+		t.Errorf("update without changes: %+v, error %v, want version 3", updated, err)
+	}
+	if err := songs.Delete(ctx, song.ID, 2); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("delete outdated version: error %v, want ErrVersionMismatch", err)
+	}
+	if err := songs.Delete(ctx, song.ID, 3); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := songs.Restore(ctx, song.ID)
+	if err != nil || restored.Version <= 3 {
+		t.Errorf("restore: %+v, error %v, want a version after 3", restored, err)
+	}
+}
+
+func TestMemoryUpdatePatch(t *testing.T) {
+	testUpdatePatch(t, NewMemorySongRepository())
+}
+
+// testUpdatePatch проверяет, что Update изменяет только поля, переданные в patch, и очищает поля пустыми значениями.
+func testUpdatePatch(t *testing.T, songs SongRepository) {
+	ctx := context.Background()
+	song := models.Song{Group: "Muse", Song: "Hysteria", Text: "verse", Link: "https://example.com/hysteria",
+		ReleaseDate: models.Date{Time: time.Date(2003, 12, 1, 0, 0, 0, 0, time.UTC)}}
+	if err := songs.Create(ctx, &song); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := songs.Update(ctx, song.ID, models.SongPatch{Text: ptr(""), ReleaseDate: &models.Date{}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Text != "" || !updated.ReleaseDate.IsZero() || updated.Link != song.Link || updated.Song != "Hysteria" {
+		t.Errorf("updated song %+v, want cleared text and release date and the other fields unchanged", updated)
+	}
+
+	updated, err = songs.Update(ctx, song.ID, models.SongPatch{Group: ptr("Blur")}, 0)
+	if err != nil || updated.Group != "Blur" || updated.GroupID == song.GroupID {
+		t.Fatalf("move to a new group: %+v, error %v", updated, err)
+	}
+	if _, err := songs.Update(ctx, song.ID, models.SongPatch{GroupID: ptr(uint(42))}, 0); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("move to a missing group: error %v, want ErrGroupNotFound", err)
+	}
+	if updated, err := songs.Update(ctx, song.ID, models.SongPatch{GroupID: ptr(song.GroupID)}, 0); err != nil || updated.Group != "Muse" {
+		t.Errorf("move back by group ID: %+v, error %v", updated, err)
+	}
+	if _, err := songs.Update(ctx, 42, models.SongPatch{Text: ptr("verse")}, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("update a missing song: error %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryCreateWithID(t *testing.T) {
+	testCreateWithID(t, NewMemorySongRepository())
+}
+
+// testCreateWithID проверяет создание песен с заданным ID и группой по ID в пустом хранилище songs.
+func testCreateWithID(t *testing.T, songs SongRepository) {
+	ctx := context.Background()
+	song := models.Song{ID: 10, Group: "Muse", Song: "Hysteria"}
+	if err := songs.Create(ctx, &song); err != nil || song.ID != 10 {
+		t.Fatalf("create with ID 10: %+v, error %v", song, err)
+	}
+	next := models.Song{Group: "Muse", Song: "Starlight"}
+	if err := songs.Create(ctx, &next); err != nil || next.ID != 11 {
+		t.Fatalf("create after ID 10: %+v, error %v, want ID 11", next, err)
+	}
+	if err := songs.Create(ctx, &models.Song{ID: 10, Group: "Muse", Song: "Uprising"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("create with a taken ID: error %v, want ErrDuplicate", err)
+	}
+	if err := songs.Delete(ctx, 11, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := songs.Create(ctx, &models.Song{ID: 11, Group: "Muse", Song: "Uprising"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("create with the ID of a song in trash: error %v, want ErrDuplicate", err)
+	}
+
+	byGroupID := models.Song{GroupID: song.GroupID, Song: "Uprising"}
+	if err := songs.Create(ctx, &byGroupID); err != nil || byGroupID.Group != "Muse" {
+		t.Errorf("create by group ID: %+v, error %v", byGroupID, err)
+	}
+	if err := songs.Create(ctx, &models.Song{GroupID: 42, Song: "Uprising"}); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("create by a missing group ID: error %v, want ErrGroupNotFound", err)
+	}
+}
+
+func TestMemoryFindByGroupAndTitle(t *testing.T) {
+	testFindByGroupAndTitle(t, NewMemorySongRepository())
+}
+
+// testFindByGroupAndTitle проверяет поиск песни по группе и названию без учета регистра в пустом хранилище songs.
+func testFindByGroupAndTitle(t *testing.T, songs SongRepository) {
+	ctx := context.Background()
+	for _, song := range []models.Song{{Group: "Muse", Song: "Hysteria"}, {Group: "Кино", Song: "Кукушка"}} {
+		if err := songs.Create(ctx, &song); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		group, title string
+		want         uint
+	}{
+		{group: "muse", title: "HYSTERIA", want: 1},
+		{group: " Muse ", title: " Hysteria", want: 1},
+		{group: "КИНО", title: "кукушка", want: 2},
+		{group: "Muse", title: "Starlight"},
+		{group: "Blur", title: "Hysteria"},
+	}
+	for _, tt := range tests {
+		song, err := songs.FindByGroupAndTitle(ctx, tt.group, tt.title)
+		if tt.want == 0 {
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("FindByGroupAndTitle(%q, %q): error %v, want ErrNotFound", tt.group, tt.title, err)
+			}
+			continue
+		}
+		if err != nil || song.ID != tt.want {
+			t.Errorf("FindByGroupAndTitle(%q, %q) = %+v, error %v, want song %d", tt.group, tt.title, song, err, tt.want)
+		}
+		if exists, err := songs.ExistsByGroupAndTitle(ctx, tt.group, tt.title); err != nil || !exists {
+			t.Errorf("ExistsByGroupAndTitle(%q, %q) = %v, error %v, want true", tt.group, tt.title, exists, err)
+		}
+	}
+}
+
+func TestMemoryEach(t *testing.T) {
+	testEach(t, NewMemorySongRepository())
+}
+
+// testEach проверяет перебор песен с фильтром и сортировкой в пустом хранилище songs.
+func testEach(t *testing.T, songs SongRepository) {
+	ctx := context.Background()
+	for _, song := range []models.Song{
+		{Group: "Muse", Song: "Hysteria", ReleaseDate: models.NewDate(2003, time.December, 1)},
+		{Group: "Blur", Song: "Song 2", ReleaseDate: models.NewDate(1997, time.April, 7)},
+		{Group: "Muse", Song: "Uprising", ReleaseDate: models.NewDate(2009, time.September, 7)},
+	} {
+		if err := songs.Create(ctx, &song); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var visited []models.Song
+	collect := func(song models.Song) error {
+		visited = append(visited, song)
+		return nil
+	}
+	tests := []struct {
+		name   string
+		filter SongFilter
+		sort   []SortField
+		want   string
+	}{
+		{name: "all songs", sort: []SortField{{Name: "id"}}, want: "Hysteria,Song 2,Uprising"},
+		{name: "release date descending", sort: []SortField{{Name: "releaseDate", Desc: true}, {Name: "id"}}, want: "Uprising,Hysteria,Song 2"},
+		{name: "filter", filter: SongFilter{Group: "muse"}, sort: []SortField{{Name: "id", Desc: true}}, want: "Uprising,Hysteria"},
+		{name: "nothing found", filter: SongFilter{Group: "Кино"}, sort: []SortField{{Name: "id"}}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visited = nil
+			if err := songs.Each(ctx, tt.filter, tt.sort, collect); err != nil {
+				t.Fatal(err)
+			}
+			if got := listTitles(visited); got != tt.want {
+				t.Errorf("songs %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err := songs.Each(ctx, SongFilter{}, []SortField{{Name: "id"}}, func(models.Song) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Each returned %v after %d calls, want the callback error after 1 call", err, calls)
+	}
+}
+
+func TestMemorySuggest(t *testing.T) {
+	songs := NewMemorySongRepository()
+	for _, title := range []string{"Hysteria", "Starlight"} {
+		if err := songs.Create(context.Background(), &models.Song{Group: "Muse", Song: title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter SongFilter
+		want   *models.SongSuggestion
+	}{
+		{name: "typo in song", filter: SongFilter{Song: "Hysterya"}, want: &models.SongSuggestion{Song: "Hysteria"}},
+		{name: "typo in group and song", filter: SongFilter{Group: "Musee", Song: "Starlite"}, want: &models.SongSuggestion{Group: "Muse", Song: "Starlight"}},
+		{name: "same name in another case", filter: SongFilter{Song: "HYSTERIA"}},
+		{name: "nothing similar", filter: SongFilter{Song: "Bohemian Rhapsody"}},
+	}
+	for _, tt := range tests {
+		got, err := songs.Suggest(context.Background(), tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("%s: suggestion %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryRevisions(t *testing.T) {
+	testRevisions(t, NewMemorySongRepository())
+}
+
+// testRevisions проверяет запись истории версий песни и откат к версии в пустом хранилище songs.
+func testRevisions(t *testing.T, songs SongRepository) {
+	ctx := WithActor(context.Background(), "editor")
+	song := models.Song{Group: "Muse", Song: "Hysteria", Text: "verse"}
+	if err := songs.Create(ctx, &song); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := songs.Update(ctx, song.ID, models.SongPatch{Song: ptr("Hysteria (live)"), Text: ptr("verse\nchorus")}, 0); err != nil {
+		t.Fatal(err)
+	}
+	// Изменение без новых значений не создаёт версию
+	if _, err := songs.Update(ctx, song.ID, models.SongPatch{Text: ptr("verse\nchorus")}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := songs.Delete(context.Background(), song.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := songs.Revert(ctx, song.ID, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("revert a song in trash: error %v, want ErrNotFound", err)
+	}
+	if _, err := songs.Restore(ctx, song.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, total, err := songs.ListRevisions(ctx, song.ID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, revision := range revisions {
+		actions = append(actions, revision.Action)
+		if revision.Snapshot != nil {
+			t.Errorf("revision %d is listed with a snapshot", revision.Revision)
+		}
+	}
+	if total != 4 || strings.Join(actions, ",") != "restore,delete,update,create" {
+		t.Fatalf("revisions %v, total %d, want restore,delete,update,create", actions, total)
+	}
+	if revisions[2].Actor != "editor" || revisions[1].Actor != DefaultActor || strings.Join(revisions[2].ChangedFields, ",") != "song,text" {
+		t.Errorf("update revision %+v, delete revision %+v, want actors editor and %s and changed fields song,text", revisions[2], revisions[1], DefaultActor)
+	}
+	if page, _, err := songs.ListRevisions(ctx, song.ID, 1, 2); err != nil || len(page) != 2 || page[0].Revision != 3 || page[1].Revision != 2 {
+		t.Errorf("second page: %+v, error %v, want revisions 3 and 2", page, err)
+	}
+	if _, _, err := songs.ListRevisions(ctx, 42, 0, 10); !errors.Is(err, ErrNotFound) {
+		t.Errorf("revisions of a missing song: error %v, want ErrNotFound", err)
+	}
+
+	first, err := songs.GetRevision(ctx, song.ID, 1)
+	if err != nil || first.Snapshot == nil || first.Snapshot.Song != "Hysteria" || first.Snapshot.Text != "verse" {
+		t.Fatalf("revision 1: %+v, error %v, want the snapshot of the created song", first, err)
+	}
+	for _, revision := range []int{0, 5} {
+		if _, err := songs.GetRevision(ctx, song.ID, revision); !errors.Is(err, ErrRevisionNotFound) {
+			t.Errorf("revision %d: error %v, want ErrRevisionNotFound", revision, err)
+		}
+	}
+
+	// Откат записывается новой версией
+	reverted, err := songs.Revert(ctx, song.ID, 1)
+	if err != nil || reverted.Song != "Hysteria" || reverted.Text != "verse" || reverted.Group != "Muse" {
+		t.Fatalf("revert to revision 1: %+v, error %v", reverted, err)
+	}
+	if latest, err := songs.GetRevision(ctx, song.ID, 5); err != nil || latest.Action != models.RevisionRevert || strings.Join(latest.ChangedFields, ",") != "song,text" {
+		t.Errorf("revision 5: %+v, error %v, want a revert of song and text", latest, err)
+	}
+	if _, err := songs.Revert(ctx, song.ID, 6); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("revert to a missing revision: error %v, want ErrRevisionNotFound", err)
+	}
+
+	// Откат к названию, которое уже занято другой песней группы
+	if err := songs.Create(ctx, &models.Song{Group: "Muse", Song: "Hysteria (live)"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := songs.Revert(ctx, song.ID, 2); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("revert to a taken title: error %v, want ErrDuplicate", err)
+	}
+
+	// Окончательное удаление удаляет и историю
+	if err := songs.Delete(ctx, song.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := songs.Purge(ctx, song.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := songs.GetRevision(ctx, song.ID, 1); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("revision of a purged song: error %v, want ErrRevisionNotFound", err)
+	}
+}
+
+func TestMemoryUpdateEnrichmentSources(t *testing.T) {
+	testUpdateEnrichmentSources(t, NewMemorySongRepository())
+}
+
+// testUpdateEnrichmentSources проверяет, что изменение поля сбрасывает его источник, а откат версии
+// возвращает источники вместе со значениями, в пустом хранилище songs.
+func testUpdateEnrichmentSources(t *testing.T, songs SongRepository) {
+	ctx := context.Background()
+	song := models.Song{Group: "Muse", Song: "Hysteria"}
+	if err := songs.Create(ctx, &song); err != nil {
+		t.Fatal(err)
+	}
+	text, link := "verse", "https://example.com/hysteria"
+	enriched, err := songs.Update(ctx, song.ID, models.SongPatch{
+		Text:              &text,
+		Link:              &link,
+		EnrichmentSources: models.SongSources{"text": "lyrics", "link": "video"},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		patch models.SongPatch
+		want  models.SongSources
+	}{
+		{name: "unrelated field", patch: models.SongPatch{Song: ptr("Hysteria (Live)")}, want: models.SongSources{"text": "lyrics", "link": "video"}},
+		{name: "same value", patch: models.SongPatch{Text: ptr("verse")}, want: models.SongSources{"text": "lyrics", "link": "video"}},
+		{name: "edited field", patch: models.SongPatch{Text: ptr("new verse")}, want: models.SongSources{"link": "video"}},
+		{name: "all fields edited", patch: models.SongPatch{Link: ptr("")}, want: nil},
+	}
+	for _, tt := range tests {
+		updated, err := songs.Update(ctx, song.ID, tt.patch, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(updated.EnrichmentSources, tt.want) {
+			t.Errorf("%s: sources %v, want %v", tt.name, updated.EnrichmentSources, tt.want)
+		}
+	}
+
+	// Откат возвращает источники вместе со значениями полей
+	revisions, _, err := songs.ListRevisions(ctx, song.ID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var revision int
+	for _, r := range revisions {
+		if reflect.DeepEqual(r.ChangedFields, []string{"text", "link"}) {
+			revision = r.Revision
+		}
+	}
+	reverted, err := songs.Revert(ctx, song.ID, revision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reverted.EnrichmentSources, enriched.EnrichmentSources) {
+		t.Errorf("reverted sources %v, want %v", reverted.EnrichmentSources, enriched.EnrichmentSources)
+	}
+}
+
+// ptr возвращает указатель на значение value.
+func ptr[T any](value T) *T {
+	return &value
+}
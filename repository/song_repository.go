@@ -0,0 +1,206 @@
+/*
+Package repository содержит интерфейсы доступа к данным библиотеки и их реализации.
+Обработчики HTTP-запросов работают с хранилищем только через эти интерфейсы, поэтому
+реализацию на GORM и PostgreSQL можно заменить, например, хранилищем в памяти в тестах.
+*/
+
+package repository
+
+import (
+	"MusicLibrary/models"
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound возвращается, если запрошенная запись не найдена.
+var ErrNotFound = errors.New("record not found")
+
+// ErrGroupNotFound возвращается, если группы с указанным ID нет, например при привязке к ней песни.
+var ErrGroupNotFound = errors.New("group not found")
+
+// ErrRevisionNotFound возвращается, если у песни нет версии с запрошенным номером.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// ErrVersionMismatch возвращается, если песню изменили после того, как клиент получил её версию.
+var ErrVersionMismatch = errors.New("song version mismatch")
+
+// ErrNotSupported возвращается, если хранилище не поддерживает операцию, например полнотекстовый поиск в SQLite.
+var ErrNotSupported = errors.New("operation is not supported by the storage")
+
+// ErrDuplicate возвращается, если у группы уже есть песня с таким же названием без учета регистра.
+// Уникальность обеспечивается индексом базы данных, поэтому ошибка возникает и при одновременном создании.
+var ErrDuplicate = errors.New("song already exists")
+
+// SongFilter содержит условия отбора песен. Пустые поля не ограничивают выборку.
+// Ограничения по датам задают полуинтервал [ReleasedFrom, ReleasedBefore).
+type SongFilter struct {
+	Group          string      // Подстрока канонического или альтернативного названия группы, в том числе в другой транслитерации
+	Song           string      // Подстрока названия песни, в том числе в другой транслитерации
+	Album          string      // Подстрока названия альбома, в который входит песня
+	ReleaseDate    models.Date // Точная дата выпуска
+	ReleasedFrom   models.Date // Дата выпуска не ранее указанной
+	ReleasedBefore models.Date // Дата выпуска строго ранее указанной
+}
+
+// SongSortFields перечисляет поля, по которым можно сортировать песни.
+var SongSortFields = []string{"id", "group", "song", "releaseDate"}
+
+// SortField описывает одно поле сортировки и её направление.
+type SortField struct {
+	Name string
+	Desc bool
+}
+
+// SongKeyset задаёт позицию в упорядоченном списке песен для выборки по ключу:
+// значения полей сортировки (кроме id) у граничной песни и её ID.
+type SongKeyset struct {
+	Values []string
+	ID     uint
+}
+
+// SongListQuery описывает выборку списка песен.
+type SongListQuery struct {
+	Filter SongFilter
+	Sort   []SortField // Порядок сортировки; последним полем должен идти id
+	After  *SongKeyset // Если задан, выбираются только песни, следующие за этой позицией в порядке Sort
+	Offset int
+	Limit  int
+}
+
+// SongRepository описывает хранилище песен. Каждое изменение песни записывается в историю версий
+// в той же транзакции; автор изменения берётся из контекста (см. WithActor).
+type SongRepository interface {
+	// List возвращает страницу песен и общее количество песен, подходящих под фильтр (без учета After, Offset и Limit).
+	List(ctx context.Context, query SongListQuery) ([]models.Song, int64, error)
+	// Each вызывает fn для каждой песни, подходящей под фильтр, в порядке sort (последним полем должен идти id).
+	// Песни читаются из хранилища порциями, поэтому весь список не загружается в память.
+	// Ошибка fn прекращает перебор и возвращается из Each.
+	Each(ctx context.Context, filter SongFilter, sort []SortField, fn func(models.Song) error) error
+	// Get возвращает песню по ID или ErrNotFound.
+	Get(ctx context.Context, id uint) (*models.Song, error)
+	// Create сохраняет новую песню. Поле Group содержит название группы; группа ищется
+	// по названию и альтернативным названиям и создаётся при отсутствии. Если название не задано,
+	// группа берётся по GroupID; для неизвестного ID возвращается ErrGroupNotFound.
+	// Если ID песни задан, песня создаётся с этим ID. Для дубликата названия или занятого ID,
+	// в том числе песней в корзине, возвращается ErrDuplicate.
+	Create(ctx context.Context, song *models.Song) error
+	// CreateWithJob сохраняет новую песню так же, как Create, и в той же транзакции ставит в очередь
+	// задачу job для неё: песня не остаётся без задачи, а задача — без песни. ID песни записывается в job.SongID.
+	CreateWithJob(ctx context.Context, song *models.Song, job *models.Job) error
+	// Update применяет к песне изменения patch и возвращает песню, заново прочитанную из хранилища.
+	// Версия песни увеличивается, только если изменения что-то поменяли. Смена только статуса обогащения
+	// увеличивает версию, но не записывается в историю.
+	// Если expected не равен нулю и текущая версия песни другая, возвращается ErrVersionMismatch.
+	// Группа меняется по названию (Group) или по ID (GroupID); для неизвестного ID возвращается ErrGroupNotFound.
+	// Если после изменения у группы окажутся две песни с одним названием, возвращается ErrDuplicate.
+	Update(ctx context.Context, id uint, patch models.SongPatch, expected uint) (*models.Song, error)
+	// Delete перемещает песню в корзину по ID или возвращает ErrNotFound. Песни в корзине не возвращаются
+	// остальными методами, кроме ListDeleted, и не мешают создать песню с тем же названием.
+	// Если expected не равен нулю и текущая версия песни другая, возвращается ErrVersionMismatch.
+	Delete(ctx context.Context, id uint, expected uint) error
+	// ListDeleted возвращает страницу песен в корзине, начиная с удалённых последними, и общее количество песен в корзине.
+	ListDeleted(ctx context.Context, offset, limit int) ([]models.Song, int64, error)
+	// Restore возвращает песню из корзины. Если песни нет в корзине, возвращается ErrNotFound;
+	// если у группы уже есть песня с таким названием, возвращается ErrDuplicate.
+	Restore(ctx context.Context, id uint) (*models.Song, error)
+	// Purge окончательно удаляет песню из корзины вместе с её позициями в треклистах альбомов.
+	// Если песни нет в корзине, возвращается ErrNotFound.
+	Purge(ctx context.Context, id uint) error
+	// PurgeDeletedBefore окончательно удаляет песни, перемещённые в корзину раньше before, и возвращает их количество.
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+	// ExistsByGroupAndTitle проверяет, есть ли у группы песня с таким названием без учета регистра.
+	ExistsByGroupAndTitle(ctx context.Context, group, title string) (bool, error)
+	// FindByGroupAndTitle возвращает песню группы с таким названием без учета регистра или ErrNotFound.
+	// Группа ищется по названию и альтернативным названиям.
+	FindByGroupAndTitle(ctx context.Context, group, title string) (*models.Song, error)
+	// ListRevisions возвращает страницу версий песни от новых к старым без снимков и общее количество версий.
+	// Версии песни в корзине тоже доступны; если песни нет, возвращается ErrNotFound.
+	ListRevisions(ctx context.Context, songID uint, offset, limit int) ([]models.SongRevision, int64, error)
+	// GetRevision возвращает версию песни со снимком или ErrRevisionNotFound.
+	GetRevision(ctx context.Context, songID uint, revision int) (*models.SongRevision, error)
+	// Revert возвращает поля песни к снимку версии revision и записывает это как новую версию.
+	// Если песни нет или она в корзине, возвращается ErrNotFound, если нет версии — ErrRevisionNotFound,
+	// если у группы уже есть песня с названием из снимка — ErrDuplicate.
+	Revert(ctx context.Context, songID uint, revision int) (*models.Song, error)
+	// Suggest подбирает существующие названия группы и песни, похожие на значения фильтра.
+	// Если похожих названий нет, возвращается nil.
+	Suggest(ctx context.Context, filter SongFilter) (*models.SongSuggestion, error)
+	// Search ищет песни по названию, группе и тексту с учетом словоформ и возвращает страницу результатов
+	// по убыванию релевантности и общее количество найденных песен. Если хранилище не поддерживает
+	// полнотекстовый поиск, возвращается ErrNotSupported.
+	Search(ctx context.Context, query string, offset, limit int) ([]models.SongSearchHit, int64, error)
+	// FuzzySearch ищет песни, название или группа которых похожи на query с точностью до опечаток,
+	// и возвращает страницу результатов по убыванию сходства и общее количество найденных песен.
+	// Если хранилище не поддерживает поиск по сходству, возвращается ErrNotSupported.
+	FuzzySearch(ctx context.Context, query string, offset, limit int) ([]models.SongSimilarityHit, int64, error)
+	// Autocomplete возвращает до limit различных названий групп (field равен "group") или песен ("song"),
+	// начинающихся с prefix или содержащих слово, начинающееся с prefix, без учета регистра. Сначала идут
+	// названия, начинающиеся с prefix целиком, затем остальные; внутри — по убыванию количества песен.
+	Autocomplete(ctx context.Context, field, prefix string, limit int) ([]models.Suggestion, error)
+}
+
+// AutocompleteFields перечисляет значения field, которые принимает SongRepository.Autocomplete.
+var AutocompleteFields = []string{"group", "song"}
+
+// SongSortValue возвращает значение поля сортировки песни в строковом виде, в котором его
+// сравнивают реализации SongRepository. Песни без даты выпуска считаются самыми ранними.
+func SongSortValue(name string, song models.Song) string {
+	switch name {
+	case "group":
+		return song.Group
+	case "song":
+		return song.Song
+	case "releaseDate":
+		if song.ReleaseDate.IsZero() {
+			return "0001-01-01"
+		}
+		return song.ReleaseDate.Format("2006-01-02")
+	}
+	return ""
+}
+
+// songSources возвращает провайдеров полей песни after, полученной изменением before: поля, изменённые
+// без нового источника, больше не считаются полученными из внешнего API, а sources задаёт источники
+// значений, полученных в этом изменении.
+func songSources(before, after models.Song, sources models.SongSources) models.SongSources {
+	result := make(models.SongSources)
+	for field, provider := range before.EnrichmentSources {
+		result[field] = provider
+	}
+	for _, field := range changedSongFields(before, after) {
+		delete(result, field)
+	}
+	for field, provider := range sources {
+		result[field] = provider
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// changedSongFields возвращает имена полей JSON, которыми песни before и after различаются.
+// Поисковые ключи не сравниваются: они вычисляются из названий.
+func changedSongFields(before, after models.Song) []string {
+	fields := []string{}
+	if before.GroupID != after.GroupID {
+		fields = append(fields, "groupId")
+	}
+	if before.Group != after.Group {
+		fields = append(fields, "group")
+	}
+	if before.Song != after.Song {
+		fields = append(fields, "song")
+	}
+	if before.ReleaseDate.String() != after.ReleaseDate.String() {
+		fields = append(fields, "releaseDate")
+	}
+	if before.Text != after.Text {
+		fields = append(fields, "text")
+	}
+	if before.Link != after.Link {
+		fields = append(fields, "link")
+	}
+	return fields
+}
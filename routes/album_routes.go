@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"MusicLibrary/controllers"
+	"MusicLibrary/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// setupAlbumRoutes регистрирует маршруты для работы с альбомами и их треклистами.
+func setupAlbumRoutes(r *gin.Engine, logger *logrus.Logger, albums repository.AlbumRepository) {
+	albumRoutes := r.Group("/albums")
+	{
+		// GET /albums — маршрут для получения всех альбомов
+		logger.Infof("Setting up route: GET /albums")
+		albumRoutes.GET("", controllers.GetAllAlbums(logger, albums))
+
+		// GET /albums/{id} — маршрут для получения альбома по ID
+		logger.Infof("Setting up route: GET /albums/{id}")
+		albumRoutes.GET("/:id", controllers.GetAlbum(logger, albums))
+
+		// POST /albums — маршрут для создания нового альбома
+		logger.Infof("Setting up route: POST /albums")
+		albumRoutes.POST("", controllers.CreateAlbum(logger, albums))
+
+		// PATCH /albums/{id} — маршрут для обновления данных об альбоме по ID
+		logger.Infof("Setting up route: PATCH /albums/{id}")
+		albumRoutes.PATCH("/:id", controllers.UpdateAlbum(logger, albums))
+
+		// DELETE /albums/{id} — маршрут для удаления альбома по ID
+		logger.Infof("Setting up route: DELETE /albums/{id}")
+		albumRoutes.DELETE("/:id", controllers.DeleteAlbum(logger, albums))
+
+		// GET /albums/{id}/tracks — маршрут для получения упорядоченного треклиста альбома
+		logger.Infof("Setting up route: GET /albums/{id}/tracks")
+		albumRoutes.GET("/:id/tracks", controllers.GetAlbumTracks(logger, albums))
+
+		// PUT /albums/{id}/tracks/{songId} — маршрут для добавления песни в альбом или изменения её позиции
+		logger.Infof("Setting up route: PUT /albums/{id}/tracks/{songId}")
+		albumRoutes.PUT("/:id/tracks/:songId", controllers.SetAlbumTrack(logger, albums))
+
+		// DELETE /albums/{id}/tracks/{songId} — маршрут для удаления песни из альбома
+		logger.Infof("Setting up route: DELETE /albums/{id}/tracks/{songId}")
+		albumRoutes.DELETE("/:id/tracks/:songId", controllers.DeleteAlbumTrack(logger, albums))
+	}
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"MusicLibrary/controllers"
+	"MusicLibrary/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// setupGroupRoutes регистрирует маршруты для работы с группами.
+func setupGroupRoutes(r *gin.Engine, logger *logrus.Logger, groups repository.GroupRepository) {
+	// Переименование группы записывается в историю её песен, поэтому автор изменения берётся из запроса
+	groupRoutes := r.Group("/groups", controllers.Actor())
+	{
+		// GET /groups — маршрут для получения всех групп
+		logger.Infof("Setting up route: GET /groups")
+		groupRoutes.GET("", controllers.GetAllGroups(logger, groups))
+
+		// GET /groups/{id} — маршрут для получения группы по ID
+		logger.Infof("Setting up route: GET /groups/{id}")
+		groupRoutes.GET("/:id", controllers.GetGroup(logger, groups))
+
+		// POST /groups — маршрут для создания новой группы
+		logger.Infof("Setting up route: POST /groups")
+		groupRoutes.POST("", controllers.CreateGroup(logger, groups))
+
+		// PATCH /groups/{id} — маршрут для обновления данных о группе по ID
+		logger.Infof("Setting up route: PATCH /groups/{id}")
+		groupRoutes.PATCH("/:id", controllers.UpdateGroup(logger, groups))
+
+		// DELETE /groups/{id} — маршрут для удаления группы по ID
+		logger.Infof("Setting up route: DELETE /groups/{id}")
+		groupRoutes.DELETE("/:id", controllers.DeleteGroup(logger, groups))
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"MusicLibrary/controllers"
+	"MusicLibrary/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// setupJobRoutes регистрирует маршруты для отслеживания фоновых задач.
+func setupJobRoutes(r *gin.Engine, logger *logrus.Logger, jobs repository.JobRepository) {
+	jobRoutes := r.Group("/jobs")
+	{
+		// GET /jobs/{id} — маршрут для получения состояния фоновой задачи
+		logger.Infof("Setting up route: GET /jobs/{id}")
+		jobRoutes.GET("/:id", controllers.GetJob(logger, jobs))
+	}
+}
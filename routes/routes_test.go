@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"MusicLibrary/repository"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// TestSetupRouterWithMemoryRepositories проверяет, что все маршруты работают поверх хранилищ в памяти без базы данных.
+func TestSetupRouterWithMemoryRepositories(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	songs := repository.NewMemorySongRepository()
+	router := SetupRouter(logger, songs, repository.NewMemoryJobRepository(songs),
+		repository.NewMemoryGroupRepository(songs), repository.NewMemoryAlbumRepository(songs), Options{EnrichMaxAttempts: 3, BatchWorkers: 4})
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{http.MethodPost, "/songs", `{"group":"Muse","song":"Hysteria"}`, http.StatusAccepted},
+		{http.MethodGet, "/songs", "", http.StatusOK},
+		{http.MethodGet, "/songs/search?q=hysteria", "", http.StatusNotImplemented},
+		{http.MethodGet, "/songs/fuzzy?q=hysteria", "", http.StatusNotImplemented},
+		{http.MethodGet, "/suggest?prefix=hys", "", http.StatusOK},
+		{http.MethodGet, "/songs/1/revisions", "", http.StatusOK},
+		{http.MethodGet, "/jobs/1", "", http.StatusOK},
+		{http.MethodPost, "/groups", `{"name":"Placebo","aliases":["Ash Wednesday"]}`, http.StatusOK},
+		{http.MethodPost, "/groups", `{"name":"ash wednesday"}`, http.StatusConflict},
+		{http.MethodGet, "/groups?name=wednesday", "", http.StatusOK},
+		{http.MethodPatch, "/groups/1", `{"name":"MUSE"}`, http.StatusOK},
+		{http.MethodDelete, "/groups/1", "", http.StatusConflict},
+		{http.MethodDelete, "/groups/2", "", http.StatusOK},
+		{http.MethodGet, "/groups/2", "", http.StatusNotFound},
+		{http.MethodPost, "/albums", `{"title":"Absolution","group":"Muse"}`, http.StatusOK},
+		{http.MethodGet, "/albums?group=muse", "", http.StatusOK},
+		{http.MethodPut, "/albums/1/tracks/1", `{"trackNumber":1}`, http.StatusOK},
+		{http.MethodPut, "/albums/1/tracks/42", `{"trackNumber":2}`, http.StatusNotFound},
+		{http.MethodGet, "/albums/1/tracks", "", http.StatusOK},
+		{http.MethodDelete, "/albums/1/tracks/1", "", http.StatusOK},
+		{http.MethodDelete, "/albums/1/tracks/1", "", http.StatusNotFound},
+		{http.MethodDelete, "/albums/1", "", http.StatusOK},
+		{http.MethodGet, "/albums/1", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+		if tt.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: status %d, want %d, body %s", tt.method, tt.target, w.Code, tt.want, w.Body)
+		}
+	}
+}
+
+// TestSetupRouterRequireIfMatch проверяет, что настройка RequireIfMatch передаётся обработчикам изменения песни.
+func TestSetupRouterRequireIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	songs := repository.NewMemorySongRepository()
+	router := SetupRouter(logger, songs, repository.NewMemoryJobRepository(songs),
+		repository.NewMemoryGroupRepository(songs), repository.NewMemoryAlbumRepository(songs),
+		Options{EnrichMaxAttempts: 3, BatchWorkers: 4, RequireIfMatch: true})
+
+	tests := []struct {
+		method  string
+		body    string
+		ifMatch string
+		want    int
+	}{
+		{http.MethodPost, `{"group":"Muse","song":"Hysteria"}`, "", http.StatusAccepted},
+		{http.MethodPatch, `{"song":"Starlight"}`, "", http.StatusPreconditionRequired},
+		{http.MethodPut, `{"group":"Muse","song":"Starlight"}`, "", http.StatusPreconditionRequired},
+		{http.MethodDelete, "", "", http.StatusPreconditionRequired},
+		{http.MethodPatch, `{"song":"Starlight"}`, `"1"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		target := "/songs/1"
+		if tt.method == http.MethodPost {
+			target = "/songs"
+		}
+		req := httptest.NewRequest(tt.method, target, strings.NewReader(tt.body))
+		if tt.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: status %d, want %d, body %s", tt.method, target, w.Code, tt.want, w.Body)
+		}
+	}
+}
@@ -1,7 +1,7 @@
 /*
 Package routes содержит настройки маршрутов для приложения MusicLibrary.
-В этом пакете определяются маршруты для обработки запросов, связанных с песнями,
-такие как получение, создание, обновление и удаление песен.
+В этом пакете определяются маршруты для обработки запросов, связанных с песнями, группами и альбомами,
+такие как получение, создание, обновление и удаление песен, групп и альбомов.
 Каждый маршрут регистрирует соответствующий обработчик, обеспечивая необходимую функциональность.
 */
 
@@ -9,41 +9,121 @@ package routes
 
 import (
 	"MusicLibrary/controllers"
+	"MusicLibrary/repository"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
+// Options содержит настройки обработчиков, которые читаются из окружения один раз при запуске.
+type Options struct {
+	// EnrichMaxAttempts — сколько попыток получает задача обогащения новой песни
+	EnrichMaxAttempts int
+	// BatchWorkers — сколько песен пакетного создания и импорта обогащается одновременно
+	BatchWorkers int
+	// RequireIfMatch делает заголовок If-Match обязательным для изменения и удаления песни
+	RequireIfMatch bool
+}
+
 // SetupRouter создает маршруты для приложения и регистрирует обработчики запросов для работы с песнями.
+// Обработчики песен работают с хранилищем songs, групп — с groups, альбомов — с albums, а состояние
+// фоновых задач читается из очереди jobs, что позволяет подставить любые реализации хранилищ, например в памяти.
+// Настройки обработчиков передаются в opts.
 // @Summary Настройка маршрутов для работы с песнями
 // @Description Определение маршрутов для получения, создания, обновления и удаления песен.
 // @Tags songs
-func SetupRouter(logger *logrus.Logger) *gin.Engine {
+func SetupRouter(logger *logrus.Logger, songs repository.SongRepository, jobs repository.JobRepository, groups repository.GroupRepository, albums repository.AlbumRepository, opts Options) *gin.Engine {
 	r := gin.Default() // Создаем экземпляр роутера Gin
 
 	// Группа маршрутов для работы с песнями
-	songRoutes := r.Group("/songs")
+	// Автор изменений песен записывается в историю версий
+	songRoutes := r.Group("/songs", controllers.Actor())
 	{
 		// GET /songs — маршрут для получения всех песен
 		logger.Infof("Setting up route: GET /songs")
-		songRoutes.GET("", controllers.GetAllSongs(logger))
+		songRoutes.GET("", controllers.GetAllSongs(logger, songs))
+
+		// GET /songs/export — маршрут для выгрузки песен в файл
+		logger.Infof("Setting up route: GET /songs/export")
+		songRoutes.GET("/export", controllers.ExportSongs(logger, songs))
+
+		// GET /songs/search — маршрут для полнотекстового поиска песен
+		logger.Infof("Setting up route: GET /songs/search")
+		songRoutes.GET("/search", controllers.SearchSongs(logger, songs))
+
+		// GET /songs/fuzzy — маршрут для поиска песен с опечатками
+		logger.Infof("Setting up route: GET /songs/fuzzy")
+		songRoutes.GET("/fuzzy", controllers.FuzzySearchSongs(logger, songs))
+
+		// GET /songs/trash — маршрут для получения песен в корзине
+		logger.Infof("Setting up route: GET /songs/trash")
+		songRoutes.GET("/trash", controllers.GetTrash(logger, songs))
+
+		// DELETE /songs/trash/{id} — маршрут для окончательного удаления песни из корзины
+		logger.Infof("Setting up route: DELETE /songs/trash/{id}")
+		songRoutes.DELETE("/trash/:id", controllers.PurgeSong(logger, songs))
+
+		// GET /songs/{id} — маршрут для получения песни по ID
+		logger.Infof("Setting up route: GET /songs/{id}")
+		songRoutes.GET("/:id", controllers.GetSong(logger, songs))
 
 		// GET /songs/{id}/verses — маршрут для получения куплетов песни по ID
 		logger.Infof("Setting up route: GET /songs/{id}/verses")
-		songRoutes.GET("/:id/verses", controllers.GetSongVerses(logger))
+		songRoutes.GET("/:id/verses", controllers.GetSongVerses(logger, songs))
+
+		// GET /songs/{id}/revisions — маршрут для получения истории изменений песни
+		logger.Infof("Setting up route: GET /songs/{id}/revisions")
+		songRoutes.GET("/:id/revisions", controllers.GetSongRevisions(logger, songs))
+
+		// GET /songs/{id}/revisions/{rev} — маршрут для получения версии песни
+		logger.Infof("Setting up route: GET /songs/{id}/revisions/{rev}")
+		songRoutes.GET("/:id/revisions/:rev", controllers.GetSongRevision(logger, songs))
+
+		// POST /songs/{id}/revisions/{rev}/revert — маршрут для отката песни к версии
+		logger.Infof("Setting up route: POST /songs/{id}/revisions/{rev}/revert")
+		songRoutes.POST("/:id/revisions/:rev/revert", controllers.RevertSongRevision(logger, songs))
 
 		// POST /songs — маршрут для создания новой песни
 		logger.Infof("Setting up route: POST /songs")
-		songRoutes.POST("", controllers.CreateSong(logger))
+		songRoutes.POST("", controllers.CreateSong(logger, songs, opts.EnrichMaxAttempts))
+
+		// POST /songs/batch — маршрут для пакетного создания песен
+		logger.Infof("Setting up route: POST /songs/batch")
+		songRoutes.POST("/batch", controllers.CreateSongsBatch(logger, songs, opts.BatchWorkers))
+
+		// POST /songs/import — маршрут для импорта песен из файла CSV, JSON или NDJSON
+		logger.Infof("Setting up route: POST /songs/import")
+		songRoutes.POST("/import", controllers.ImportSongs(logger, songs, opts.BatchWorkers))
 
 		// PATCH /songs/{id} — маршрут для обновления данных о песне по ID
 		logger.Infof("Setting up route: PATCH /songs/{id}")
-		songRoutes.PATCH("/:id", controllers.UpdateSong(logger))
+		songRoutes.PATCH("/:id", controllers.UpdateSong(logger, songs, opts.RequireIfMatch))
 
-		// DELETE /songs/{id} — маршрут для удаления песни по ID
+		// PUT /songs/{id} — маршрут для полной замены песни по ID
+		logger.Infof("Setting up route: PUT /songs/{id}")
+		songRoutes.PUT("/:id", controllers.ReplaceSong(logger, songs, opts.RequireIfMatch))
+
+		// DELETE /songs/{id} — маршрут для перемещения песни в корзину по ID
 		logger.Infof("Setting up route: DELETE /songs/{id}")
-		songRoutes.DELETE("/:id", controllers.DeleteSong(logger))
+		songRoutes.DELETE("/:id", controllers.DeleteSong(logger, songs, opts.RequireIfMatch))
+
+		// POST /songs/{id}/restore — маршрут для восстановления песни из корзины
+		logger.Infof("Setting up route: POST /songs/{id}/restore")
+		songRoutes.POST("/:id/restore", controllers.RestoreSong(logger, songs))
 	}
 
+	// GET /suggest — маршрут для автодополнения названий групп и песен
+	logger.Infof("Setting up route: GET /suggest")
+	r.GET("/suggest", controllers.Suggest(logger, songs))
+
+	// Маршруты для работы с группами
+	setupGroupRoutes(r, logger, groups)
+
+	// Маршруты для работы с альбомами
+	setupAlbumRoutes(r, logger, albums)
+
+	// Маршруты для отслеживания фоновых задач
+	setupJobRoutes(r, logger, jobs)
+
 	return r
 }
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker приостанавливает запросы к внешней службе после threshold неудачных запросов подряд.
+// Через cooldown пропускается один пробный запрос: его успех возобновляет запросы, а неудача
+// снова приостанавливает их на cooldown.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int // 0 отключает размыкатель
+	cooldown  time.Duration
+	failures  int       // Неудачные запросы подряд
+	openUntil time.Time // До этого времени запросы не выполняются
+	probing   bool      // Выполняется пробный запрос
+}
+
+// newCircuitBreaker создаёт размыкатель цепи.
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow проверяет, можно ли выполнить запрос. Каждый разрешённый запрос должен завершиться вызовом
+// record или release.
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record учитывает результат запроса.
+func (b *circuitBreaker) record(success bool) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// release завершает запрос, результат которого не говорит о состоянии службы, например отменённый вызывающим.
+func (b *circuitBreaker) release() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	breaker := newCircuitBreaker(2, 20*time.Millisecond)
+	fail := func() {
+		t.Helper()
+		if !breaker.allow() {
+			t.Fatal("request was suspended before the threshold")
+		}
+		breaker.record(false)
+	}
+
+	// Успешный запрос сбрасывает счётчик неудач
+	fail()
+	breaker.allow()
+	breaker.record(true)
+	fail()
+	if !breaker.allow() {
+		t.Fatal("breaker opened after one failure in a row")
+	}
+	breaker.record(false)
+	if breaker.allow() {
+		t.Fatal("breaker stayed closed after two failures in a row")
+	}
+
+	// После cooldown пропускается только один пробный запрос
+	time.Sleep(30 * time.Millisecond)
+	if !breaker.allow() {
+		t.Fatal("probe request was not allowed after the cooldown")
+	}
+	if breaker.allow() {
+		t.Fatal("second request was allowed while the probe is running")
+	}
+	breaker.record(false)
+	if breaker.allow() {
+		t.Fatal("failed probe did not suspend requests again")
+	}
+
+	// Отменённая проба не меняет состояние, успешная закрывает размыкатель
+	time.Sleep(30 * time.Millisecond)
+	if !breaker.allow() {
+		t.Fatal("probe request was not allowed after the cooldown")
+	}
+	breaker.release()
+	if !breaker.allow() {
+		t.Fatal("probe request was not allowed after the cancelled probe")
+	}
+	breaker.record(true)
+	if !breaker.allow() || !breaker.allow() {
+		t.Fatal("successful probe did not resume requests")
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	breaker := newCircuitBreaker(0, time.Hour)
+	for i := 0; i < 10; i++ {
+		if !breaker.allow() {
+			t.Fatalf("disabled breaker suspended request %d", i+1)
+		}
+		breaker.record(false)
+	}
+}
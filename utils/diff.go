@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"MusicLibrary/models"
+	"strings"
+)
+
+// Операции построчного сравнения.
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLines сравнивает тексты построчно и возвращает строки старого и нового текста в порядке следования:
+// общие строки помечаются как equal, удалённые из старого текста — delete, добавленные в новый — insert.
+// В каждом изменённом фрагменте удалённые строки идут перед добавленными.
+// Общие строки находятся алгоритмом Майерса с поиском средней змеи, которому нужна память,
+// линейная по числу строк, поэтому сравнение длинных текстов не расходует память квадратично.
+func DiffLines(oldText, newText string) []models.DiffLine {
+	a, b := splitLines(oldText), splitLines(newText)
+	diff := make([]models.DiffLine, 0, len(a)+len(b))
+	diff = diffLines(diff, a, b)
+	return groupChanges(diff)
+}
+
+// diffLines дописывает к diff построчное сравнение a и b. Общие начало и конец отделяются сразу,
+// а оставшаяся часть делится средней змеёй на две части, которые сравниваются рекурсивно.
+func diffLines(diff []models.DiffLine, a, b []string) []models.DiffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		diff = append(diff, models.DiffLine{Op: DiffEqual, Text: a[prefix]})
+		prefix++
+	}
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-suffix-1] == b[len(b)-suffix-1] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			diff = append(diff, models.DiffLine{Op: DiffInsert, Text: line})
+		}
+	case len(b) == 0:
+		for _, line := range a {
+			diff = append(diff, models.DiffLine{Op: DiffDelete, Text: line})
+		}
+	default:
+		if x, y, ok := middleSnake(a, b); ok {
+			diff = diffLines(diff, a[:x], b[:y])
+			diff = diffLines(diff, a[x:], b[y:])
+		} else {
+			for _, line := range a {
+				diff = append(diff, models.DiffLine{Op: DiffDelete, Text: line})
+			}
+			for _, line := range b {
+				diff = append(diff, models.DiffLine{Op: DiffInsert, Text: line})
+			}
+		}
+	}
+
+	for _, line := range common {
+		diff = append(diff, models.DiffLine{Op: DiffEqual, Text: line})
+	}
+	return diff
+}
+
+// middleSnake одновременно строит кратчайшие пути редактирования от начала и от конца a и b
+// и возвращает точку (x, y), в которой они встретились: a[:x] и b[:y] сравниваются отдельно от a[x:] и b[y:].
+// Для каждой диагонали хранится только самая дальняя точка пути, поэтому память линейна.
+// Если общих строк нет, ok равно false.
+func middleSnake(a, b []string) (x, y int, ok bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// forward[offset+k] и backward[offset+k] — дальняя точка x на диагонали k пути от начала и от конца
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// При нечётной разнице длин пути встречаются на шаге пути от начала, при чётной — от конца
+	odd := delta%2 != 0
+	// Диагонали, вышедшие за границы текстов, больше не продлеваются
+	forwardStart, forwardEnd, backwardStart, backwardEnd := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + forwardStart; k <= d-forwardEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && forward[i-1] < forward[i+1]) {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+			switch {
+			case x > n:
+				forwardEnd += 2
+			case y > m:
+				forwardStart += 2
+			case odd:
+				j := offset + delta - k
+				if j >= 0 && j < len(backward) && backward[j] != -1 && x >= n-backward[j] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k := -d + backwardStart; k <= d-backwardEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[i] = x
+			switch {
+			case x > n:
+				backwardEnd += 2
+			case y > m:
+				backwardStart += 2
+			case !odd:
+				j := offset + delta - k
+				if j >= 0 && j < len(forward) && forward[j] != -1 {
+					fx := forward[j]
+					if fx >= n-x {
+						return fx, fx - (j - offset), true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// groupChanges переставляет строки каждого изменённого фрагмента так, чтобы удалённые шли перед добавленными.
+func groupChanges(diff []models.DiffLine) []models.DiffLine {
+	grouped := make([]models.DiffLine, 0, len(diff))
+	var inserted []models.DiffLine
+	for _, line := range diff {
+		switch line.Op {
+		case DiffInsert:
+			inserted = append(inserted, line)
+		case DiffDelete:
+			grouped = append(grouped, line)
+		default:
+			grouped = append(grouped, inserted...)
+			inserted = inserted[:0]
+			grouped = append(grouped, line)
+		}
+	}
+	return append(grouped, inserted...)
+}
+
+// splitLines делит текст на строки. У пустого текста строк нет.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"MusicLibrary/models"
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	equal := func(text string) models.DiffLine { return models.DiffLine{Op: DiffEqual, Text: text} }
+	insert := func(text string) models.DiffLine { return models.DiffLine{Op: DiffInsert, Text: text} }
+	remove := func(text string) models.DiffLine { return models.DiffLine{Op: DiffDelete, Text: text} }
+
+	tests := []struct {
+		name     string
+		old, new string
+		want     []models.DiffLine
+	}{
+		{name: "empty", want: []models.DiffLine{}},
+		{name: "equal", old: "a\nb", new: "a\nb", want: []models.DiffLine{equal("a"), equal("b")}},
+		{name: "insert", old: "a\nc", new: "a\nb\nc", want: []models.DiffLine{equal("a"), insert("b"), equal("c")}},
+		{name: "insert into empty", new: "a\nb", want: []models.DiffLine{insert("a"), insert("b")}},
+		{name: "delete", old: "a\nb\nc", new: "a\nc", want: []models.DiffLine{equal("a"), remove("b"), equal("c")}},
+		{name: "delete all", old: "a\nb", want: []models.DiffLine{remove("a"), remove("b")}},
+		{name: "replace", old: "a\nb\nc", new: "a\nx\nc", want: []models.DiffLine{equal("a"), remove("b"), insert("x"), equal("c")}},
+		{name: "replace all", old: "a\nb", new: "x\ny", want: []models.DiffLine{remove("a"), remove("b"), insert("x"), insert("y")}},
+		{name: "trailing newline added", old: "a\nb", new: "a\nb\n", want: []models.DiffLine{equal("a"), equal("b"), insert("")}},
+		{name: "trailing newline removed", old: "a\nb\n", new: "a\nb", want: []models.DiffLine{equal("a"), equal("b"), remove("")}},
+		{name: "crlf", old: "a\r\nb", new: "a\nb", want: []models.DiffLine{equal("a"), equal("b")}},
+		{name: "moved line", old: "a\nb\nc", new: "b\nc\na", want: []models.DiffLine{remove("a"), equal("b"), equal("c"), insert("a")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffLines(tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+			}
+		})
+	}
+}
+
+// lcsLength возвращает длину наибольшей общей подпоследовательности строк a и b.
+func lcsLength(a, b []string) int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	return lengths[0][0]
+}
+
+// TestDiffLinesMinimal сверяет сравнение случайных текстов с наибольшей общей подпоследовательностью:
+// из результата восстанавливаются оба текста, а общих строк столько же, сколько в подпоследовательности.
+func TestDiffLinesMinimal(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	randomLines := func() []string {
+		lines := make([]string, random.IntN(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + random.IntN(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 500; i++ {
+		a, b := randomLines(), randomLines()
+		var gotOld, gotNew []string
+		equal := 0
+		for _, line := range DiffLines(strings.Join(a, "\n"), strings.Join(b, "\n")) {
+			switch line.Op {
+			case DiffEqual:
+				gotOld, gotNew = append(gotOld, line.Text), append(gotNew, line.Text)
+				equal++
+			case DiffDelete:
+				gotOld = append(gotOld, line.Text)
+			case DiffInsert:
+				gotNew = append(gotNew, line.Text)
+			}
+		}
+		// Пустой текст не содержит строк, а не одну пустую строку
+		if len(a) == 0 {
+			a = nil
+		}
+		if len(b) == 0 {
+			b = nil
+		}
+		if !reflect.DeepEqual(gotOld, a) || !reflect.DeepEqual(gotNew, b) {
+			t.Fatalf("DiffLines(%q, %q) restores %q and %q", a, b, gotOld, gotNew)
+		}
+		if want := lcsLength(a, b); equal != want {
+			t.Fatalf("DiffLines(%q, %q) keeps %d equal lines, want %d", a, b, equal, want)
+		}
+	}
+}
+
+// TestDiffLinesLongText проверяет сравнение текста, для которого квадратичная таблица не поместилась бы в память.
+func TestDiffLinesLongText(t *testing.T) {
+	lines := make([]string, 100000)
+	for i := range lines {
+		lines[i] = strconv.Itoa(i)
+	}
+	changed := append(append(append([]string{}, lines[:50000]...), "new"), lines[50001:]...)
+
+	diff := DiffLines(strings.Join(lines, "\n"), strings.Join(changed, "\n"))
+	if len(diff) != len(lines)+1 {
+		t.Fatalf("%d diff lines, want %d", len(diff), len(lines)+1)
+	}
+	want := []models.DiffLine{{Op: DiffDelete, Text: "50000"}, {Op: DiffInsert, Text: "new"}}
+	if got := diff[50000:50002]; !reflect.DeepEqual(got, want) {
+		t.Errorf("changed lines %v, want %v", got, want)
+	}
+}
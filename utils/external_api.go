@@ -9,37 +9,328 @@ package utils
 
 import (
 	"MusicLibrary/models"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
 )
 
-// FetchSongDetails отправляет запрос к внешнему API для получения дополнительных данных о песне.
+// ErrCircuitOpen возвращается без запроса к внешнему API, пока размыкатель цепи открыт
+// после серии неудачных запросов.
+var ErrCircuitOpen = errors.New("external API is unavailable, requests are suspended")
+
+// ErrSongDetailsNotFound возвращается, если внешний API ответил 404: данных о песне у него нет.
+var ErrSongDetailsNotFound = errors.New("song details not found")
+
+// SongDetailsConfig задаёт параметры клиента внешнего API.
+type SongDetailsConfig struct {
+	Name             string        // Имя провайдера, под которым он указывается в приоритетах полей
+	URL              string        // Адрес метода внешнего API
+	Timeout          time.Duration // Время ожидания одной попытки, включая чтение ответа
+	MaxRetries       int           // Количество повторов после неудачной попытки с ответом 5xx, 429 или ошибкой сети
+	RetryDelay       time.Duration // Базовая задержка перед повтором; удваивается с каждым повтором
+	MaxRetryDelay    time.Duration // Наибольшая задержка перед повтором, в том числе по заголовку Retry-After
+	MaxBodySize      int64         // Наибольший размер ответа в байтах
+	BreakerThreshold int           // Количество неудачных запросов подряд, после которого запросы приостанавливаются; 0 отключает
+	BreakerCooldown  time.Duration // Время, на которое приостанавливаются запросы
+}
+
+// DefaultSongDetailsConfig возвращает параметры клиента по умолчанию для адреса apiURL.
+func DefaultSongDetailsConfig(apiURL string) SongDetailsConfig {
+	return SongDetailsConfig{
+		Name:             DefaultProviderName,
+		URL:              apiURL,
+		Timeout:          10 * time.Second,
+		MaxRetries:       2,
+		RetryDelay:       200 * time.Millisecond,
+		MaxRetryDelay:    5 * time.Second,
+		MaxBodySize:      1 << 20,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// SongDetailsConfigFromEnv читает параметры клиента из переменных окружения EXTERNAL_API_*;
+// для незаданных переменных используются значения DefaultSongDetailsConfig.
+func SongDetailsConfigFromEnv() (SongDetailsConfig, error) {
+	config := DefaultSongDetailsConfig(os.Getenv("EXTERNAL_API_URL"))
+	err := applySongDetailsEnv(&config, "EXTERNAL_API_")
+	return config, err
+}
+
+// applySongDetailsEnv заменяет параметры config значениями заданных переменных окружения с префиксом prefix.
+func applySongDetailsEnv(config *SongDetailsConfig, prefix string) error {
+	if value := os.Getenv(prefix + "URL"); value != "" {
+		config.URL = value
+	}
+
+	durations := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"TIMEOUT", &config.Timeout},
+		{"RETRY_DELAY", &config.RetryDelay},
+		{"MAX_RETRY_DELAY", &config.MaxRetryDelay},
+		{"BREAKER_COOLDOWN", &config.BreakerCooldown},
+	}
+	for _, setting := range durations {
+		if value := os.Getenv(prefix + setting.name); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("invalid %s %q, expected a positive duration such as 500ms or 10s", prefix+setting.name, value)
+			}
+			*setting.value = d
+		}
+	}
+
+	numbers := []struct {
+		name  string
+		value *int
+	}{
+		{"MAX_RETRIES", &config.MaxRetries},
+		{"BREAKER_THRESHOLD", &config.BreakerThreshold},
+	}
+	for _, setting := range numbers {
+		if value := os.Getenv(prefix + setting.name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid %s %q, expected a non-negative number", prefix+setting.name, value)
+			}
+			*setting.value = n
+		}
+	}
+
+	if value := os.Getenv(prefix + "MAX_BODY_SIZE"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid %sMAX_BODY_SIZE %q, expected a positive number of bytes", prefix, value)
+		}
+		config.MaxBodySize = n
+	}
+	return nil
+}
+
+// SongDetailsClient запрашивает данные песен во внешнем API: ограничивает время и размер ответа,
+// повторяет запрос при временных ошибках и приостанавливает запросы, если внешний API недоступен.
+// Клиент безопасен для одновременного использования.
+type SongDetailsClient struct {
+	config  SongDetailsConfig
+	http    *http.Client
+	breaker *circuitBreaker
+}
+
+// NewSongDetailsClient создаёт клиент внешнего API с параметрами config.
+func NewSongDetailsClient(config SongDetailsConfig) *SongDetailsClient {
+	return &SongDetailsClient{
+		config:  config,
+		http:    &http.Client{},
+		breaker: newCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown),
+	}
+}
+
+// Name возвращает имя провайдера из параметров клиента.
+func (c *SongDetailsClient) Name() string {
+	return c.config.Name
+}
+
+// defaultSongDetailsProviders используется функцией FetchSongDetails.
+var defaultSongDetailsProviders atomic.Pointer[ProviderChain]
+
+// InitSongDetailsProviders настраивает провайдеров, которых опрашивает FetchSongDetails, по переменным окружения
+// (см. ProviderChainFromEnv). Вызывается при запуске после загрузки .env; без вызова используется один
+// провайдер EXTERNAL_API_URL с параметрами по умолчанию.
+func InitSongDetailsProviders() error {
+	chain, err := ProviderChainFromEnv()
+	if err != nil {
+		return err
+	}
+	defaultSongDetailsProviders.Store(chain)
+	return nil
+}
+
+// FetchSongDetails запрашивает дополнительные данные о песне у настроенных провайдеров и объединяет их ответы.
+// Запрос отменяется вместе с ctx, например когда клиент HTTP-запроса разорвал соединение.
 // @Summary Запрос к внешнему API для обогащения данных песни
-// @Description Эта функция отправляет GET-запрос к внешнему API для получения информации о песне, включая дату выпуска, текст и ссылку на видео.
-func FetchSongDetails(group, song string) (*models.SongDetail, error) {
+// @Description Эта функция отправляет GET-запросы к внешним API для получения информации о песне, включая дату выпуска, текст и ссылку на видео.
+func FetchSongDetails(ctx context.Context, group, song string) (*models.SongDetail, error) {
+	chain := defaultSongDetailsProviders.Load()
+	if chain == nil {
+		client := NewSongDetailsClient(DefaultSongDetailsConfig(os.Getenv("EXTERNAL_API_URL")))
+		defaultSongDetailsProviders.CompareAndSwap(nil, singleProviderChain(client))
+		chain = defaultSongDetailsProviders.Load()
+	}
+	return chain.Fetch(ctx, group, song)
+}
+
+// Fetch запрашивает данные песни. Ответы 5xx и 429 и ошибки сети повторяются до MaxRetries раз
+// со случайной экспоненциально растущей задержкой; заголовок Retry-After задаёт задержку явно.
+// Если внешний API не ответил успешно BreakerThreshold запросов подряд, следующие запросы
+// в течение BreakerCooldown сразу завершаются ошибкой ErrCircuitOpen.
+func (c *SongDetailsClient) Fetch(ctx context.Context, group, song string) (*models.SongDetail, error) {
+	if !c.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
 	// Формируем URL запроса к внешнему API с экранированием параметров группы и песни
-	apiURL := fmt.Sprintf("%s?group=%s&song=%s", os.Getenv("EXTERNAL_API_URL"), url.QueryEscape(group), url.QueryEscape(song))
+	apiURL := fmt.Sprintf("%s?group=%s&song=%s", c.config.URL, url.QueryEscape(group), url.QueryEscape(song))
+
+	for attempt := 0; ; attempt++ {
+		detail, retryAfter, err := c.fetchOnce(ctx, apiURL)
+		if err == nil || !isRetryable(err) {
+			// Ответ без повтора, в том числе 4xx, показывает, что внешний API доступен
+			c.breaker.record(true)
+			return detail, err
+		}
+		if ctx.Err() != nil {
+			// Запрос отменил вызывающий, а не внешний API: попытка не считается неудачной
+			c.breaker.release()
+			return nil, ctx.Err()
+		}
+
+		delay := c.backoff(attempt)
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		if attempt >= c.config.MaxRetries || delay > c.config.MaxRetryDelay {
+			c.breaker.record(false)
+			return nil, fmt.Errorf("%w (after %d attempts)", err, attempt+1)
+		}
+
+		select {
+		case <-ctx.Done():
+			c.breaker.release()
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryableError — ошибка попытки, после которой запрос имеет смысл повторить.
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string { return e.err.Error() }
+func (e retryableError) Unwrap() error { return e.err }
+
+// isRetryable проверяет, можно ли повторить запрос после ошибки err.
+func isRetryable(err error) bool {
+	var retryable retryableError
+	return errors.As(err, &retryable)
+}
+
+// permanentError — ошибка ответа внешнего API, который не изменится при повторе запроса:
+// ответ 4xx, кроме 429, некорректный JSON или слишком большой ответ.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// IsPermanentError проверяет, что повтор запроса данных песни не поможет: у внешнего API нет данных
+// о песне (ErrSongDetailsNotFound) или его ответ не изменится. Объединённая ошибка нескольких провайдеров
+// постоянна, только если постоянны ошибки всех провайдеров. Ошибки сети, ответы 5xx и 429, ErrCircuitOpen
+// и отмена запроса постоянными не считаются.
+func IsPermanentError(err error) bool {
+	if err == ErrSongDetailsNotFound {
+		return true
+	}
+	switch e := err.(type) {
+	case permanentError:
+		return true
+	case interface{ Unwrap() []error }:
+		errs := e.Unwrap()
+		for _, err := range errs {
+			if !IsPermanentError(err) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	case interface{ Unwrap() error }:
+		return IsPermanentError(e.Unwrap())
+	}
+	return false
+}
+
+// fetchOnce выполняет одну попытку запроса. Для ответов 429 и 503 возвращает задержку из заголовка Retry-After.
+func (c *SongDetailsClient) fetchOnce(ctx context.Context, apiURL string) (*models.SongDetail, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
 
-	// Отправляем GET-запрос к API
-	resp, err := http.Get(apiURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := c.http.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, 0, retryableError{err}
 	}
 	defer resp.Body.Close()
 
 	// Проверяем успешность запроса по статус-коду
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get song details: %v", resp.Status)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, 0, ErrSongDetailsNotFound
+		}
+		err := fmt.Errorf("failed to get song details: %v", resp.Status)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+			return nil, parseRetryAfter(resp.Header.Get("Retry-After")), retryableError{err}
+		}
+		return nil, 0, permanentError{err}
+	}
+
+	// Ответ читается не больше MaxBodySize байт: лишний байт показывает, что ответ слишком большой
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.config.MaxBodySize+1))
+	if err != nil {
+		return nil, 0, retryableError{err}
+	}
+	if int64(len(body)) > c.config.MaxBodySize {
+		return nil, 0, permanentError{fmt.Errorf("song details response exceeds %d bytes", c.config.MaxBodySize)}
 	}
 
 	// Декодируем JSON-ответ в структуру SongDetail
 	var songDetail models.SongDetail
-	if err := json.NewDecoder(resp.Body).Decode(&songDetail); err != nil {
-		return nil, err
+	if err := json.Unmarshal(body, &songDetail); err != nil {
+		return nil, 0, permanentError{fmt.Errorf("invalid song details response: %w", err)}
 	}
+	return &songDetail, 0, nil
+}
+
+// backoff возвращает задержку перед повтором после попытки attempt (начиная с 0): случайное значение
+// от нуля до RetryDelay*2^attempt, но не больше MaxRetryDelay. Случайность не даёт клиентам повторять
+// запросы одновременно.
+func (c *SongDetailsClient) backoff(attempt int) time.Duration {
+	ceiling := c.config.RetryDelay
+	for i := 0; i < attempt && ceiling < c.config.MaxRetryDelay; i++ {
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, c.config.MaxRetryDelay)
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
 
-	return &songDetail, nil
+// parseRetryAfter разбирает заголовок Retry-After: число секунд или дату HTTP. Возвращает 0,
+// если заголовка нет или он некорректен.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
 }
@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{value: ""},
+		{value: "3", min: 3 * time.Second, max: 3 * time.Second},
+		{value: "0"},
+		{value: "-1"},
+		{value: "soon"},
+		{value: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), min: 58 * time.Second, max: time.Minute},
+		{value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value); got < tt.min || got > tt.max {
+			t.Errorf("parseRetryAfter(%q) = %s, want between %s and %s", tt.value, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	client := NewSongDetailsClient(SongDetailsConfig{RetryDelay: 100 * time.Millisecond, MaxRetryDelay: time.Second})
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{attempt: 0, ceiling: 100 * time.Millisecond},
+		{attempt: 1, ceiling: 200 * time.Millisecond},
+		{attempt: 3, ceiling: 800 * time.Millisecond},
+		{attempt: 10, ceiling: time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := client.backoff(tt.attempt); got <= 0 || got > tt.ceiling {
+				t.Fatalf("backoff(%d) = %s, want between 0 and %s", tt.attempt, got, tt.ceiling)
+			}
+		}
+	}
+}
+
+func TestSongDetailsClientFetch(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []int // Статусы ответов внешнего API по порядку; последний повторяется
+		body      string
+		wantErr   error
+		wantCalls int32
+	}{
+		{name: "success", responses: []int{http.StatusOK}, body: `{"text":"verse"}`, wantCalls: 1},
+		{name: "retried server error", responses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}, body: `{"text":"verse"}`, wantCalls: 3},
+		{name: "retries exhausted", responses: []int{http.StatusInternalServerError}, wantErr: errAny, wantCalls: 3},
+		{name: "not found", responses: []int{http.StatusNotFound}, wantErr: ErrSongDetailsNotFound, wantCalls: 1},
+		{name: "client error", responses: []int{http.StatusBadRequest}, wantErr: errAny, wantCalls: 1},
+		{name: "invalid json", responses: []int{http.StatusOK}, body: `verse`, wantErr: errAny, wantCalls: 1},
+		{name: "too large", responses: []int{http.StatusOK}, body: `{"text":"` + strings.Repeat("a", 64) + `"}`, wantErr: errAny, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1))
+				w.WriteHeader(tt.responses[min(n, len(tt.responses))-1])
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			config := DefaultSongDetailsConfig(server.URL)
+			config.RetryDelay, config.MaxBodySize = time.Millisecond, 32
+			detail, err := NewSongDetailsClient(config).Fetch(context.Background(), "Muse", "Hysteria")
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("external API called %d times, want %d", calls.Load(), tt.wantCalls)
+			}
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("Fetch succeeded with %+v, want an error", detail)
+				}
+				if tt.wantErr != errAny && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if detail.Text != "verse" {
+				t.Errorf("text %q, want %q", detail.Text, "verse")
+			}
+		})
+	}
+}
+
+func TestSongDetailsClientOpensCircuit(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	config := DefaultSongDetailsConfig(server.URL)
+	config.MaxRetries, config.BreakerThreshold, config.BreakerCooldown = 0, 2, time.Hour
+	client := NewSongDetailsClient(config)
+	for i := 0; i < 2; i++ {
+		if _, err := client.Fetch(context.Background(), "Muse", "Hysteria"); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("request %d: error %v, want a server error", i+1, err)
+		}
+	}
+	if _, err := client.Fetch(context.Background(), "Muse", "Hysteria"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error %v, want %v", err, ErrCircuitOpen)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("external API called %d times, want 2", calls.Load())
+	}
+}
+
+func TestSongDetailsConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    func(SongDetailsConfig) bool
+		wantErr bool
+	}{
+		{name: "defaults", want: func(c SongDetailsConfig) bool { return c.MaxRetries == 2 && c.Timeout == 10*time.Second }},
+		{name: "overrides", env: map[string]string{"EXTERNAL_API_TIMEOUT": "500ms", "EXTERNAL_API_MAX_RETRIES": "0", "EXTERNAL_API_MAX_BODY_SIZE": "1024"},
+			want: func(c SongDetailsConfig) bool {
+				return c.Timeout == 500*time.Millisecond && c.MaxRetries == 0 && c.MaxBodySize == 1024
+			}},
+		{name: "invalid duration", env: map[string]string{"EXTERNAL_API_RETRY_DELAY": "soon"}, wantErr: true},
+		{name: "negative duration", env: map[string]string{"EXTERNAL_API_BREAKER_COOLDOWN": "-1s"}, wantErr: true},
+		{name: "negative number", env: map[string]string{"EXTERNAL_API_BREAKER_THRESHOLD": "-1"}, wantErr: true},
+		{name: "invalid body size", env: map[string]string{"EXTERNAL_API_MAX_BODY_SIZE": "0"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			config, err := SongDetailsConfigFromEnv()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("config %+v, want an error", config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.want(config) {
+				t.Errorf("unexpected config %+v", config)
+			}
+		})
+	}
+}
+
+func TestIsPermanentError(t *testing.T) {
+	permanent := permanentError{errors.New("failed to get song details: 400 Bad Request")}
+	temporary := retryableError{errors.New("failed to get song details: 502 Bad Gateway")}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil},
+		{name: "not found", err: ErrSongDetailsNotFound, want: true},
+		{name: "wrapped not found", err: fmt.Errorf("failed to fetch song details: %w", ErrSongDetailsNotFound), want: true},
+		{name: "client error", err: permanent, want: true},
+		{name: "server error", err: temporary},
+		{name: "circuit open", err: ErrCircuitOpen},
+		{name: "cancelled", err: context.Canceled},
+		{name: "all providers failed permanently", err: errors.Join(fmt.Errorf("lyrics: %w", permanent), ErrSongDetailsNotFound), want: true},
+		{name: "one provider failed temporarily", err: errors.Join(fmt.Errorf("lyrics: %w", permanent), fmt.Errorf("video: %w", temporary))},
+	}
+	for _, tt := range tests {
+		if got := IsPermanentError(tt.err); got != tt.want {
+			t.Errorf("%s: IsPermanentError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrPatchTestFailed возвращается, если операция test JSON Patch не совпала с документом.
+var ErrPatchTestFailed = errors.New("JSON Patch test operation failed")
+
+// JSONPatchOperation описывает одну операцию JSON Patch (RFC 6902).
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyJSONPatch применяет операции JSON Patch к плоскому JSON-объекту doc и возвращает новый объект,
+// не изменяя исходный. Поддерживаются все операции RFC 6902, но только для полей верхнего уровня:
+// пути вида /name. Операции применяются по порядку; при ошибке документ не изменяется.
+// Если операция test не прошла, возвращается ErrPatchTestFailed.
+func ApplyJSONPatch(doc map[string]json.RawMessage, patch []byte) (map[string]json.RawMessage, error) {
+	var operations []JSONPatchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, errors.New("JSON Patch must be an array of operations")
+	}
+
+	result := make(map[string]json.RawMessage, len(doc))
+	for key, value := range doc {
+		result[key] = value
+	}
+
+	for i, operation := range operations {
+		name, err := patchMember(operation.Path)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+
+		switch operation.Op {
+		case "add", "replace", "test":
+			if operation.Value == nil {
+				return nil, fmt.Errorf("operation %d: %s requires a value", i, operation.Op)
+			}
+			current, ok := result[name]
+			if operation.Op != "add" && !ok {
+				return nil, fmt.Errorf("operation %d: path %s does not exist", i, operation.Path)
+			}
+			if operation.Op == "test" {
+				if !JSONEqual(current, operation.Value) {
+					return nil, fmt.Errorf("operation %d: %w", i, ErrPatchTestFailed)
+				}
+				continue
+			}
+			result[name] = operation.Value
+		case "remove":
+			if _, ok := result[name]; !ok {
+				return nil, fmt.Errorf("operation %d: path %s does not exist", i, operation.Path)
+			}
+			delete(result, name)
+		case "move", "copy":
+			from, err := patchMember(operation.From)
+			if err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+			value, ok := result[from]
+			if !ok {
+				return nil, fmt.Errorf("operation %d: path %s does not exist", i, operation.From)
+			}
+			if operation.Op == "move" {
+				delete(result, from)
+			}
+			result[name] = value
+		default:
+			return nil, fmt.Errorf("operation %d: unknown operation %q", i, operation.Op)
+		}
+	}
+	return result, nil
+}
+
+// MergePatchFromDiff возвращает JSON Merge Patch (RFC 7396), превращающий плоский объект before в after:
+// изменённые и добавленные поля передаются новыми значениями, удалённые — значением null.
+func MergePatchFromDiff(before, after map[string]json.RawMessage) map[string]json.RawMessage {
+	patch := make(map[string]json.RawMessage)
+	for key, value := range after {
+		if old, ok := before[key]; !ok || !JSONEqual(old, value) {
+			patch[key] = value
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			patch[key] = json.RawMessage("null")
+		}
+	}
+	return patch
+}
+
+// JSONEqual сравнивает два JSON-значения без учёта форматирования и порядка полей.
+func JSONEqual(a, b json.RawMessage) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var left, right interface{}
+	if json.Unmarshal(a, &left) != nil || json.Unmarshal(b, &right) != nil {
+		return false
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// patchMember разбирает указатель JSON (RFC 6901) на поле верхнего уровня и возвращает имя поля.
+func patchMember(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") || strings.Count(pointer, "/") != 1 {
+		return "", fmt.Errorf("unsupported path %q: only top-level fields can be patched", pointer)
+	}
+	name := strings.NewReplacer("~1", "/", "~0", "~").Replace(pointer[1:])
+	return name, nil
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// rawObject разбирает JSON-объект в плоский документ для ApplyJSONPatch.
+func rawObject(t *testing.T, data string) map[string]json.RawMessage {
+	t.Helper()
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// sameObject сравнивает документ с ожидаемым JSON-объектом без учёта форматирования.
+func sameObject(t *testing.T, doc map[string]json.RawMessage, want string) bool {
+	t.Helper()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return JSONEqual(data, json.RawMessage(want))
+}
+
+// errAny отмечает в таблице тестов, что подходит любая ошибка.
+var errAny = errors.New("any error")
+
+func TestApplyJSONPatch(t *testing.T) {
+	const doc = `{"song":"Hysteria","text":"verse","link":""}`
+	tests := []struct {
+		name    string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		{name: "replace", patch: `[{"op":"replace","path":"/song","value":"Uprising"}]`, want: `{"song":"Uprising","text":"verse","link":""}`},
+		{name: "add", patch: `[{"op":"add","path":"/releaseDate","value":"2003-12-01"}]`, want: `{"song":"Hysteria","text":"verse","link":"","releaseDate":"2003-12-01"}`},
+		{name: "remove", patch: `[{"op":"remove","path":"/link"}]`, want: `{"song":"Hysteria","text":"verse"}`},
+		{name: "move", patch: `[{"op":"move","from":"/text","path":"/link"}]`, want: `{"song":"Hysteria","link":"verse"}`},
+		{name: "copy", patch: `[{"op":"copy","from":"/song","path":"/text"}]`, want: `{"song":"Hysteria","text":"Hysteria","link":""}`},
+		{name: "test then replace", patch: `[{"op":"test","path":"/song","value":"Hysteria"},{"op":"replace","path":"/text","value":null}]`, want: `{"song":"Hysteria","text":null,"link":""}`},
+		{name: "escaped pointer", patch: `[{"op":"add","path":"/a~1b~0c","value":1}]`, want: `{"song":"Hysteria","text":"verse","link":"","a/b~c":1}`},
+		{name: "empty patch", patch: `[]`, want: doc},
+		{name: "test failed", patch: `[{"op":"test","path":"/song","value":"Uprising"}]`, wantErr: ErrPatchTestFailed},
+		{name: "not an array", patch: `{"op":"remove","path":"/link"}`, wantErr: errAny},
+		{name: "nested path", patch: `[{"op":"remove","path":"/song/0"}]`, wantErr: errAny},
+		{name: "root path", patch: `[{"op":"replace","path":"","value":{}}]`, wantErr: errAny},
+		{name: "replace missing", patch: `[{"op":"replace","path":"/missing","value":1}]`, wantErr: errAny},
+		{name: "remove missing", patch: `[{"op":"remove","path":"/missing"}]`, wantErr: errAny},
+		{name: "move from missing", patch: `[{"op":"move","from":"/missing","path":"/song"}]`, wantErr: errAny},
+		{name: "value required", patch: `[{"op":"add","path":"/song"}]`, wantErr: errAny},
+		{name: "unknown operation", patch: `[{"op":"append","path":"/song","value":"!"}]`, wantErr: errAny},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := rawObject(t, doc)
+			patched, err := ApplyJSONPatch(original, []byte(tt.patch))
+			if !sameObject(t, original, doc) {
+				t.Fatalf("source document was modified")
+			}
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("ApplyJSONPatch succeeded with %v, want an error", patched)
+				}
+				if tt.wantErr != errAny && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameObject(t, patched, tt.want) {
+				data, _ := json.Marshal(patched)
+				t.Errorf("patched document %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePatchFromDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{name: "unchanged", before: `{"a":1,"b":{"x":1,"y":2}}`, after: `{"a":1,"b":{"y":2,"x":1}}`, want: `{}`},
+		{name: "changed", before: `{"a":1,"b":"x"}`, after: `{"a":2,"b":"x"}`, want: `{"a":2}`},
+		{name: "added", before: `{"a":1}`, after: `{"a":1,"b":null}`, want: `{"b":null}`},
+		{name: "removed", before: `{"a":1,"b":2}`, after: `{"a":1}`, want: `{"b":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch := MergePatchFromDiff(rawObject(t, tt.before), rawObject(t, tt.after))
+			if !sameObject(t, patch, tt.want) {
+				data, _ := json.Marshal(patch)
+				t.Errorf("merge patch %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
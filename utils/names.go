@@ -0,0 +1,23 @@
+package utils
+
+import "strings"
+
+// NormalizeName приводит название группы или песни к каноническому виду:
+// убирает пробелы по краям и схлопывает повторяющиеся пробелы внутри строки.
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// NameKey возвращает ключ для сравнения названий без учета регистра и лишних пробелов.
+// Например, "Muse", "muse " и "MUSE" имеют одинаковый ключ "muse".
+func NameKey(name string) string {
+	return strings.ToLower(NormalizeName(name))
+}
+
+// likeEscaper экранирует специальные символы шаблона LIKE.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike экранирует символы %, _ и \, чтобы строка сравнивалась в шаблоне LIKE буквально.
+func EscapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
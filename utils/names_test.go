@@ -0,0 +1,44 @@
+package utils
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		key  string
+	}{
+		{name: "Muse", want: "Muse", key: "muse"},
+		{name: "  muse ", want: "muse", key: "muse"},
+		{name: "MUSE", want: "MUSE", key: "muse"},
+		{name: "Red  Hot\tChili\nPeppers", want: "Red Hot Chili Peppers", key: "red hot chili peppers"},
+		{name: "Кино", want: "Кино", key: "кино"},
+		{name: "   ", want: "", key: ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeName(tt.name); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if got := NameKey(tt.name); got != tt.key {
+			t.Errorf("NameKey(%q) = %q, want %q", tt.name, got, tt.key)
+		}
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "muse", want: "muse"},
+		{value: "100%", want: `100\%`},
+		{value: "a_b", want: `a\_b`},
+		{value: `AC\DC`, want: `AC\\DC`},
+		{value: `\%_`, want: `\\\%\_`},
+	}
+	for _, tt := range tests {
+		if got := EscapeLike(tt.value); got != tt.want {
+			t.Errorf("EscapeLike(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
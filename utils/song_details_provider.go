@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"MusicLibrary/models"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultProviderName — имя провайдера EXTERNAL_API_URL, если EXTERNAL_API_PROVIDERS не задана.
+const DefaultProviderName = "default"
+
+// SongDetailsProvider — источник дополнительных данных о песне: даты выпуска, текста и ссылки.
+// Если у провайдера нет данных о песне, Fetch возвращает ErrSongDetailsNotFound.
+type SongDetailsProvider interface {
+	// Name возвращает имя провайдера, которое указывается в приоритетах полей и в SongDetail.Sources.
+	Name() string
+	// Fetch запрашивает данные песни song группы group.
+	Fetch(ctx context.Context, group, song string) (*models.SongDetail, error)
+}
+
+// songDetailFields перечисляет поля SongDetail, которые объединяет ProviderChain: имя поля в JSON
+// и в SongDetail.Sources, суффикс переменной окружения с приоритетом поля и доступ к значению.
+var songDetailFields = []struct {
+	name  string
+	env   string
+	value func(detail *models.SongDetail) *string
+}{
+	{"releaseDate", "RELEASE_DATE", func(detail *models.SongDetail) *string { return &detail.ReleaseDate }},
+	{"text", "TEXT", func(detail *models.SongDetail) *string { return &detail.Text }},
+	{"link", "LINK", func(detail *models.SongDetail) *string { return &detail.Link }},
+}
+
+// ProviderChain опрашивает несколько провайдеров и собирает данные песни по полям: каждое поле берётся
+// у первого по приоритету поля провайдера, который вернул непустое значение. ProviderChain сам реализует
+// SongDetailsProvider.
+type ProviderChain struct {
+	providers []SongDetailsProvider
+	priority  map[string][]int // Поле -> индексы провайдеров в порядке приоритета
+}
+
+// NewProviderChain создаёт цепочку провайдеров. priority задаёт для поля (releaseDate, text, link)
+// имена провайдеров в порядке приоритета; поле, которого нет в priority, запрашивается у всех провайдеров
+// в порядке providers. Пустой список провайдеров поля считается ошибкой. Провайдер, не указанный в приоритете ни одного поля, не опрашивается.
+func NewProviderChain(providers []SongDetailsProvider, priority map[string][]string) (*ProviderChain, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("at least one song details provider is required")
+	}
+	index := make(map[string]int, len(providers))
+	for i, provider := range providers {
+		if _, ok := index[provider.Name()]; ok {
+			return nil, fmt.Errorf("duplicate song details provider %q", provider.Name())
+		}
+		index[provider.Name()] = i
+	}
+
+	chain := &ProviderChain{providers: providers, priority: make(map[string][]int, len(songDetailFields))}
+	for _, field := range songDetailFields {
+		names, ok := priority[field.name]
+		if !ok {
+			for i := range providers {
+				chain.priority[field.name] = append(chain.priority[field.name], i)
+			}
+			continue
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("%s priority must list at least one provider", field.name)
+		}
+		for _, name := range names {
+			i, ok := index[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown song details provider %q in %s priority", name, field.name)
+			}
+			chain.priority[field.name] = append(chain.priority[field.name], i)
+		}
+	}
+	for field := range priority {
+		if _, ok := chain.priority[field]; !ok {
+			return nil, fmt.Errorf("unknown song details field %q", field)
+		}
+	}
+	return chain, nil
+}
+
+// singleProviderChain создаёт цепочку из одного провайдера, который предоставляет все поля.
+func singleProviderChain(provider SongDetailsProvider) *ProviderChain {
+	chain := &ProviderChain{providers: []SongDetailsProvider{provider}, priority: make(map[string][]int, len(songDetailFields))}
+	for _, field := range songDetailFields {
+		chain.priority[field.name] = []int{0}
+	}
+	return chain
+}
+
+// providerNamePattern задаёт допустимые имена провайдеров: из имени составляются имена переменных окружения.
+var providerNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// ProviderChainFromEnv создаёт цепочку провайдеров по переменным окружения. EXTERNAL_API_PROVIDERS
+// перечисляет через запятую имена провайдеров; адрес провайдера задаёт EXTERNAL_API_<ИМЯ>_URL,
+// а остальные параметры EXTERNAL_API_* можно переопределить для провайдера так же, например
+// EXTERNAL_API_<ИМЯ>_TIMEOUT. EXTERNAL_API_PRIORITY_RELEASE_DATE, EXTERNAL_API_PRIORITY_TEXT
+// и EXTERNAL_API_PRIORITY_LINK перечисляют провайдеров поля в порядке приоритета. Если
+// EXTERNAL_API_PROVIDERS не задана, цепочка состоит из одного провайдера EXTERNAL_API_URL.
+func ProviderChainFromEnv() (*ProviderChain, error) {
+	base, err := SongDetailsConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	names := splitProviderList(os.Getenv("EXTERNAL_API_PROVIDERS"))
+	if len(names) == 0 {
+		return singleProviderChain(NewSongDetailsClient(base)), nil
+	}
+
+	providers := make([]SongDetailsProvider, 0, len(names))
+	for _, name := range names {
+		if !providerNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid provider name %q in EXTERNAL_API_PROVIDERS, expected letters, digits and underscores", name)
+		}
+		prefix := "EXTERNAL_API_" + strings.ToUpper(name) + "_"
+		config := base
+		config.Name, config.URL = name, ""
+		if err := applySongDetailsEnv(&config, prefix); err != nil {
+			return nil, err
+		}
+		if config.URL == "" {
+			return nil, fmt.Errorf("%sURL is required for song details provider %q", prefix, name)
+		}
+		providers = append(providers, NewSongDetailsClient(config))
+	}
+
+	priority := make(map[string][]string)
+	for _, field := range songDetailFields {
+		name := "EXTERNAL_API_PRIORITY_" + field.env
+		if value := os.Getenv(name); value != "" {
+			priority[field.name] = splitProviderList(value)
+			if len(priority[field.name]) == 0 {
+				return nil, fmt.Errorf("%s must list at least one provider", name)
+			}
+		}
+	}
+	return NewProviderChain(providers, priority)
+}
+
+// splitProviderList разбирает список имён через запятую, пропуская пустые элементы.
+func splitProviderList(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Name возвращает имя цепочки провайдеров.
+func (c *ProviderChain) Name() string {
+	return "chain"
+}
+
+// Fetch одновременно опрашивает провайдеров и собирает данные песни по приоритетам полей. В Sources
+// ответа записывается, какой провайдер предоставил каждое заполненное поле. Ошибка провайдера не мешает
+// взять поле у следующего по приоритету; если же поле осталось пустым, а один из его провайдеров
+// завершился ошибкой, Fetch возвращает ошибку, чтобы запрос можно было повторить. Если данных о песне
+// нет ни у одного провайдера, возвращается ErrSongDetailsNotFound.
+func (c *ProviderChain) Fetch(ctx context.Context, group, song string) (*models.SongDetail, error) {
+	queried := make([]bool, len(c.providers))
+	for _, providers := range c.priority {
+		for _, i := range providers {
+			queried[i] = true
+		}
+	}
+
+	results := make([]*models.SongDetail, len(c.providers))
+	errs := make([]error, len(c.providers))
+	var wg sync.WaitGroup
+	for i, provider := range c.providers {
+		if !queried[i] {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = provider.Fetch(ctx, group, song)
+		}()
+	}
+	wg.Wait()
+
+	detail := &models.SongDetail{Sources: make(map[string]string)}
+	blocking := make([]bool, len(c.providers)) // Провайдеры, из-за ошибки которых поле осталось пустым
+	for _, field := range songDetailFields {
+		for _, i := range c.priority[field.name] {
+			if errs[i] != nil {
+				continue
+			}
+			if value := *field.value(results[i]); value != "" {
+				*field.value(detail) = value
+				detail.Sources[field.name] = c.providers[i].Name()
+				break
+			}
+		}
+		if *field.value(detail) != "" {
+			continue
+		}
+		for _, i := range c.priority[field.name] {
+			if errs[i] != nil && !errors.Is(errs[i], ErrSongDetailsNotFound) {
+				blocking[i] = true
+			}
+		}
+	}
+
+	var failed []error
+	for i, provider := range c.providers {
+		if blocking[i] {
+			failed = append(failed, fmt.Errorf("%s: %w", provider.Name(), errs[i]))
+		}
+	}
+	if len(failed) > 0 {
+		return nil, errors.Join(failed...)
+	}
+
+	for i := range c.providers {
+		if queried[i] && !errors.Is(errs[i], ErrSongDetailsNotFound) {
+			return detail, nil
+		}
+	}
+	return nil, ErrSongDetailsNotFound
+}
@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"MusicLibrary/models"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeProvider возвращает заданные данные песни или ошибку.
+type fakeProvider struct {
+	name   string
+	detail models.SongDetail
+	err    error
+}
+
+func (p fakeProvider) Name() string { return p.name }
+
+func (p fakeProvider) Fetch(ctx context.Context, group, song string) (*models.SongDetail, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	detail := p.detail
+	return &detail, nil
+}
+
+func TestProviderChainFetch(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	lyrics := fakeProvider{name: "lyrics", detail: models.SongDetail{Text: "lyrics text", Link: "https://lyrics.example/1"}}
+	video := fakeProvider{name: "video", detail: models.SongDetail{ReleaseDate: "01.12.2003", Link: "https://video.example/1"}}
+	tests := []struct {
+		name      string
+		providers []SongDetailsProvider
+		priority  map[string][]string
+		want      models.SongDetail
+		wantErr   error
+	}{
+		{
+			name:      "providers order",
+			providers: []SongDetailsProvider{lyrics, video},
+			want: models.SongDetail{ReleaseDate: "01.12.2003", Text: "lyrics text", Link: "https://lyrics.example/1",
+				Sources: map[string]string{"releaseDate": "video", "text": "lyrics", "link": "lyrics"}},
+		},
+		{
+			name:      "field priority",
+			providers: []SongDetailsProvider{lyrics, video},
+			priority:  map[string][]string{"link": {"video", "lyrics"}},
+			want: models.SongDetail{ReleaseDate: "01.12.2003", Text: "lyrics text", Link: "https://video.example/1",
+				Sources: map[string]string{"releaseDate": "video", "text": "lyrics", "link": "video"}},
+		},
+		{
+			name:      "provider only for one field",
+			providers: []SongDetailsProvider{lyrics, video},
+			priority:  map[string][]string{"releaseDate": {"video"}, "text": {"lyrics"}, "link": {"lyrics"}},
+			want: models.SongDetail{ReleaseDate: "01.12.2003", Text: "lyrics text", Link: "https://lyrics.example/1",
+				Sources: map[string]string{"releaseDate": "video", "text": "lyrics", "link": "lyrics"}},
+		},
+		{
+			name:      "fallback after an error",
+			providers: []SongDetailsProvider{fakeProvider{name: "lyrics", err: errUnavailable}, video},
+			priority:  map[string][]string{"text": {"video"}},
+			want: models.SongDetail{ReleaseDate: "01.12.2003", Link: "https://video.example/1",
+				Sources: map[string]string{"releaseDate": "video", "link": "video"}},
+		},
+		{
+			name:      "fallback after not found",
+			providers: []SongDetailsProvider{fakeProvider{name: "lyrics", err: ErrSongDetailsNotFound}, video},
+			want: models.SongDetail{ReleaseDate: "01.12.2003", Link: "https://video.example/1",
+				Sources: map[string]string{"releaseDate": "video", "link": "video"}},
+		},
+		{
+			name:      "error leaves a field empty",
+			providers: []SongDetailsProvider{fakeProvider{name: "lyrics", err: errUnavailable}, video},
+			wantErr:   errUnavailable,
+		},
+		{
+			name:      "not found by all providers",
+			providers: []SongDetailsProvider{fakeProvider{name: "lyrics", err: ErrSongDetailsNotFound}, fakeProvider{name: "video", err: ErrSongDetailsNotFound}},
+			wantErr:   ErrSongDetailsNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := NewProviderChain(tt.providers, tt.priority)
+			if err != nil {
+				t.Fatal(err)
+			}
+			detail, err := chain.Fetch(context.Background(), "Muse", "Hysteria")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*detail, tt.want) {
+				t.Errorf("song details %+v, want %+v", *detail, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewProviderChainErrors(t *testing.T) {
+	lyrics := fakeProvider{name: "lyrics"}
+	tests := []struct {
+		name      string
+		providers []SongDetailsProvider
+		priority  map[string][]string
+	}{
+		{name: "no providers"},
+		{name: "duplicate provider", providers: []SongDetailsProvider{lyrics, lyrics}},
+		{name: "unknown provider", providers: []SongDetailsProvider{lyrics}, priority: map[string][]string{"text": {"video"}}},
+		{name: "unknown field", providers: []SongDetailsProvider{lyrics}, priority: map[string][]string{"lyrics": {"lyrics"}}},
+		{name: "empty priority", providers: []SongDetailsProvider{lyrics}, priority: map[string][]string{"text": {}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewProviderChain(tt.providers, tt.priority); err == nil {
+				t.Error("NewProviderChain succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestProviderChainFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "single provider", env: map[string]string{"EXTERNAL_API_URL": "http://localhost/info"}},
+		{name: "several providers", env: map[string]string{
+			"EXTERNAL_API_PROVIDERS":     "lyrics, video",
+			"EXTERNAL_API_LYRICS_URL":    "http://lyrics.example/info",
+			"EXTERNAL_API_VIDEO_URL":     "http://video.example/info",
+			"EXTERNAL_API_VIDEO_TIMEOUT": "2s",
+			"EXTERNAL_API_PRIORITY_LINK": "video,lyrics",
+			"EXTERNAL_API_PRIORITY_TEXT": "lyrics",
+		}},
+		{name: "missing provider URL", env: map[string]string{"EXTERNAL_API_PROVIDERS": "lyrics"}, wantErr: true},
+		{name: "invalid provider name", env: map[string]string{"EXTERNAL_API_PROVIDERS": "lyrics-api", "EXTERNAL_API_LYRICS-API_URL": "http://lyrics.example/info"}, wantErr: true},
+		{name: "invalid provider setting", env: map[string]string{
+			"EXTERNAL_API_PROVIDERS":          "lyrics",
+			"EXTERNAL_API_LYRICS_URL":         "http://lyrics.example/info",
+			"EXTERNAL_API_LYRICS_MAX_RETRIES": "-1",
+		}, wantErr: true},
+		{name: "unknown provider in priority", env: map[string]string{
+			"EXTERNAL_API_PROVIDERS":     "lyrics",
+			"EXTERNAL_API_LYRICS_URL":    "http://lyrics.example/info",
+			"EXTERNAL_API_PRIORITY_TEXT": "video",
+		}, wantErr: true},
+		{name: "empty priority", env: map[string]string{
+			"EXTERNAL_API_PROVIDERS":     "lyrics",
+			"EXTERNAL_API_LYRICS_URL":    "http://lyrics.example/info",
+			"EXTERNAL_API_PRIORITY_TEXT": " , ",
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"EXTERNAL_API_URL", "EXTERNAL_API_PROVIDERS", "EXTERNAL_API_PRIORITY_RELEASE_DATE", "EXTERNAL_API_PRIORITY_TEXT", "EXTERNAL_API_PRIORITY_LINK"} {
+				t.Setenv(name, "")
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			_, err := ProviderChainFromEnv()
+			if tt.wantErr != (err != nil) {
+				t.Errorf("error %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// cyrillicToLatin задаёт транслитерацию кириллицы в латиницу по распространённой неформальной схеме.
+// Различия с другими схемами (ГОСТ, ISO 9) устраняются последующей нормализацией в latinFolding.
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// latinDiacritics раскрывает буквы с диакритикой из ISO 9 / ГОСТ 7.79 (система А) в латинские сочетания.
+var latinDiacritics = map[rune]string{
+	'š': "sh", 'č': "ch", 'ž': "zh", 'ŝ': "shch", 'ë': "e", 'è': "e", 'é': "e",
+	'û': "yu", 'â': "ya", 'ǎ': "ya", 'ǔ': "yu", 'ì': "i", 'ï': "i", 'ü': "u", 'ö': "o", 'ä': "a",
+}
+
+// apostrophes содержит символы, которыми в разных схемах передаются мягкий и твёрдый знаки.
+var apostrophes = "'`\"ʹʺ’‘"
+
+// latinFolding сводит различные латинские написания одного звука к единому виду:
+// "Mumiy", "Mumij" и "Mumii" дают одинаковый результат. Более длинные сочетания перечислены первыми.
+var latinFolding = strings.NewReplacer(
+	"shch", "sh",
+	"sch", "sh",
+	"kh", "h",
+	"ts", "c",
+	"tz", "c",
+	"cz", "c",
+	"ck", "k",
+	"x", "ks",
+	"w", "v",
+	"y", "i",
+	"j", "i",
+)
+
+// SearchKey возвращает ключ для поиска без учета регистра, алфавита и схемы транслитерации.
+// Кириллица транслитерируется в латиницу, после чего различные схемы (ГОСТ, ISO 9 и неформальные
+// написания) сводятся к единому виду, а повторяющиеся буквы схлопываются. Например, "Мумий Тролль",
+// "Mumiy Troll" и "Mumij Troll'" имеют одинаковый ключ, как и "Кино" и "Kino".
+func SearchKey(value string) string {
+	var latin strings.Builder
+	for _, r := range strings.ToLower(value) {
+		switch {
+		case strings.ContainsRune(apostrophes, r):
+			// Мягкий и твёрдый знаки не влияют на ключ
+		case cyrillicToLatin[r] != "" || r == 'ъ' || r == 'ь':
+			latin.WriteString(cyrillicToLatin[r])
+		case latinDiacritics[r] != "":
+			latin.WriteString(latinDiacritics[r])
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			latin.WriteRune(r)
+		default:
+			latin.WriteRune(' ')
+		}
+	}
+
+	folded := latinFolding.Replace(latin.String())
+
+	// Схлопываем повторяющиеся буквы и пробелы: "troll" и "trol" дают одинаковый ключ
+	var key strings.Builder
+	var previous rune
+	for _, r := range folded {
+		if r != previous {
+			key.WriteRune(r)
+		}
+		previous = r
+	}
+	return strings.TrimSpace(key.String())
+}
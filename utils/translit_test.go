@@ -0,0 +1,41 @@
+package utils
+
+import "testing"
+
+func TestSearchKey(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "Кино", want: "kino"},
+		{value: "KINO", want: "kino"},
+		{value: "Мумий Тролль", want: "mumi trol"},
+		{value: "Mumiy Troll", want: "mumi trol"},
+		{value: "Mumij Troll'", want: "mumi trol"},
+		{value: "Щорс", want: "shors"},
+		{value: "Šors", want: "shors"},
+		{value: "Цой", want: "coi"},
+		{value: "Tsoy", want: "coi"},
+		{value: "Хижина", want: "hizhina"},
+		{value: "Khizhina", want: "hizhina"},
+		{value: "Сплин-2", want: "splin 2"},
+		{value: "  ", want: ""},
+	}
+	for _, tt := range tests {
+		if got := SearchKey(tt.value); got != tt.want {
+			t.Errorf("SearchKey(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSearchKeyDistinguishesNames(t *testing.T) {
+	for _, pair := range [][2]string{
+		{"Кино", "Кина"},
+		{"Muse", "Mute"},
+		{"Сплин", "Splean"},
+	} {
+		if SearchKey(pair[0]) == SearchKey(pair[1]) {
+			t.Errorf("SearchKey(%q) and SearchKey(%q) are both %q", pair[0], pair[1], SearchKey(pair[0]))
+		}
+	}
+}
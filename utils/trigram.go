@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Trigrams возвращает множество триграмм строки так же, как их строит расширение pg_trgm:
+// строка делится на слова из букв и цифр, каждое слово приводится к нижнему регистру
+// и дополняется двумя пробелами в начале и одним в конце.
+func Trigrams(value string) map[string]bool {
+	trigrams := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			trigrams[string(runes[i:i+3])] = true
+		}
+	}
+	return trigrams
+}
+
+// TrigramSimilarity возвращает сходство строк от 0 до 1 по аналогии с функцией similarity из pg_trgm:
+// отношение числа общих триграмм к числу всех различных триграмм обеих строк.
+func TrigramSimilarity(a, b string) float64 {
+	left, right := Trigrams(a), Trigrams(b)
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+
+	common := 0
+	for trigram := range left {
+		if right[trigram] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(left)+len(right)-common)
+}
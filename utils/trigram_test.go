@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrigrams(t *testing.T) {
+	got := Trigrams("Cat, cat!")
+	want := []string{"  c", " ca", "cat", "at "}
+	if len(got) != len(want) {
+		t.Fatalf("Trigrams = %v, want %v", got, want)
+	}
+	for _, trigram := range want {
+		if !got[trigram] {
+			t.Errorf("Trigrams = %v, missing %q", got, trigram)
+		}
+	}
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{a: "muse", b: "Muse", want: 1},
+		{a: "cat", b: "cart", want: 2.0 / 7},
+		{a: "muse", b: "blur", want: 0},
+		{a: "", b: "muse", want: 0},
+		{a: "!!!", b: "!!!", want: 0},
+	}
+	for _, tt := range tests {
+		if got := TrigramSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("TrigramSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package utils
+
+import "net/url"
+
+// IsHTTPURL проверяет, что ссылка — абсолютный URL со схемой http или https и непустым хостом.
+func IsHTTPURL(link string) bool {
+	parsed, err := url.ParseRequestURI(link)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,21 @@
+package utils
+
+import "testing"
+
+func TestIsHTTPURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.com/hysteria": true,
+		"http://example.com":           true,
+		"HTTPS://example.com":          true,
+		"ftp://example.com/hysteria":   false,
+		"/hysteria":                    false,
+		"https://":                     false,
+		"example.com/hysteria":         false,
+		"":                             false,
+	}
+	for link, want := range tests {
+		if got := IsHTTPURL(link); got != want {
+			t.Errorf("IsHTTPURL(%q) = %v, want %v", link, got, want)
+		}
+	}
+}